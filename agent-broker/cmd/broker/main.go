@@ -2,19 +2,30 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 
 	"github.com/joho/godotenv"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/config"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/handler"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/server"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/sessionstore"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
 	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/llm"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/rerank"
 )
 
 func main() {
@@ -32,55 +43,172 @@ func run() error {
 	logger.Info("starting agent-broker",
 		"port", cfg.Port,
 		"log_level", cfg.LogLevel.String(),
+		"store_backend", cfg.StoreBackend,
 		"qdrant_host", cfg.QdrantHost,
 		"qdrant_port", cfg.QdrantPort,
+		"embedding_provider", cfg.EmbeddingProvider,
 		"embedding_url", cfg.EmbeddingURL,
 		"embedding_dim", cfg.EmbeddingDim,
+		"broker_mode", cfg.BrokerMode,
+		"llm_provider", cfg.LLMProvider,
+		"rerank_provider", cfg.RerankProvider,
 	)
 
 	ctx := context.Background()
 
-	// Create embedder with configured dimension
-	embedder := embedding.NewClient(cfg.EmbeddingURL, cfg.EmbeddingDim)
+	embedder, embeddingModel, err := newEmbedder(ctx, cfg, logger)
+	if err != nil {
+		logger.Error("failed to create embedder", "provider", cfg.EmbeddingProvider, "error", err)
+		return err
+	}
 
-	// Create Qdrant store with configured dimension
-	qdrantStore, err := store.NewQdrantStore(ctx,
-		store.WithHost(cfg.QdrantHost),
-		store.WithPort(cfg.QdrantPort),
-		store.WithAPIKey(cfg.QdrantAPIKey),
-		store.WithTLS(cfg.QdrantUseTLS),
-		store.WithVectorDimension(uint64(cfg.EmbeddingDim)),
-	)
+	baseStore, err := newStore(ctx, cfg)
 	if err != nil {
-		logger.Error("failed to connect to qdrant", "error", err)
+		logger.Error("failed to create store", "backend", cfg.StoreBackend, "error", err)
 		return err
 	}
 	defer func() {
-		if err := qdrantStore.Close(); err != nil {
-			logger.Error("failed to close qdrant connection", "error", err)
+		if err := baseStore.Close(); err != nil {
+			logger.Error("failed to close store", "error", err)
 		}
 	}()
-	logger.Info("connected to qdrant")
+	logger.Info("store ready", "backend", cfg.StoreBackend)
+
+	agentStore := baseStore
+	if cfg.CacheEnabled {
+		agentStore = store.NewCachedStore(baseStore,
+			store.WithAgentTTL(cfg.CacheAgentTTL),
+			store.WithListTTL(cfg.CacheListTTL),
+			store.WithSearchTTL(cfg.CacheSearchTTL),
+		)
+	}
+
+	embeddingTemplate, err := newEmbeddingTemplate(cfg)
+	if err != nil {
+		logger.Error("failed to load embedding template", "error", err)
+		return err
+	}
+	logger.Info("embedding template ready", "version", embeddingTemplate.Version())
 
-	registryService := registry.NewRegistryService(qdrantStore, registry.WithEmbedder(embedder))
+	chunking := registry.ChunkOptions{
+		MaxWords: cfg.EmbeddingChunkWords,
+		Overlap:  cfg.EmbeddingChunkOverlap,
+		Pooling:  registry.PoolingMode(cfg.EmbeddingPooling),
+		DryRun:   cfg.EmbeddingChunkDryRun,
+	}
+	if err := chunking.Validate(); err != nil {
+		logger.Error("invalid embedding chunking config", "error", err)
+		return err
+	}
+
+	chatModel, err := newChatModel(cfg)
+	if err != nil {
+		logger.Error("failed to create chat model", "provider", cfg.LLMProvider, "error", err)
+		return err
+	}
+
+	reranker, err := newReranker(ctx, cfg, chatModel)
+	if err != nil {
+		logger.Error("failed to create reranker", "provider", cfg.RerankProvider, "error", err)
+		return err
+	}
+
+	registryService := registry.NewRegistryService(agentStore,
+		registry.WithEmbedder(embedder),
+		registry.WithEmbeddingModel(embeddingModel),
+		registry.WithLogger(logger),
+		registry.WithEmbeddingTemplate(embeddingTemplate),
+		registry.WithChunking(chunking),
+		registry.WithReranker(reranker),
+		registry.WithRerankCandidates(cfg.RerankCandidates),
+	)
+
+	if qs, ok := baseStore.(*store.QdrantStore); ok && qs.Migrated() {
+		logger.Warn("migrated legacy qdrant collection, re-embedding agents")
+		go func() {
+			reindexed, err := registryService.Reindex(ctx)
+			if err != nil {
+				logger.Error("failed to re-embed migrated agents, retry with POST /v1/admin/agents/reindex",
+					"reindexed", reindexed, "error", err)
+				return
+			}
+			logger.Info("re-embedded migrated agents", "reindexed", reindexed)
+		}()
+	}
+
+	taskStore, err := newTaskStore(cfg)
+	if err != nil {
+		logger.Error("failed to create task store", "error", err)
+		return err
+	}
+	pruner := delegate.NewTaskPruner(taskStore,
+		delegate.WithRetention(cfg.BrokerTaskRetention),
+		delegate.WithPruneInterval(cfg.BrokerTaskPruneInterval),
+		delegate.WithPrunerLogger(logger),
+	)
+	defer pruner.Close()
+
+	// One delegator makes the delegated calls and proxies tasks/get and
+	// tasks/cancel to the downstream tasks it recorded.
+	delegator := delegate.NewDelegator(
+		delegate.WithTimeout(cfg.BrokerDelegateTimeout),
+		delegate.WithStreamIdleTimeout(cfg.BrokerDelegateStreamIdleTimeout),
+		delegate.WithBroadcastDeadline(cfg.BrokerBroadcastDeadline),
+		delegate.WithMaxConcurrency(cfg.BrokerBroadcastConcurrency),
+		delegate.WithTaskStore(taskStore),
+		delegate.WithLogger(logger),
+	)
 
 	brokerAgent, err := agent.NewBrokerAgent(ctx, registryService,
+		agent.WithMode(agent.Mode(cfg.BrokerMode)),
+		agent.WithModel(chatModel),
+		agent.WithDefaultAction(agent.Action(cfg.BrokerDefaultAction)),
 		agent.WithGeminiAPIKey(cfg.GeminiAPIKey),
 		agent.WithGeminiModel(cfg.GeminiModel),
+		agent.WithDelegation(cfg.BrokerDelegate),
+		agent.WithDelegator(delegator),
+		agent.WithRoutePolicy(tools.RoutePolicy{
+			MinScore:     cfg.RouteMinScore,
+			TagMinScores: cfg.RouteTagMinScores,
+			MinMargin:    cfg.RouteMinMargin,
+			Reranked:     reranker != nil,
+		}),
 	)
 	if err != nil {
 		logger.Error("failed to create broker agent", "error", err)
 		return err
 	}
 
-	sessionService := agent.NewSessionService()
+	sessionService, err := newSessionService(cfg)
+	if err != nil {
+		logger.Error("failed to create session service", "backend", cfg.SessionBackend, "error", err)
+		return err
+	}
+	if closer, ok := sessionService.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				logger.Error("failed to close session service", "error", err)
+			}
+		}()
+	}
+	logger.Info("session service ready", "backend", cfg.SessionBackend)
+
+	expirer := sessionstore.NewExpirer(sessionService, []string{brokerAgent.Name()},
+		sessionstore.WithIdleTimeout(cfg.SessionRetention),
+		sessionstore.WithInterval(cfg.SessionPruneInterval),
+		sessionstore.WithLogger(logger),
+	)
+	defer expirer.Close()
 
 	mux := http.NewServeMux()
 
-	handler.NewBrokerHandler(brokerAgent, sessionService).RegisterRoutes(mux)
-	handler.NewHealthHandler(qdrantStore).RegisterRoutes(mux)
+	handler.NewBrokerHandler(brokerAgent, sessionService, handler.WithDelegator(delegator)).RegisterRoutes(mux)
+	handler.NewHealthHandler(agentStore, handler.WithEmbedder(embedder)).RegisterRoutes(mux)
 	handler.NewAdminHandler(registryService).RegisterRoutes(mux)
 	handler.NewAgentsHandler(registryService).RegisterRoutes(mux)
+	handler.NewSessionsHandler(sessionService, []string{brokerAgent.Name()},
+		handler.WithExpirer(expirer),
+	).RegisterRoutes(mux)
 
 	srv := server.New(mux,
 		server.WithPort(cfg.Port),
@@ -95,6 +223,276 @@ func run() error {
 	return nil
 }
 
+// newChatModel creates the broker's chat model. Gemini returns nil, letting
+// the broker agent build its default Gemini model.
+func newChatModel(cfg *config.Config) (model.LLM, error) {
+	switch cfg.LLMProvider {
+	case "gemini":
+		return nil, nil
+	case "openai":
+		if cfg.LLMModel == "" {
+			return nil, fmt.Errorf("LLM_MODEL must be set for the openai provider")
+		}
+		return llm.NewOpenAIModel(cfg.LLMURL,
+			llm.WithModel(cfg.LLMModel),
+			llm.WithAPIKey(cfg.LLMAPIKey),
+			llm.WithHeaders(cfg.LLMHeaders),
+			llm.WithPath(cfg.LLMPath),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown chat model provider %q", cfg.LLMProvider)
+	}
+}
+
+// newReranker creates the discovery reranker, or nil when reranking is
+// disabled. LLM reranking uses the chat model, building a Gemini model when
+// chatModel is nil.
+func newReranker(ctx context.Context, cfg *config.Config, chatModel model.LLM) (rerank.Reranker, error) {
+	switch cfg.RerankProvider {
+	case "none":
+		return nil, nil
+	case "tei":
+		return rerank.NewTEIReranker(cfg.RerankURL, rerank.WithAPIKey(cfg.RerankAPIKey)), nil
+	case "llm":
+		if chatModel == nil {
+			var err error
+			chatModel, err = gemini.NewModel(ctx, cfg.GeminiModel, &genai.ClientConfig{
+				APIKey: cfg.GeminiAPIKey,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("create gemini model: %w", err)
+			}
+		}
+		return rerank.NewLLMReranker(chatModel), nil
+	default:
+		return nil, fmt.Errorf("unknown rerank provider %q", cfg.RerankProvider)
+	}
+}
+
+// newSessionService creates the broker session service for the configured
+// backend.
+func newSessionService(cfg *config.Config) (session.Service, error) {
+	switch cfg.SessionBackend {
+	case "memory":
+		return agent.NewSessionService(), nil
+	case "sqlite":
+		svc, err := sessionstore.NewSQLiteService(cfg.SessionDBPath)
+		if err != nil {
+			return nil, fmt.Errorf("create sqlite session service: %w", err)
+		}
+		return svc, nil
+	default:
+		return nil, fmt.Errorf("unknown session backend %q", cfg.SessionBackend)
+	}
+}
+
+// newTaskStore creates the store for downstream task links, on disk when a
+// directory is configured so links survive restarts.
+func newTaskStore(cfg *config.Config) (delegate.TaskStore, error) {
+	if cfg.BrokerTaskStoreDir == "" {
+		return delegate.NewMemoryTaskStore(), nil
+	}
+	store, err := delegate.NewFileTaskStore(cfg.BrokerTaskStoreDir)
+	if err != nil {
+		return nil, fmt.Errorf("create file task store: %w", err)
+	}
+	return store, nil
+}
+
+// defaultEmbeddingDim is the dimension used when none is configured, probed
+// or stored: the local embedder's default and that of common small models
+// such as all-MiniLM-L6-v2 and bge-small.
+const defaultEmbeddingDim = 384
+
+// newEmbedder creates the embedder for the configured provider and returns
+// it with the model ID to record alongside vectors. Remote providers are
+// probed to detect or validate the dimension and model, then wrapped with
+// circuit breaking, batching, per-mode instruction prefixes and caching.
+func newEmbedder(ctx context.Context, cfg *config.Config, logger *slog.Logger) (embedding.Embedder, string, error) {
+	if cfg.EmbeddingProvider == "local" {
+		if cfg.EmbeddingDim == 0 {
+			cfg.EmbeddingDim = defaultEmbeddingDim
+		}
+		embedder, err := embedding.NewLocalEmbedder(cfg.EmbeddingDim)
+		if err != nil {
+			return nil, "", err
+		}
+		return embedder, embedding.LocalModel, nil
+	}
+
+	embedder, err := newProviderEmbedder(ctx, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	model := cfg.EmbeddingModel
+	if cfg.EmbeddingProbe {
+		probe, err := embedding.Probe(ctx, embedder)
+		if err == nil {
+			if err := probe.Validate(cfg.EmbeddingDim, cfg.EmbeddingModel); err != nil {
+				return nil, "", err
+			}
+			logger.Info("embedding provider probed",
+				"dimensions", probe.Dimensions,
+				"model", probe.Model,
+				"max_input_length", probe.MaxInputLength,
+			)
+			if probe.Model != "" {
+				model = probe.Model
+			}
+			if cfg.EmbeddingDim == 0 {
+				cfg.EmbeddingDim = probe.Dimensions
+				if embedder, err = newProviderEmbedder(ctx, cfg); err != nil {
+					return nil, "", err
+				}
+			}
+		} else {
+			logger.Warn("embedding provider probe failed", "error", err)
+		}
+	}
+	if cfg.EmbeddingDim == 0 {
+		cfg.EmbeddingDim = fallbackEmbeddingDim(ctx, cfg, logger)
+		if embedder, err = newProviderEmbedder(ctx, cfg); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if cfg.EmbeddingBreakerThreshold > 0 {
+		embedder = embedding.NewCircuitBreaker(embedder,
+			embedding.WithFailureThreshold(cfg.EmbeddingBreakerThreshold),
+			embedding.WithCooldown(cfg.EmbeddingBreakerCooldown),
+		)
+	}
+	if cfg.EmbeddingBatchWindow > 0 {
+		embedder = embedding.NewBatchingEmbedder(embedder,
+			embedding.WithMaxBatchSize(cfg.EmbeddingBatchSize),
+			embedding.WithBatchWindow(cfg.EmbeddingBatchWindow),
+		)
+	}
+
+	prefixes := embedding.Prefixes{
+		Query:    cfg.EmbeddingQueryPrefix,
+		Document: cfg.EmbeddingDocumentPrefix,
+	}
+	if prefixes.IsZero() {
+		prefixes = embedding.DefaultPrefixes(model)
+	}
+	if !prefixes.IsZero() {
+		embedder = embedding.NewPrefixEmbedder(embedder, prefixes)
+	}
+
+	if cfg.EmbeddingCacheEnabled {
+		embedder = embedding.NewCachedEmbedder(embedder,
+			embedding.WithMaxEntries(cfg.EmbeddingCacheSize),
+			embedding.WithCacheDir(cfg.EmbeddingCacheDir),
+			embedding.WithCacheModel(model),
+		)
+	}
+	return embedder, model, nil
+}
+
+// newProviderEmbedder creates the unwrapped client for a remote provider.
+func newProviderEmbedder(ctx context.Context, cfg *config.Config) (embedding.Embedder, error) {
+	opts := []embedding.Option{
+		embedding.WithModel(cfg.EmbeddingModel),
+		embedding.WithAPIKey(cfg.EmbeddingAPIKey),
+		embedding.WithHeaders(cfg.EmbeddingHeaders),
+		embedding.WithPath(cfg.EmbeddingPath),
+		embedding.WithRetryPolicy(embedding.RetryPolicy{
+			MaxRetries:     cfg.EmbeddingMaxRetries,
+			InitialBackoff: cfg.EmbeddingRetryBackoff,
+			MaxBackoff:     cfg.EmbeddingRetryMaxBackoff,
+		}),
+	}
+
+	switch cfg.EmbeddingProvider {
+	case "openai":
+		return embedding.NewClient(cfg.EmbeddingURL, cfg.EmbeddingDim, opts...), nil
+	case "ollama":
+		return embedding.NewOllamaClient(cfg.EmbeddingURL, cfg.EmbeddingDim, opts...), nil
+	case "gemini":
+		// The Gemini API endpoint is fixed; fall back to the chat model's key.
+		if cfg.EmbeddingAPIKey == "" {
+			opts = append(opts, embedding.WithAPIKey(cfg.GeminiAPIKey))
+		}
+		gemini, err := embedding.NewGeminiEmbedder(ctx, "", cfg.EmbeddingDim, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("create gemini embedder: %w", err)
+		}
+		return gemini, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.EmbeddingProvider)
+	}
+}
+
+// fallbackEmbeddingDim returns the dimension to use when it is neither
+// configured nor probed: that of the stored Qdrant collection, so a provider
+// outage does not block a restart, or defaultEmbeddingDim.
+func fallbackEmbeddingDim(ctx context.Context, cfg *config.Config, logger *slog.Logger) int {
+	if cfg.StoreBackend == "qdrant" {
+		dim, err := store.CollectionDimension(ctx, qdrantOptions(cfg)...)
+		switch {
+		case err != nil:
+			logger.Warn("failed to read stored embedding dimension", "error", err)
+		case dim > 0:
+			logger.Warn("embedding dimension unknown, using the stored collection's", "dimensions", dim)
+			return int(dim)
+		}
+	}
+	logger.Warn("embedding dimension unknown, using the default", "dimensions", defaultEmbeddingDim)
+	return defaultEmbeddingDim
+}
+
+// newEmbeddingTemplate parses the configured agent embedding text template,
+// read from EmbeddingTemplateFile when set.
+func newEmbeddingTemplate(cfg *config.Config) (*registry.EmbeddingTemplate, error) {
+	source := cfg.EmbeddingTemplate
+	if cfg.EmbeddingTemplateFile != "" {
+		data, err := os.ReadFile(cfg.EmbeddingTemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("read embedding template: %w", err)
+		}
+		source = string(data)
+	}
+	if source == "" {
+		source = registry.DefaultEmbeddingTemplate
+	}
+	return registry.ParseEmbeddingTemplate(source)
+}
+
+// newStore creates the agent store for the configured backend.
+func newStore(ctx context.Context, cfg *config.Config) (store.Store, error) {
+	switch cfg.StoreBackend {
+	case "memory":
+		var opts []store.MemoryOption
+		if cfg.MemoryHNSW {
+			if cfg.HNSWM < 2 {
+				return nil, fmt.Errorf("HNSW_M must be at least 2, got %d", cfg.HNSWM)
+			}
+			hnsw := store.DefaultHNSWOptions()
+			hnsw.M = cfg.HNSWM
+			hnsw.EfSearch = cfg.HNSWEfSearch
+			opts = append(opts, store.WithHNSWIndex(hnsw))
+		}
+		return store.NewMemoryStore(opts...), nil
+	case "qdrant":
+		opts := append(qdrantOptions(cfg), store.WithVectorDimension(uint64(cfg.EmbeddingDim)))
+		return store.NewQdrantStore(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", cfg.StoreBackend)
+	}
+}
+
+// qdrantOptions returns the options connecting to the configured Qdrant.
+func qdrantOptions(cfg *config.Config) []store.Option {
+	return []store.Option{
+		store.WithHost(cfg.QdrantHost),
+		store.WithPort(cfg.QdrantPort),
+		store.WithAPIKey(cfg.QdrantAPIKey),
+		store.WithTLS(cfg.QdrantUseTLS),
+	}
+}
+
 func setupLogger(level slog.Level) *slog.Logger {
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: level,
@@ -3,15 +3,18 @@ package agent
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 )
 
@@ -25,29 +28,94 @@ const brokerInstruction = `You are the Lunarr Agent Broker, a meta-agent that he
 
 You have three capabilities:
 1. **discover**: Find agents matching a query. Use this to show users available agents for a topic.
-2. **route**: Find the single best agent for a specific task. Use this when a user needs to be directed to one agent.
+2. **route**: Find the single best agent for a specific task. Use this when a user needs to be directed to one agent. Check its confidence: when it is "ambiguous" or "low", no agent was chosen. Ask the user a clarifying question instead of guessing, offering the returned agent and any alternatives.
 3. **broadcast**: Find multiple agents to send a request to. Use this when a task should go to several agents.
 
 When users describe what they need, use the appropriate tool to find matching agents. Be helpful and explain the results clearly.`
 
+const delegationInstruction = `
+
+Delegation is enabled: **route** forwards the user's request to the chosen agent and returns its reply in the delegation field. Relay that reply to the user, naming the agent that answered. If the delegation failed or timed out, say so and include the reported error instead of answering yourself.
+
+**broadcast** sends the request to every selected agent in parallel and returns one result per agent with its reply, error and latency. Summarize the replies, attributing each to its agent, and list the agents that failed or timed out.`
+
 // Options configures the broker agent.
 type Options struct {
+	// Mode selects between the model-driven agent and fixed rules.
+	Mode Mode
+	// DefaultAction is the action applied in rules mode when the request
+	// does not select one.
+	DefaultAction Action
+	// Model is the chat model of the LLM agent. Nil builds a Gemini model
+	// from GeminiAPIKey and GeminiModel.
+	Model model.LLM
 	// GeminiAPIKey is the API key for Gemini.
 	GeminiAPIKey string
 	// GeminiModel is the model name to use.
 	GeminiModel string
+	// Delegate forwards routed requests to the chosen agent over A2A.
+	Delegate bool
+	// DelegateTimeout bounds a single delegated call.
+	DelegateTimeout time.Duration
+	// DelegateStreamIdleTimeout bounds the wait for each event of a
+	// streamed delegated call.
+	DelegateStreamIdleTimeout time.Duration
+	// BroadcastDeadline bounds a whole delegated broadcast.
+	BroadcastDeadline time.Duration
+	// BroadcastConcurrency caps parallel calls during a broadcast.
+	BroadcastConcurrency int
+	// TaskStore records the downstream tasks each broker task delegated to.
+	TaskStore delegate.TaskStore
+	// Delegator, when set, makes the delegated calls instead of one built
+	// from the delegation fields above, so it can be shared with the task
+	// handler.
+	Delegator *delegate.Delegator
+	// RoutePolicy decides when route trusts its best match.
+	RoutePolicy tools.RoutePolicy
 }
 
 // DefaultOptions returns sensible defaults for broker options.
 func DefaultOptions() Options {
 	return Options{
-		GeminiModel: "gemini-3-flash-preview",
+		Mode:                      ModeLLM,
+		DefaultAction:             ActionRoute,
+		GeminiModel:               "gemini-3-flash-preview",
+		DelegateTimeout:           delegate.DefaultOptions().Timeout,
+		DelegateStreamIdleTimeout: delegate.DefaultOptions().StreamIdleTimeout,
+		BroadcastDeadline:         delegate.DefaultOptions().BroadcastDeadline,
+		BroadcastConcurrency:      delegate.DefaultOptions().MaxConcurrency,
 	}
 }
 
 // Option is a functional option for configuring the broker agent.
 type Option func(*Options)
 
+// WithMode sets the broker mode.
+func WithMode(mode Mode) Option {
+	return func(o *Options) {
+		if mode != "" {
+			o.Mode = mode
+		}
+	}
+}
+
+// WithDefaultAction sets the action applied in rules mode when the request
+// does not select one.
+func WithDefaultAction(action Action) Option {
+	return func(o *Options) {
+		if action != "" {
+			o.DefaultAction = action
+		}
+	}
+}
+
+// WithModel sets the chat model, replacing the default Gemini model.
+func WithModel(m model.LLM) Option {
+	return func(o *Options) {
+		o.Model = m
+	}
+}
+
 // WithGeminiAPIKey sets the Gemini API key.
 func WithGeminiAPIKey(key string) Option {
 	return func(o *Options) {
@@ -64,18 +132,111 @@ func WithGeminiModel(model string) Option {
 	}
 }
 
-// NewBrokerAgent creates a new ADK LLM agent for the broker.
+// WithDelegation enables forwarding routed requests to the chosen agent.
+func WithDelegation(enabled bool) Option {
+	return func(o *Options) {
+		o.Delegate = enabled
+	}
+}
+
+// WithDelegateTimeout sets the timeout for a single delegated call.
+func WithDelegateTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.DelegateTimeout = d
+		}
+	}
+}
+
+// WithDelegateStreamIdleTimeout sets the longest wait for the next event of
+// a streamed delegated call.
+func WithDelegateStreamIdleTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.DelegateStreamIdleTimeout = d
+		}
+	}
+}
+
+// WithBroadcastDeadline sets the deadline for a whole delegated broadcast.
+func WithBroadcastDeadline(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.BroadcastDeadline = d
+		}
+	}
+}
+
+// WithBroadcastConcurrency caps parallel calls during a broadcast.
+func WithBroadcastConcurrency(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.BroadcastConcurrency = n
+		}
+	}
+}
+
+// WithTaskStore records delegated downstream tasks in store.
+func WithTaskStore(store delegate.TaskStore) Option {
+	return func(o *Options) {
+		o.TaskStore = store
+	}
+}
+
+// WithDelegator makes delegated calls with delegator.
+func WithDelegator(delegator *delegate.Delegator) Option {
+	return func(o *Options) {
+		o.Delegator = delegator
+	}
+}
+
+// WithRoutePolicy sets the score thresholds and margin route requires.
+func WithRoutePolicy(policy tools.RoutePolicy) Option {
+	return func(o *Options) {
+		o.RoutePolicy = policy
+	}
+}
+
+// NewBrokerAgent creates the ADK agent for the broker: an LLM agent, or in
+// rules mode an agent that needs no model.
 func NewBrokerAgent(ctx context.Context, reg *registry.RegistryService, opts ...Option) (agent.Agent, error) {
 	options := DefaultOptions()
 	for _, opt := range opts {
 		opt(&options)
 	}
 
-	model, err := gemini.NewModel(ctx, options.GeminiModel, &genai.ClientConfig{
-		APIKey: options.GeminiAPIKey,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create gemini model: %w", err)
+	var delegator *delegate.Delegator
+	switch {
+	case !options.Delegate:
+	case options.Delegator != nil:
+		delegator = options.Delegator
+	default:
+		delegator = delegate.NewDelegator(
+			delegate.WithTimeout(options.DelegateTimeout),
+			delegate.WithStreamIdleTimeout(options.DelegateStreamIdleTimeout),
+			delegate.WithBroadcastDeadline(options.BroadcastDeadline),
+			delegate.WithMaxConcurrency(options.BroadcastConcurrency),
+			delegate.WithTaskStore(options.TaskStore),
+		)
+	}
+
+	switch options.Mode {
+	case ModeRules:
+		return newRuleAgent(reg, delegator, options.DefaultAction, options.RoutePolicy)
+	case ModeLLM:
+	default:
+		return nil, fmt.Errorf("unknown broker mode %q", options.Mode)
+	}
+
+	llm := options.Model
+	if llm == nil {
+		var err error
+		llm, err = gemini.NewModel(ctx, options.GeminiModel, &genai.ClientConfig{
+			APIKey: options.GeminiAPIKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create gemini model: %w", err)
+		}
 	}
 
 	discoverTool, err := tools.NewDiscoverTool(reg)
@@ -83,12 +244,17 @@ func NewBrokerAgent(ctx context.Context, reg *registry.RegistryService, opts ...
 		return nil, fmt.Errorf("create discover tool: %w", err)
 	}
 
-	routeTool, err := tools.NewRouteTool(reg)
+	instruction := brokerInstruction
+	if delegator != nil {
+		instruction += delegationInstruction
+	}
+
+	routeTool, err := tools.NewRouteTool(reg, delegator, options.RoutePolicy)
 	if err != nil {
 		return nil, fmt.Errorf("create route tool: %w", err)
 	}
 
-	broadcastTool, err := tools.NewBroadcastTool(reg)
+	broadcastTool, err := tools.NewBroadcastTool(reg, delegator)
 	if err != nil {
 		return nil, fmt.Errorf("create broadcast tool: %w", err)
 	}
@@ -96,8 +262,8 @@ func NewBrokerAgent(ctx context.Context, reg *registry.RegistryService, opts ...
 	return llmagent.New(llmagent.Config{
 		Name:        brokerName,
 		Description: brokerDescription,
-		Model:       model,
-		Instruction: brokerInstruction,
+		Model:       llm,
+		Instruction: instruction,
 		Tools:       []tool.Tool{discoverTool, routeTool, broadcastTool},
 	})
 }
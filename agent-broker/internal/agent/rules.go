@@ -0,0 +1,210 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/genaitext"
+)
+
+// Mode selects how the broker decides what to do with a message.
+type Mode string
+
+// Broker modes.
+const (
+	// ModeLLM lets the configured chat model choose and call the broker tools.
+	ModeLLM Mode = "llm"
+	// ModeRules applies one action to the message text without a model.
+	ModeRules Mode = "rules"
+)
+
+// Valid reports whether m is a known broker mode.
+func (m Mode) Valid() bool {
+	return m == ModeLLM || m == ModeRules
+}
+
+// Action is a broker operation applied in rules mode.
+type Action string
+
+// Broker actions.
+const (
+	// ActionDiscover lists the agents matching the message.
+	ActionDiscover Action = "discover"
+	// ActionRoute picks the best agent for the message.
+	ActionRoute Action = "route"
+	// ActionBroadcast picks several agents for the message.
+	ActionBroadcast Action = "broadcast"
+)
+
+// Valid reports whether a is a known broker action.
+func (a Action) Valid() bool {
+	return a == ActionDiscover || a == ActionRoute || a == ActionBroadcast
+}
+
+// Request metadata keys read in rules mode.
+const (
+	// ActionMetadataKey selects the action, overriding the default.
+	ActionMetadataKey = "lunarr/action"
+	// ArgsMetadataKey holds an object of arguments for the action, using
+	// the same fields as the matching tool. The query defaults to the
+	// message text.
+	ArgsMetadataKey = "lunarr/args"
+)
+
+// metadataKey is the context key for request metadata.
+type metadataKey struct{}
+
+// ContextWithRequestMetadata returns a context carrying the metadata of the
+// A2A request being executed.
+func ContextWithRequestMetadata(ctx context.Context, meta map[string]any) context.Context {
+	return context.WithValue(ctx, metadataKey{}, meta)
+}
+
+// requestMetadata returns the context's request metadata, or nil.
+func requestMetadata(ctx context.Context) map[string]any {
+	meta, _ := ctx.Value(metadataKey{}).(map[string]any)
+	return meta
+}
+
+// ruleAgent answers each message by applying a broker action to its text.
+type ruleAgent struct {
+	// reg is searched for matching agents.
+	reg *registry.RegistryService
+	// delegator forwards requests when delegation is enabled, else nil.
+	delegator *delegate.Delegator
+	// action applies when the request does not select one.
+	action Action
+	// policy decides when route trusts its best match.
+	policy tools.RoutePolicy
+}
+
+// newRuleAgent creates the broker agent for rules mode.
+func newRuleAgent(reg *registry.RegistryService, delegator *delegate.Delegator, action Action, policy tools.RoutePolicy) (agent.Agent, error) {
+	if !action.Valid() {
+		return nil, fmt.Errorf("unknown default action %q", action)
+	}
+	a := &ruleAgent{reg: reg, delegator: delegator, action: action, policy: policy}
+	return agent.New(agent.Config{
+		Name:        brokerName,
+		Description: brokerDescription,
+		Run:         a.run,
+	})
+}
+
+// run replies with the action's result as a function response, which A2A
+// clients receive as a data part.
+func (a *ruleAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		action, response, err := a.apply(ctx, genaitext.Join(ctx.UserContent()))
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		event := session.NewEvent(ctx.InvocationID())
+		event.Content = &genai.Content{
+			Role: genai.RoleModel,
+			Parts: []*genai.Part{{
+				FunctionResponse: &genai.FunctionResponse{Name: string(action), Response: response},
+			}},
+		}
+		yield(event, nil)
+	}
+}
+
+// apply runs the action selected by the request metadata, or the default
+// action, with text as the query and delegated message.
+func (a *ruleAgent) apply(ctx context.Context, text string) (Action, map[string]any, error) {
+	meta := requestMetadata(ctx)
+	action := a.action
+	if v, ok := meta[ActionMetadataKey]; ok {
+		s, _ := v.(string)
+		action = Action(s)
+		if !action.Valid() {
+			return "", nil, fmt.Errorf("unknown action %v", v)
+		}
+	}
+
+	var result any
+	var err error
+	switch action {
+	case ActionDiscover:
+		args := tools.DiscoverArgs{Query: text}
+		if err := decodeArgs(meta[ArgsMetadataKey], &args); err != nil {
+			return "", nil, err
+		}
+		if args.Query == "" {
+			return "", nil, errEmptyQuery
+		}
+		result, err = tools.Discover(ctx, a.reg, args)
+	case ActionRoute:
+		args := tools.RouteArgs{Query: text, Message: text}
+		if err := decodeArgs(meta[ArgsMetadataKey], &args); err != nil {
+			return "", nil, err
+		}
+		if args.Query == "" {
+			return "", nil, errEmptyQuery
+		}
+		result, err = tools.Route(ctx, a.reg, a.delegator, a.policy, args)
+	case ActionBroadcast:
+		args := tools.BroadcastArgs{Query: text, Message: text}
+		if err := decodeArgs(meta[ArgsMetadataKey], &args); err != nil {
+			return "", nil, err
+		}
+		if args.Query == "" {
+			return "", nil, errEmptyQuery
+		}
+		result, err = tools.Broadcast(ctx, a.reg, a.delegator, args)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", action, err)
+	}
+
+	response, err := toMap(result)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode %s result: %w", action, err)
+	}
+	return action, response, nil
+}
+
+// errEmptyQuery is returned when neither the message nor its arguments
+// give a query.
+var errEmptyQuery = errors.New("message has no text to use as the query")
+
+// decodeArgs overlays the fields of a metadata arguments object onto args.
+func decodeArgs(v any, args any) error {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode action arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, args); err != nil {
+		return fmt.Errorf("decode action arguments: %w", err)
+	}
+	return nil
+}
+
+// toMap converts a tool result to its JSON object form.
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -1,47 +1,79 @@
 package tools
 
 import (
+	"context"
+
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 )
 
-// NewBroadcastTool creates a tool for broadcasting to multiple agents.
-func NewBroadcastTool(reg *registry.RegistryService) (tool.Tool, error) {
+// NewBroadcastTool creates a tool for broadcasting to multiple agents. With
+// a non-nil delegator the request is sent to every selected agent in
+// parallel and their replies are aggregated.
+func NewBroadcastTool(reg *registry.RegistryService, delegator *delegate.Delegator) (tool.Tool, error) {
+	description := "Find multiple agents to broadcast a request to. Use this when a task should be sent to several relevant agents."
+	if delegator != nil {
+		description = "Send a request to several relevant agents in parallel and return each agent's reply, failure and latency."
+	}
+
+	schema, err := argsSchema[BroadcastArgs]()
+	if err != nil {
+		return nil, err
+	}
+
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "broadcast",
-			Description: "Find multiple agents to broadcast a request to. Use this when a task should be sent to several relevant agents.",
+			Description: description,
+			InputSchema: schema,
 		},
 		func(ctx tool.Context, args BroadcastArgs) (BroadcastResult, error) {
-			limit := args.Limit
-			if limit <= 0 {
-				limit = 5
-			}
-
-			result, err := reg.Discover(ctx, registry.DiscoverInput{
-				Query:  args.Query,
-				Limit:  limit,
-				Tags:   args.Tags,
-				Skills: args.Skills,
-			})
-			if err != nil {
-				return BroadcastResult{}, err
-			}
-
-			agents := make([]ScoredAgent, 0, len(result.Agents))
-			for _, scored := range result.Agents {
-				agents = append(agents, ScoredAgent{
-					Card:  scored.Agent.Card,
-					Score: scored.Score,
-				})
-			}
-
-			return BroadcastResult{
-				Agents: agents,
-				Total:  len(agents),
-			}, nil
+			args.Message = delegatedMessage(ctx, args.Message, args.Query)
+			return Broadcast(ctx, reg, delegator, args)
 		},
 	)
 }
+
+// Broadcast finds the agents matching args. With a non-nil delegator
+// args.Message, or the query if it is empty, is sent to each of them, at
+// most MaxBroadcastTargets agents.
+func Broadcast(ctx context.Context, reg *registry.RegistryService, delegator *delegate.Delegator, args BroadcastArgs) (BroadcastResult, error) {
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	if delegator != nil {
+		limit = min(limit, delegator.MaxBroadcastTargets())
+	}
+
+	result, err := reg.Discover(ctx, registry.DiscoverInput{
+		Query:  args.Query,
+		Limit:  limit,
+		Tags:   args.Tags,
+		Skills: args.Skills,
+		Filter: args.Filter,
+	})
+	if err != nil {
+		return BroadcastResult{}, err
+	}
+
+	agents := make([]ScoredAgent, 0, len(result.Agents))
+	targets := make([]delegate.Target, 0, len(result.Agents))
+	for _, scored := range result.Agents {
+		agents = append(agents, toScoredAgent(scored))
+		targets = append(targets, delegate.Target{AgentID: scored.Agent.ID, Card: scored.Agent.Card})
+	}
+
+	broadcast := BroadcastResult{
+		Agents:  agents,
+		Total:   len(agents),
+		Lexical: result.Lexical,
+	}
+	if delegator != nil && len(targets) > 0 {
+		broadcast.Delegation = delegator.Broadcast(ctx, targets, messageOrQuery(args.Message, args.Query))
+	}
+	return broadcast, nil
+}
@@ -0,0 +1,96 @@
+package tools
+
+import "github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+
+// Confidence is how sure the route tool is of its choice.
+type Confidence string
+
+// Route confidence categories.
+const (
+	// ConfidenceHigh means the best agent passed the score threshold with a
+	// clear margin over the runner-up. Only confident routes are delegated.
+	ConfidenceHigh Confidence = "high"
+	// ConfidenceAmbiguous means the runner-up scored too close to the best
+	// agent to tell them apart.
+	ConfidenceAmbiguous Confidence = "ambiguous"
+	// ConfidenceLow means the best agent scored below the minimum, or its
+	// score could not be checked against the policy's thresholds.
+	ConfidenceLow Confidence = "low"
+	// ConfidenceNone means no agent matched at all.
+	ConfidenceNone Confidence = "none"
+)
+
+// RoutePolicy sets when the route tool trusts its best match. Thresholds
+// are reranker scores when Reranked is set, else vector similarities.
+// Scores on another scale, from the lexical fallback or kept after a
+// failed rerank, cannot be checked against them and are rated low. The
+// zero policy trusts any match.
+type RoutePolicy struct {
+	// MinScore is the score the best agent needs to be routed to.
+	MinScore float32
+	// TagMinScores raises the minimum for agents with a tag. An agent with
+	// several listed tags needs the highest of their minimums.
+	TagMinScores map[string]float32
+	// MinMargin is how far the best agent must score above the runner-up.
+	// Zero skips the margin check.
+	MinMargin float32
+	// Reranked is true when discovery reranks its results, so the
+	// thresholds are reranker scores.
+	Reranked bool
+}
+
+// checks reports whether the policy sets any threshold.
+func (p RoutePolicy) checks() bool {
+	return p.MinScore > 0 || len(p.TagMinScores) > 0 || p.MinMargin > 0
+}
+
+// scaled reports whether result's scores are on the thresholds' scale.
+func (p RoutePolicy) scaled(result *store.SearchResult) bool {
+	if p.Reranked {
+		return result.Reranked
+	}
+	return !result.Reranked && !result.Lexical
+}
+
+// minScore returns the score agent needs under the policy.
+func (p RoutePolicy) minScore(agent *store.RegisteredAgent) float32 {
+	threshold := p.MinScore
+	for _, tag := range agent.Tags {
+		if m, ok := p.TagMinScores[tag]; ok && m > threshold {
+			threshold = m
+		}
+	}
+	return threshold
+}
+
+// classify rates the best of result's ranked candidates, best first.
+func (p RoutePolicy) classify(result *store.SearchResult) Confidence {
+	candidates, reranked := result.Agents, result.Reranked
+	if len(candidates) == 0 {
+		return ConfidenceNone
+	}
+	if p.checks() && !p.scaled(result) {
+		return ConfidenceLow
+	}
+	best := candidates[0]
+	if routeScore(best, reranked) < p.minScore(best.Agent) {
+		return ConfidenceLow
+	}
+	if p.MinMargin > 0 && len(candidates) > 1 {
+		// A runner-up that fails its own threshold is no real contender.
+		runnerUp := candidates[1]
+		score := routeScore(runnerUp, reranked)
+		if score >= p.minScore(runnerUp.Agent) && routeScore(best, reranked)-score < p.MinMargin {
+			return ConfidenceAmbiguous
+		}
+	}
+	return ConfidenceHigh
+}
+
+// routeScore returns the score that ranked agent.
+func routeScore(agent store.ScoredAgent, reranked bool) float32 {
+	if reranked {
+		return agent.RerankScore
+	}
+	return agent.Score
+}
@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"context"
+
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
@@ -9,39 +11,49 @@ import (
 
 // NewDiscoverTool creates a tool for discovering agents by semantic search.
 func NewDiscoverTool(reg *registry.RegistryService) (tool.Tool, error) {
+	schema, err := argsSchema[DiscoverArgs]()
+	if err != nil {
+		return nil, err
+	}
+
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "discover",
 			Description: "Find agents matching a natural language query. Returns a list of agents ranked by relevance.",
+			InputSchema: schema,
 		},
 		func(ctx tool.Context, args DiscoverArgs) (DiscoverResult, error) {
-			limit := args.Limit
-			if limit <= 0 {
-				limit = 10
-			}
-
-			result, err := reg.Discover(ctx, registry.DiscoverInput{
-				Query:  args.Query,
-				Limit:  limit,
-				Tags:   args.Tags,
-				Skills: args.Skills,
-			})
-			if err != nil {
-				return DiscoverResult{}, err
-			}
-
-			agents := make([]ScoredAgent, 0, len(result.Agents))
-			for _, scored := range result.Agents {
-				agents = append(agents, ScoredAgent{
-					Card:  scored.Agent.Card,
-					Score: scored.Score,
-				})
-			}
-
-			return DiscoverResult{
-				Agents: agents,
-				Total:  len(agents),
-			}, nil
+			return Discover(ctx, reg, args)
 		},
 	)
 }
+
+// Discover finds the agents matching args.
+func Discover(ctx context.Context, reg *registry.RegistryService, args DiscoverArgs) (DiscoverResult, error) {
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	result, err := reg.Discover(ctx, registry.DiscoverInput{
+		Query:  args.Query,
+		Limit:  limit,
+		Tags:   args.Tags,
+		Skills: args.Skills,
+		Filter: args.Filter,
+	})
+	if err != nil {
+		return DiscoverResult{}, err
+	}
+
+	agents := make([]ScoredAgent, 0, len(result.Agents))
+	for _, scored := range result.Agents {
+		agents = append(agents, toScoredAgent(scored))
+	}
+
+	return DiscoverResult{
+		Agents:  agents,
+		Total:   len(agents),
+		Lexical: result.Lexical,
+	}, nil
+}
@@ -1,42 +1,111 @@
 package tools
 
 import (
+	"context"
+
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/genaitext"
 )
 
-// NewRouteTool creates a tool for routing to the best matching agent.
-func NewRouteTool(reg *registry.RegistryService) (tool.Tool, error) {
+// NewRouteTool creates a tool for routing to the best matching agent. With
+// a non-nil delegator the request is also forwarded to that agent and its
+// reply returned. policy decides when the best match is trusted.
+func NewRouteTool(reg *registry.RegistryService, delegator *delegate.Delegator, policy RoutePolicy) (tool.Tool, error) {
+	description := "Find the single best agent for a task. Use this when you need to forward a request to the most relevant agent."
+	if delegator != nil {
+		description = "Find the single best agent for a task, forward the request to it and return its reply."
+	}
+
+	schema, err := argsSchema[RouteArgs]()
+	if err != nil {
+		return nil, err
+	}
+
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "route",
-			Description: "Find the single best agent for a task. Use this when you need to forward a request to the most relevant agent.",
+			Description: description,
+			InputSchema: schema,
 		},
 		func(ctx tool.Context, args RouteArgs) (RouteResult, error) {
-			result, err := reg.Discover(ctx, registry.DiscoverInput{
-				Query:  args.Query,
-				Limit:  1,
-				Tags:   args.Tags,
-				Skills: args.Skills,
-			})
-			if err != nil {
-				return RouteResult{}, err
-			}
-
-			if len(result.Agents) == 0 {
-				return RouteResult{Found: false}, nil
-			}
-
-			agent := result.Agents[0]
-			return RouteResult{
-				Agent: &ScoredAgent{
-					Card:  agent.Agent.Card,
-					Score: agent.Score,
-				},
-				Found: true,
-			}, nil
+			args.Message = delegatedMessage(ctx, args.Message, args.Query)
+			return Route(ctx, reg, delegator, policy, args)
 		},
 	)
 }
+
+// Route finds the best agent matching args and rates it under policy. Only
+// a high-confidence match is reported as found; with a non-nil delegator
+// args.Message, or the query if it is empty, is then forwarded to it.
+func Route(ctx context.Context, reg *registry.RegistryService, delegator *delegate.Delegator, policy RoutePolicy, args RouteArgs) (RouteResult, error) {
+	limit := 1
+	if policy.MinMargin > 0 {
+		limit = 2
+	}
+	result, err := reg.Discover(ctx, registry.DiscoverInput{
+		Query:  args.Query,
+		Limit:  limit,
+		Tags:   args.Tags,
+		Skills: args.Skills,
+		Filter: args.Filter,
+	})
+	if err != nil {
+		return RouteResult{}, err
+	}
+
+	confidence := policy.classify(result)
+	if confidence == ConfidenceNone {
+		return RouteResult{Found: false, Confidence: confidence, Lexical: result.Lexical}, nil
+	}
+
+	agent := result.Agents[0]
+	best := toScoredAgent(agent)
+	routed := RouteResult{
+		Agent:      &best,
+		Found:      confidence == ConfidenceHigh,
+		Confidence: confidence,
+		Lexical:    result.Lexical,
+	}
+	if confidence == ConfidenceAmbiguous {
+		routed.Alternatives = []ScoredAgent{toScoredAgent(result.Agents[1])}
+	}
+	if delegator != nil && routed.Found {
+		routed.Delegation = delegator.Send(ctx, delegate.Target{AgentID: agent.Agent.ID, Card: agent.Agent.Card}, messageOrQuery(args.Message, args.Query))
+	}
+	return routed, nil
+}
+
+// toScoredAgent converts a search hit into its tool result form.
+func toScoredAgent(scored store.ScoredAgent) ScoredAgent {
+	return ScoredAgent{
+		Card:         scored.Agent.Card,
+		Score:        scored.Score,
+		MatchedSkill: scored.MatchedSkill,
+		RerankScore:  scored.RerankScore,
+	}
+}
+
+// delegatedMessage returns the text to forward: the explicit message if
+// given, else the user's original message, else the search query.
+func delegatedMessage(ctx tool.Context, message, query string) string {
+	if message != "" {
+		return message
+	}
+	if text := genaitext.Join(ctx.UserContent()); text != "" {
+		return text
+	}
+	return query
+}
+
+// messageOrQuery returns message, or query when message is empty.
+func messageOrQuery(message, query string) string {
+	if message != "" {
+		return message
+	}
+	return query
+}
@@ -1,6 +1,32 @@
 package tools
 
-import "github.com/a2aproject/a2a-go/a2a"
+import (
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
+)
+
+// filterDescription documents the filter property of the tools that accept
+// a filter expression.
+const filterDescription = "Optional filter expression, e.g. tags in (prod, beta) AND NOT skills all (translate, summarize) OR created_at >= 2025-01-01. " +
+	"Fields: id, name, description, version, tags, skills, created_at, updated_at. " +
+	"Operators: = in != all ~ > >= < <=; ~ matches whole words, ignoring case. Combine with AND, OR, NOT and parentheses."
+
+// argsSchema infers the input schema of a tool's arguments and describes
+// its filter property with filterDescription.
+func argsSchema[T any]() (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return nil, fmt.Errorf("infer input schema: %w", err)
+	}
+	if filter, ok := schema.Properties["filter"]; ok {
+		filter.Description = filterDescription
+	}
+	return schema, nil
+}
 
 // DiscoverArgs are the arguments for the discover tool.
 type DiscoverArgs struct {
@@ -12,6 +38,8 @@ type DiscoverArgs struct {
 	Tags []string `json:"tags,omitempty"`
 	// Skills filters by skill IDs.
 	Skills []string `json:"skills,omitempty"`
+	// Filter is an optional filter expression over agent fields.
+	Filter string `json:"filter,omitempty"`
 }
 
 // RouteArgs are the arguments for the route tool.
@@ -22,6 +50,10 @@ type RouteArgs struct {
 	Tags []string `json:"tags,omitempty"`
 	// Skills filters by skill IDs.
 	Skills []string `json:"skills,omitempty"`
+	// Filter is an optional filter expression over agent fields.
+	Filter string `json:"filter,omitempty"`
+	// Message is the text forwarded to the chosen agent in delegation mode.
+	Message string `json:"message,omitempty" jsonschema:"Message to forward to the chosen agent when delegation is enabled. Defaults to the user's original message."`
 }
 
 // BroadcastArgs are the arguments for the broadcast tool.
@@ -34,6 +66,10 @@ type BroadcastArgs struct {
 	Tags []string `json:"tags,omitempty"`
 	// Skills filters by skill IDs.
 	Skills []string `json:"skills,omitempty"`
+	// Filter is an optional filter expression over agent fields.
+	Filter string `json:"filter,omitempty"`
+	// Message is the text sent to every selected agent in delegation mode.
+	Message string `json:"message,omitempty" jsonschema:"Message to send to the selected agents when delegation is enabled. Defaults to the user's original message."`
 }
 
 // ScoredAgent represents an agent with a relevance score.
@@ -42,6 +78,11 @@ type ScoredAgent struct {
 	Card a2a.AgentCard `json:"card"`
 	// Score is the relevance score.
 	Score float32 `json:"score"`
+	// MatchedSkill is the ID of the skill that best matched the query.
+	MatchedSkill string `json:"matched_skill,omitempty"`
+	// RerankScore is the second-stage reranker score, which decided the
+	// ranking when set.
+	RerankScore float32 `json:"rerank_score,omitempty"`
 }
 
 // DiscoverResult is the result of the discover tool.
@@ -50,14 +91,31 @@ type DiscoverResult struct {
 	Agents []ScoredAgent `json:"agents"`
 	// Total is the total number of results.
 	Total int `json:"total"`
+	// Lexical is true when agents were ranked by keyword overlap because
+	// semantic search was unavailable.
+	Lexical bool `json:"lexical,omitempty"`
 }
 
 // RouteResult is the result of the route tool.
 type RouteResult struct {
-	// Agent is the best matching agent.
+	// Agent is the best matching agent, also set when the match was not
+	// trusted so it can be offered to the user.
 	Agent *ScoredAgent `json:"agent,omitempty"`
-	// Found indicates whether a matching agent was found.
+	// Found indicates whether a matching agent was found with high
+	// confidence. Only found agents are delegated to.
 	Found bool `json:"found"`
+	// Confidence is "high", "ambiguous" (the runner-up scored too close),
+	// "low" (the best score is below the threshold or on another scale)
+	// or "none".
+	Confidence Confidence `json:"confidence"`
+	// Alternatives are the agents the best match could not be told apart
+	// from, set when the confidence is ambiguous.
+	Alternatives []ScoredAgent `json:"alternatives,omitempty"`
+	// Lexical is true when the agent was chosen by keyword overlap because
+	// semantic search was unavailable.
+	Lexical bool `json:"lexical,omitempty"`
+	// Delegation is the chosen agent's reply when delegation is enabled.
+	Delegation *delegate.Result `json:"delegation,omitempty"`
 }
 
 // BroadcastResult is the result of the broadcast tool.
@@ -66,4 +124,9 @@ type BroadcastResult struct {
 	Agents []ScoredAgent `json:"agents"`
 	// Total is the total number of agents.
 	Total int `json:"total"`
+	// Lexical is true when agents were ranked by keyword overlap because
+	// semantic search was unavailable.
+	Lexical bool `json:"lexical,omitempty"`
+	// Delegation holds each agent's reply when delegation is enabled.
+	Delegation *delegate.BroadcastResult `json:"delegation,omitempty"`
 }
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds application configuration from environment variables.
@@ -13,34 +15,202 @@ type Config struct {
 	// LogLevel is the minimum log level for logging.
 	LogLevel slog.Level
 
+	// StoreBackend selects the agent store ("qdrant" or "memory").
+	StoreBackend string
+
+	// Memory store config
+	MemoryHNSW   bool
+	HNSWM        int
+	HNSWEfSearch int
+
 	// Qdrant config
 	QdrantHost   string
 	QdrantPort   int
 	QdrantAPIKey string
 	QdrantUseTLS bool
 
+	// Cache config
+	CacheEnabled   bool
+	CacheAgentTTL  time.Duration
+	CacheListTTL   time.Duration
+	CacheSearchTTL time.Duration
+
 	// Embedding config
-	EmbeddingURL string
-	EmbeddingDim int
+	EmbeddingProvider string
+	EmbeddingURL      string
+	EmbeddingDim      int
+	EmbeddingModel    string
+	EmbeddingProbe    bool
+
+	// Embedding provider auth config
+	EmbeddingAPIKey  string
+	EmbeddingHeaders map[string]string
+	EmbeddingPath    string
+
+	// Embedding instruction prefixes per mode
+	EmbeddingQueryPrefix    string
+	EmbeddingDocumentPrefix string
+
+	// Embedding text template for agent profiles, inline or from a file
+	EmbeddingTemplate     string
+	EmbeddingTemplateFile string
+
+	// Embedding text chunking config
+	EmbeddingChunkWords   int
+	EmbeddingChunkOverlap int
+	EmbeddingPooling      string
+	EmbeddingChunkDryRun  bool
+
+	// Embedding resilience config
+	EmbeddingMaxRetries       int
+	EmbeddingRetryBackoff     time.Duration
+	EmbeddingRetryMaxBackoff  time.Duration
+	EmbeddingBreakerThreshold int
+	EmbeddingBreakerCooldown  time.Duration
+
+	// Embedding batching config
+	EmbeddingBatchSize   int
+	EmbeddingBatchWindow time.Duration
+
+	// Embedding cache config
+	EmbeddingCacheEnabled bool
+	EmbeddingCacheSize    int
+	EmbeddingCacheDir     string
+
+	// Rerank config
+	RerankProvider   string
+	RerankURL        string
+	RerankAPIKey     string
+	RerankCandidates int
 
 	// Gemini config
 	GeminiAPIKey string
 	GeminiModel  string
+
+	// Chat model provider config
+	LLMProvider string
+	LLMURL      string
+	LLMModel    string
+	LLMAPIKey   string
+	LLMHeaders  map[string]string
+	LLMPath     string
+
+	// Delegation config
+	BrokerDelegate                  bool
+	BrokerDelegateTimeout           time.Duration
+	BrokerDelegateStreamIdleTimeout time.Duration
+
+	// Broadcast fan-out config
+	BrokerBroadcastDeadline    time.Duration
+	BrokerBroadcastConcurrency int
+
+	// Downstream task tracking config
+	BrokerTaskStoreDir      string
+	BrokerTaskRetention     time.Duration
+	BrokerTaskPruneInterval time.Duration
+
+	// Route confidence config
+	RouteMinScore     float32
+	RouteTagMinScores map[string]float32
+	RouteMinMargin    float32
+
+	// Broker mode config
+	BrokerMode          string
+	BrokerDefaultAction string
+
+	// Session config
+	SessionBackend       string
+	SessionDBPath        string
+	SessionRetention     time.Duration
+	SessionPruneInterval time.Duration
 }
 
 // Load reads configuration from environment variables with sensible defaults.
 func Load() *Config {
 	return &Config{
-		Port:         getEnvInt("PORT", 8080),
-		LogLevel:     getEnvLogLevel("LOG_LEVEL", slog.LevelInfo),
-		QdrantHost:   getEnv("QDRANT_HOST", "localhost"),
-		QdrantPort:   getEnvInt("QDRANT_PORT", 6334),
-		QdrantAPIKey: getEnv("QDRANT_API_KEY", ""),
-		QdrantUseTLS: getEnvBool("QDRANT_USE_TLS", false),
-		EmbeddingURL: getEnv("EMBEDDING_URL", "http://localhost:8081"),
-		EmbeddingDim: getEnvInt("EMBEDDING_DIM", 384),
-		GeminiAPIKey: getEnv("GEMINI_API_KEY", ""),
-		GeminiModel:  getEnv("GEMINI_MODEL", "gemini-3-flash-preview"),
+		Port:              getEnvInt("PORT", 8080),
+		LogLevel:          getEnvLogLevel("LOG_LEVEL", slog.LevelInfo),
+		StoreBackend:      getEnv("STORE_BACKEND", "qdrant"),
+		MemoryHNSW:        getEnvBool("MEMORY_HNSW", false),
+		HNSWM:             getEnvInt("HNSW_M", 16),
+		HNSWEfSearch:      getEnvInt("HNSW_EF_SEARCH", 64),
+		QdrantHost:        getEnv("QDRANT_HOST", "localhost"),
+		QdrantPort:        getEnvInt("QDRANT_PORT", 6334),
+		QdrantAPIKey:      getEnv("QDRANT_API_KEY", ""),
+		QdrantUseTLS:      getEnvBool("QDRANT_USE_TLS", false),
+		CacheEnabled:      getEnvBool("CACHE_ENABLED", true),
+		CacheAgentTTL:     getEnvDuration("CACHE_AGENT_TTL", 5*time.Minute),
+		CacheListTTL:      getEnvDuration("CACHE_LIST_TTL", 30*time.Second),
+		CacheSearchTTL:    getEnvDuration("CACHE_SEARCH_TTL", 10*time.Second),
+		EmbeddingProvider: getEnv("EMBEDDING_PROVIDER", "openai"),
+		EmbeddingURL:      getEnv("EMBEDDING_URL", "http://localhost:8081"),
+		EmbeddingDim:      getEnvInt("EMBEDDING_DIM", 0),
+		EmbeddingModel:    getEnv("EMBEDDING_MODEL", ""),
+		EmbeddingProbe:    getEnvBool("EMBEDDING_PROBE", true),
+		RerankProvider:    getEnv("RERANK_PROVIDER", "none"),
+		RerankURL:         getEnv("RERANK_URL", "http://localhost:8081"),
+		RerankAPIKey:      getEnv("RERANK_API_KEY", ""),
+		RerankCandidates:  getEnvInt("RERANK_CANDIDATES", 20),
+		GeminiAPIKey:      getEnv("GEMINI_API_KEY", ""),
+		GeminiModel:       getEnv("GEMINI_MODEL", "gemini-3-flash-preview"),
+
+		EmbeddingAPIKey:  getEnv("EMBEDDING_API_KEY", ""),
+		EmbeddingHeaders: getEnvMap("EMBEDDING_HEADERS"),
+		EmbeddingPath:    getEnv("EMBEDDING_PATH", ""),
+
+		EmbeddingQueryPrefix:    getEnv("EMBEDDING_QUERY_PREFIX", ""),
+		EmbeddingDocumentPrefix: getEnv("EMBEDDING_DOCUMENT_PREFIX", ""),
+
+		EmbeddingTemplate:     getEnv("EMBEDDING_TEMPLATE", ""),
+		EmbeddingTemplateFile: getEnv("EMBEDDING_TEMPLATE_FILE", ""),
+
+		EmbeddingChunkWords:   getEnvInt("EMBEDDING_CHUNK_WORDS", 300),
+		EmbeddingChunkOverlap: getEnvInt("EMBEDDING_CHUNK_OVERLAP", 30),
+		EmbeddingPooling:      getEnv("EMBEDDING_POOLING", "mean"),
+		EmbeddingChunkDryRun:  getEnvBool("EMBEDDING_CHUNK_DRY_RUN", false),
+
+		EmbeddingMaxRetries:       getEnvInt("EMBEDDING_MAX_RETRIES", 3),
+		EmbeddingRetryBackoff:     getEnvDuration("EMBEDDING_RETRY_BACKOFF", 200*time.Millisecond),
+		EmbeddingRetryMaxBackoff:  getEnvDuration("EMBEDDING_RETRY_MAX_BACKOFF", 5*time.Second),
+		EmbeddingBreakerThreshold: getEnvInt("EMBEDDING_BREAKER_THRESHOLD", 5),
+		EmbeddingBreakerCooldown:  getEnvDuration("EMBEDDING_BREAKER_COOLDOWN", 30*time.Second),
+
+		EmbeddingBatchSize:   getEnvInt("EMBEDDING_BATCH_SIZE", 32),
+		EmbeddingBatchWindow: getEnvDuration("EMBEDDING_BATCH_WINDOW", 5*time.Millisecond),
+
+		EmbeddingCacheEnabled: getEnvBool("EMBEDDING_CACHE_ENABLED", true),
+		EmbeddingCacheSize:    getEnvInt("EMBEDDING_CACHE_SIZE", 10000),
+		EmbeddingCacheDir:     getEnv("EMBEDDING_CACHE_DIR", ""),
+
+		BrokerDelegate:                  getEnvBool("BROKER_DELEGATE", false),
+		BrokerDelegateTimeout:           getEnvDuration("BROKER_DELEGATE_TIMEOUT", 10*time.Second),
+		BrokerDelegateStreamIdleTimeout: getEnvDuration("BROKER_DELEGATE_STREAM_IDLE_TIMEOUT", 30*time.Second),
+
+		BrokerBroadcastDeadline:    getEnvDuration("BROKER_BROADCAST_DEADLINE", 12*time.Second),
+		BrokerBroadcastConcurrency: getEnvInt("BROKER_BROADCAST_CONCURRENCY", 8),
+
+		BrokerTaskStoreDir:      getEnv("BROKER_TASK_STORE_DIR", ""),
+		BrokerTaskRetention:     getEnvDuration("BROKER_TASK_RETENTION", 168*time.Hour),
+		BrokerTaskPruneInterval: getEnvDuration("BROKER_TASK_PRUNE_INTERVAL", 10*time.Minute),
+
+		LLMProvider: getEnv("LLM_PROVIDER", "gemini"),
+		LLMURL:      getEnv("LLM_URL", "http://localhost:8000"),
+		LLMModel:    getEnv("LLM_MODEL", ""),
+		LLMAPIKey:   getEnv("LLM_API_KEY", ""),
+		LLMHeaders:  getEnvMap("LLM_HEADERS"),
+		LLMPath:     getEnv("LLM_PATH", ""),
+
+		RouteMinScore:     getEnvFloat("ROUTE_MIN_SCORE", 0),
+		RouteTagMinScores: getEnvFloatMap("ROUTE_TAG_MIN_SCORES"),
+		RouteMinMargin:    getEnvFloat("ROUTE_MIN_MARGIN", 0),
+
+		BrokerMode:          getEnv("BROKER_MODE", "llm"),
+		BrokerDefaultAction: getEnv("BROKER_DEFAULT_ACTION", "route"),
+
+		SessionBackend:       getEnv("SESSION_BACKEND", "memory"),
+		SessionDBPath:        getEnv("SESSION_DB_PATH", "data/sessions.db"),
+		SessionRetention:     getEnvDuration("SESSION_RETENTION", 7*24*time.Hour),
+		SessionPruneInterval: getEnvDuration("SESSION_PRUNE_INTERVAL", 10*time.Minute),
 	}
 }
 
@@ -72,6 +242,60 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 }
 
+func getEnvFloat(key string, defaultValue float32) float32 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 32); err == nil {
+			return float32(parsed)
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvMap parses a comma-separated list of key=value pairs. Entries
+// without "=" are ignored.
+func getEnvMap(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if k = strings.TrimSpace(k); k != "" {
+			result[k] = strings.TrimSpace(v)
+		}
+	}
+	return result
+}
+
+// getEnvFloatMap parses a comma-separated list of key=number pairs.
+// Entries whose value is not a number are ignored.
+func getEnvFloatMap(key string) map[string]float32 {
+	pairs := getEnvMap(key)
+	if pairs == nil {
+		return nil
+	}
+	result := make(map[string]float32, len(pairs))
+	for k, v := range pairs {
+		if parsed, err := strconv.ParseFloat(v, 32); err == nil {
+			result[k] = float32(parsed)
+		}
+	}
+	return result
+}
+
 func getEnvLogLevel(key string, defaultValue slog.Level) slog.Level {
 	value := getEnv(key, "")
 	switch value {
@@ -0,0 +1,438 @@
+// Package delegate forwards broker requests to downstream agents over A2A.
+package delegate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+)
+
+// Options configures the Delegator.
+type Options struct {
+	// Timeout bounds a single downstream call that is not streamed.
+	Timeout time.Duration
+	// StreamIdleTimeout bounds the wait for each event of a streamed call,
+	// so a stream lasts as long as the agent keeps sending events.
+	StreamIdleTimeout time.Duration
+	// BroadcastDeadline bounds a whole broadcast. Agents not answered by
+	// then are reported as timed out.
+	BroadcastDeadline time.Duration
+	// MaxConcurrency is the most downstream calls a broadcast makes at once.
+	MaxConcurrency int
+	// HTTPClient is used for JSON-RPC calls to downstream agents.
+	HTTPClient *http.Client
+	// TaskStore records which downstream tasks each broker task created.
+	// Nil disables tracking.
+	TaskStore TaskStore
+	// Logger reports failures to record task links.
+	Logger *slog.Logger
+}
+
+// DefaultOptions returns sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		Timeout:           10 * time.Second,
+		StreamIdleTimeout: 30 * time.Second,
+		BroadcastDeadline: 12 * time.Second,
+		MaxConcurrency:    8,
+		HTTPClient:        &http.Client{},
+		Logger:            slog.Default(),
+	}
+}
+
+// Option is a functional option for configuring the Delegator.
+type Option func(*Options)
+
+// WithTimeout sets the per-call timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.Timeout = d
+		}
+	}
+}
+
+// WithStreamIdleTimeout sets the longest wait for the next event of a
+// streamed call.
+func WithStreamIdleTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.StreamIdleTimeout = d
+		}
+	}
+}
+
+// WithBroadcastDeadline sets the deadline for a whole broadcast.
+func WithBroadcastDeadline(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.BroadcastDeadline = d
+		}
+	}
+}
+
+// WithMaxConcurrency sets the most downstream calls a broadcast makes at once.
+func WithMaxConcurrency(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.MaxConcurrency = n
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for downstream calls.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		if client != nil {
+			o.HTTPClient = client
+		}
+	}
+}
+
+// Target is an agent to delegate to.
+type Target struct {
+	// AgentID is the registry ID of the agent.
+	AgentID string
+	// Card is the agent's A2A card.
+	Card a2a.AgentCard
+}
+
+// WithTaskStore records downstream tasks in store.
+func WithTaskStore(store TaskStore) Option {
+	return func(o *Options) {
+		o.TaskStore = store
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		if logger != nil {
+			o.Logger = logger
+		}
+	}
+}
+
+// Result is the outcome of a delegated call. Failures are reported in the
+// result rather than returned, so the broker can relay them to its caller.
+type Result struct {
+	// State is the downstream task state, completed for a plain message
+	// reply and failed when the call itself failed.
+	State a2a.TaskState `json:"state"`
+	// TaskID is the downstream task ID, empty for a message reply.
+	TaskID a2a.TaskID `json:"task_id,omitempty"`
+	// ContextID is the downstream context ID.
+	ContextID string `json:"context_id,omitempty"`
+	// Reply is the text of the agent's reply.
+	Reply string `json:"reply,omitempty"`
+	// Error describes why the call failed.
+	Error string `json:"error,omitempty"`
+	// TimedOut is true when the agent did not answer within the timeout.
+	TimedOut bool `json:"timed_out,omitempty"`
+	// LatencyMS is how long the call took in milliseconds.
+	LatencyMS int64 `json:"latency_ms"`
+}
+
+// Failed reports whether the call or the downstream task failed.
+func (r *Result) Failed() bool {
+	switch r.State {
+	case a2a.TaskStateFailed, a2a.TaskStateRejected, a2a.TaskStateCanceled:
+		return true
+	default:
+		return r.Error != ""
+	}
+}
+
+// Pending reports whether the downstream task is still in progress, such as
+// working or waiting for input or authentication, so Reply is not final.
+func (r *Result) Pending() bool {
+	return !r.Failed() && !r.State.Terminal()
+}
+
+// Delegator sends messages to downstream agents with an A2A client.
+type Delegator struct {
+	// factory creates A2A clients from agent cards.
+	factory *a2aclient.Factory
+	// timeout bounds a single downstream call that is not streamed.
+	timeout time.Duration
+	// streamIdle bounds the wait for each event of a streamed call.
+	streamIdle time.Duration
+	// deadline bounds a whole broadcast.
+	deadline time.Duration
+	// concurrency caps parallel calls during a broadcast.
+	concurrency int
+	// tasks records downstream tasks, or nil.
+	tasks TaskStore
+	// logger reports failures to record task links.
+	logger *slog.Logger
+}
+
+// NewDelegator creates a Delegator.
+func NewDelegator(opts ...Option) *Delegator {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Delegator{
+		factory:     a2aclient.NewFactory(a2aclient.WithJSONRPCTransport(options.HTTPClient)),
+		timeout:     options.Timeout,
+		streamIdle:  options.StreamIdleTimeout,
+		deadline:    options.BroadcastDeadline,
+		concurrency: options.MaxConcurrency,
+		tasks:       options.TaskStore,
+		logger:      options.Logger,
+	}
+}
+
+// Timeout returns the per-call timeout.
+func (d *Delegator) Timeout() time.Duration {
+	return d.timeout
+}
+
+// broadcastWaves is how many rounds of MaxConcurrency calls a broadcast
+// may need; more targets could not be answered before the deadline anyway.
+const broadcastWaves = 2
+
+// MaxBroadcastTargets returns the most agents a broadcast should be sent to.
+func (d *Delegator) MaxBroadcastTargets() int {
+	return d.concurrency * broadcastWaves
+}
+
+// Send sends text as a user message to target and waits for its reply or
+// task. When ctx carries a Relay and the agent supports streaming, its
+// events are relayed into the broker task as they arrive.
+func (d *Delegator) Send(ctx context.Context, target Target, text string) *Result {
+	start := time.Now()
+	result := d.send(ctx, target, text)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	return result
+}
+
+// send performs a single downstream call. Streamed calls are bounded by
+// the stream idle timeout instead of the call timeout.
+func (d *Delegator) send(parent context.Context, target Target, text string) *Result {
+	params := &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: text}),
+	}
+	if relay := RelayFromContext(parent); relay != nil && target.Card.Capabilities.Streaming {
+		return d.sendStream(parent, relay, target, params)
+	}
+
+	ctx, cancel := context.WithTimeout(parent, d.timeout)
+	defer cancel()
+
+	client, err := d.newClient(ctx, target.Card)
+	if err != nil {
+		return d.failure(parent, ctx, err)
+	}
+	defer func() { _ = client.Destroy() }()
+
+	resp, err := client.SendMessage(ctx, params)
+	if err != nil {
+		return d.failure(parent, ctx, fmt.Errorf("send message: %w", err))
+	}
+
+	switch r := resp.(type) {
+	case *a2a.Message:
+		return &Result{
+			State:     a2a.TaskStateCompleted,
+			ContextID: r.ContextID,
+			Reply:     partsText(r.Parts),
+		}
+	case *a2a.Task:
+		d.link(ctx, target, r)
+		return &Result{
+			State:     r.Status.State,
+			TaskID:    r.ID,
+			ContextID: r.ContextID,
+			Reply:     taskText(r),
+		}
+	default:
+		return &Result{State: a2a.TaskStateFailed, Error: fmt.Sprintf("unexpected response %T", resp)}
+	}
+}
+
+// newClient creates a client for card. Cards that do not name a transport
+// are assumed to speak JSON-RPC, the A2A default.
+func (d *Delegator) newClient(ctx context.Context, card a2a.AgentCard) (*a2aclient.Client, error) {
+	if card.PreferredTransport == "" {
+		card.PreferredTransport = a2a.TransportProtocolJSONRPC
+	}
+	client, err := d.factory.CreateFromCard(ctx, &card)
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+	return client, nil
+}
+
+// link records that the broker task in ctx delegated to a downstream task.
+// Without a task store or a broker task in ctx it does nothing.
+func (d *Delegator) link(ctx context.Context, target Target, task a2a.TaskInfoProvider) {
+	relay := RelayFromContext(ctx)
+	info := task.TaskInfo()
+	if d.tasks == nil || relay == nil || info.TaskID == "" {
+		return
+	}
+	broker := relay.task.TaskInfo()
+	err := d.tasks.Save(context.WithoutCancel(ctx), TaskLink{
+		BrokerTaskID:    broker.TaskID,
+		BrokerContextID: broker.ContextID,
+		AgentID:         target.AgentID,
+		AgentURL:        target.Card.URL,
+		Transport:       target.Card.PreferredTransport,
+		TaskID:          info.TaskID,
+		ContextID:       info.ContextID,
+		CreatedAt:       time.Now().UTC(),
+	})
+	if err != nil {
+		d.logger.WarnContext(ctx, "failed to record downstream task",
+			"broker_task_id", broker.TaskID, "agent_id", target.AgentID,
+			"task_id", info.TaskID, "error", err)
+	}
+}
+
+// errStreamIdle cancels a streamed call whose agent stopped sending events.
+var errStreamIdle = errors.New("stream idle")
+
+// errEmptyStream fails a streamed call whose agent ended the stream without
+// a reply or task status.
+var errEmptyStream = errors.New("agent stream ended without a response")
+
+// failure builds the result for a failed call. Errors caused by the
+// caller's context are reported as such; the per-call deadline and an idle
+// stream are reported as timeouts.
+func (d *Delegator) failure(parent, ctx context.Context, err error) *Result {
+	switch {
+	case errors.Is(parent.Err(), context.Canceled):
+		return &Result{State: a2a.TaskStateCanceled, Error: "canceled before the agent responded"}
+	case errors.Is(parent.Err(), context.DeadlineExceeded):
+		return &Result{State: a2a.TaskStateFailed, Error: "deadline exceeded before the agent responded", TimedOut: true}
+	case errors.Is(context.Cause(ctx), errStreamIdle):
+		return &Result{
+			State:    a2a.TaskStateFailed,
+			Error:    fmt.Sprintf("agent stream sent no event for %s", d.streamIdle),
+			TimedOut: true,
+		}
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &Result{
+			State:    a2a.TaskStateFailed,
+			Error:    fmt.Sprintf("agent did not respond within %s", d.timeout),
+			TimedOut: true,
+		}
+	default:
+		return &Result{State: a2a.TaskStateFailed, Error: err.Error()}
+	}
+}
+
+// taskText returns the text of a task's artifacts, falling back to its
+// status message and then the last agent message in its history.
+func taskText(task *a2a.Task) string {
+	var texts []string
+	for _, artifact := range task.Artifacts {
+		if text := partsText(artifact.Parts); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n")
+	}
+	if task.Status.Message != nil {
+		if text := partsText(task.Status.Message.Parts); text != "" {
+			return text
+		}
+	}
+	for i := len(task.History) - 1; i >= 0; i-- {
+		if msg := task.History[i]; msg.Role == a2a.MessageRoleAgent {
+			return partsText(msg.Parts)
+		}
+	}
+	return ""
+}
+
+// partsText joins the text parts of a message or artifact.
+func partsText(parts a2a.ContentParts) string {
+	var texts []string
+	for _, part := range parts {
+		if p, ok := part.(a2a.TextPart); ok && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// AgentResult is one agent's outcome in a broadcast.
+type AgentResult struct {
+	// AgentID is the registry ID of the agent.
+	AgentID string `json:"agent_id"`
+	// Name is the agent's display name.
+	Name string `json:"name"`
+	Result
+}
+
+// BroadcastResult aggregates the outcomes of a broadcast.
+type BroadcastResult struct {
+	// Results holds one entry per target, in target order.
+	Results []AgentResult `json:"results"`
+	// Succeeded is the number of agents that answered with a message or a
+	// completed task.
+	Succeeded int `json:"succeeded"`
+	// Pending is the number of agents whose task is not finished yet, e.g.
+	// working or waiting for input; their replies are partial.
+	Pending int `json:"pending"`
+	// Failed is the number of agents that failed, timed out or were not
+	// reached before the deadline.
+	Failed int `json:"failed"`
+	// LatencyMS is how long the whole broadcast took in milliseconds.
+	LatencyMS int64 `json:"latency_ms"`
+}
+
+// Broadcast sends text to every target in parallel, at most MaxConcurrency
+// at a time, and waits until all have answered or the broadcast deadline
+// passes. Cancelling ctx cancels the pending downstream calls.
+func (d *Delegator) Broadcast(ctx context.Context, targets []Target, text string) *BroadcastResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, d.deadline)
+	defer cancel()
+
+	results := make([]AgentResult, len(targets))
+	sem := make(chan struct{}, d.concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		results[i] = AgentResult{AgentID: target.AgentID, Name: target.Card.Name}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Result = *d.failure(ctx, ctx, ctx.Err())
+				return
+			}
+			results[i].Result = *d.Send(ctx, target, text)
+		}()
+	}
+	wg.Wait()
+
+	summary := &BroadcastResult{Results: results, LatencyMS: time.Since(start).Milliseconds()}
+	for i := range results {
+		switch {
+		case results[i].Failed():
+			summary.Failed++
+		case results[i].Pending():
+			summary.Pending++
+		default:
+			summary.Succeeded++
+		}
+	}
+	return summary
+}
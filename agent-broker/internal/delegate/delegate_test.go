@@ -0,0 +1,291 @@
+package delegate
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// fakeExecutor is a downstream agent that answers according to the
+// incoming message text.
+type fakeExecutor struct{}
+
+func (fakeExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	text := partsText(reqCtx.Message.Parts)
+	switch {
+	case strings.HasPrefix(text, "slow"):
+		<-ctx.Done()
+		return ctx.Err()
+	case strings.HasPrefix(text, "task"):
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+		if err := queue.Write(ctx, a2a.NewArtifactEvent(reqCtx, a2a.TextPart{Text: "report for " + text})); err != nil {
+			return err
+		}
+		done := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+		done.Final = true
+		return queue.Write(ctx, done)
+	case strings.HasPrefix(text, "stream"):
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+		working := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: "thinking"})
+		if err := queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking, working)); err != nil {
+			return err
+		}
+		first := a2a.NewArtifactEvent(reqCtx, a2a.TextPart{Text: "chunk one"})
+		if err := queue.Write(ctx, first); err != nil {
+			return err
+		}
+		second := a2a.NewArtifactUpdateEvent(reqCtx, first.Artifact.ID, a2a.TextPart{Text: "chunk two"})
+		second.LastChunk = true
+		if err := queue.Write(ctx, second); err != nil {
+			return err
+		}
+		done := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+		done.Final = true
+		return queue.Write(ctx, done)
+	case strings.HasPrefix(text, "drip"):
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+		for range 4 {
+			time.Sleep(50 * time.Millisecond)
+			if err := queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking, nil)); err != nil {
+				return err
+			}
+		}
+		done := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+		done.Final = true
+		return queue.Write(ctx, done)
+	case strings.HasPrefix(text, "ask"):
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+		question := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: "which region?"})
+		waiting := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateInputRequired, question)
+		waiting.Final = true
+		return queue.Write(ctx, waiting)
+	case strings.HasPrefix(text, "fail"):
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+		msg := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: "cannot do that"})
+		failed := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateFailed, msg)
+		failed.Final = true
+		return queue.Write(ctx, failed)
+	default:
+		return queue.Write(ctx, a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "echo: " + text}))
+	}
+}
+
+func (fakeExecutor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	canceled := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCanceled, nil)
+	canceled.Final = true
+	return queue.Write(ctx, canceled)
+}
+
+// newFakeAgent starts a fake A2A agent and returns its card.
+func newFakeAgent(t *testing.T) a2a.AgentCard {
+	t.Helper()
+	srv := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(fakeExecutor{})))
+	t.Cleanup(srv.Close)
+	return a2a.AgentCard{Name: "Fake Agent", URL: srv.URL}
+}
+
+func TestDelegator_Send(t *testing.T) {
+	t.Parallel()
+	card := newFakeAgent(t)
+	delegator := NewDelegator(WithTimeout(200 * time.Millisecond))
+
+	unreachable := a2a.AgentCard{Name: "Gone", URL: "http://127.0.0.1:1"}
+	grpcOnly := a2a.AgentCard{Name: "gRPC", URL: card.URL, PreferredTransport: a2a.TransportProtocolGRPC}
+
+	tests := []struct {
+		name         string
+		card         a2a.AgentCard
+		text         string
+		wantState    a2a.TaskState
+		wantReply    string
+		wantTask     bool
+		wantError    bool
+		wantTimedOut bool
+	}{
+		{name: "message reply", card: card, text: "hello", wantState: a2a.TaskStateCompleted, wantReply: "echo: hello"},
+		{name: "completed task", card: card, text: "task summary", wantState: a2a.TaskStateCompleted, wantReply: "report for task summary", wantTask: true},
+		{name: "failed task", card: card, text: "fail now", wantState: a2a.TaskStateFailed, wantReply: "cannot do that", wantTask: true},
+		{name: "timeout", card: card, text: "slow", wantState: a2a.TaskStateFailed, wantError: true, wantTimedOut: true},
+		{name: "unreachable agent", card: unreachable, text: "hello", wantState: a2a.TaskStateFailed, wantError: true},
+		{name: "unsupported transport", card: grpcOnly, text: "hello", wantState: a2a.TaskStateFailed, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := delegator.Send(context.Background(), Target{AgentID: "agent", Card: tt.card}, tt.text)
+
+			if got.State != tt.wantState {
+				t.Errorf("State = %q, want %q (error %q)", got.State, tt.wantState, got.Error)
+			}
+			if got.Reply != tt.wantReply {
+				t.Errorf("Reply = %q, want %q", got.Reply, tt.wantReply)
+			}
+			if (got.TaskID != "") != tt.wantTask {
+				t.Errorf("TaskID = %q, want task %v", got.TaskID, tt.wantTask)
+			}
+			if (got.Error != "") != tt.wantError {
+				t.Errorf("Error = %q, want error %v", got.Error, tt.wantError)
+			}
+			if got.TimedOut != tt.wantTimedOut {
+				t.Errorf("TimedOut = %v, want %v", got.TimedOut, tt.wantTimedOut)
+			}
+		})
+	}
+}
+
+func TestDelegator_Send_CanceledParent(t *testing.T) {
+	t.Parallel()
+	card := newFakeAgent(t)
+	delegator := NewDelegator(WithTimeout(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got := delegator.Send(ctx, Target{AgentID: "agent", Card: card}, "hello")
+	if got.State != a2a.TaskStateCanceled || got.TimedOut {
+		t.Errorf("Send() = %+v, want canceled without timeout", got)
+	}
+}
+
+// peakExecutor is a downstream agent that records how many calls it
+// serves at once.
+type peakExecutor struct {
+	// active is the number of calls in progress.
+	active atomic.Int32
+	// peak is the highest value of active.
+	peak atomic.Int32
+}
+
+func (e *peakExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	n := e.active.Add(1)
+	defer e.active.Add(-1)
+	for {
+		p := e.peak.Load()
+		if n <= p || e.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return queue.Write(ctx, a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "done"}))
+}
+
+func (e *peakExecutor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	return nil
+}
+
+func TestDelegator_Broadcast(t *testing.T) {
+	t.Parallel()
+	card := newFakeAgent(t)
+	unreachable := a2a.AgentCard{Name: "Gone", URL: "http://127.0.0.1:1"}
+
+	t.Run("aggregates successes and failures", func(t *testing.T) {
+		t.Parallel()
+		delegator := NewDelegator(WithTimeout(time.Second))
+		targets := []Target{
+			{AgentID: "echo", Card: card},
+			{AgentID: "gone", Card: unreachable},
+			{AgentID: "echo-2", Card: card},
+		}
+
+		got := delegator.Broadcast(context.Background(), targets, "hello")
+
+		if got.Succeeded != 2 || got.Failed != 1 {
+			t.Errorf("Succeeded, Failed = %d, %d, want 2, 1", got.Succeeded, got.Failed)
+		}
+		for i, r := range got.Results {
+			if r.AgentID != targets[i].AgentID {
+				t.Errorf("Results[%d].AgentID = %q, want %q", i, r.AgentID, targets[i].AgentID)
+			}
+		}
+		if got.Results[0].Reply != "echo: hello" || got.Results[1].Error == "" {
+			t.Errorf("Results = %+v", got.Results)
+		}
+	})
+
+	t.Run("unfinished tasks are pending", func(t *testing.T) {
+		t.Parallel()
+		delegator := NewDelegator(WithTimeout(time.Second))
+		targets := []Target{{AgentID: "ask", Card: card}, {AgentID: "ask-2", Card: card}}
+
+		got := delegator.Broadcast(context.Background(), targets, "ask for details")
+
+		if got.Succeeded != 0 || got.Pending != 2 || got.Failed != 0 {
+			t.Errorf("Succeeded, Pending, Failed = %d, %d, %d, want 0, 2, 0: %+v",
+				got.Succeeded, got.Pending, got.Failed, got.Results)
+		}
+	})
+
+	t.Run("global deadline stops slow agents", func(t *testing.T) {
+		t.Parallel()
+		delegator := NewDelegator(WithTimeout(time.Minute), WithBroadcastDeadline(100*time.Millisecond))
+		targets := []Target{{AgentID: "slow", Card: card}, {AgentID: "slow-2", Card: card}}
+
+		start := time.Now()
+		got := delegator.Broadcast(context.Background(), targets, "slow")
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("Broadcast() took %s, want it bounded by the deadline", elapsed)
+		}
+		if !got.Results[0].TimedOut || got.Failed != 2 {
+			t.Errorf("Results = %+v, want both agents timed out", got.Results)
+		}
+	})
+
+	t.Run("caps concurrency", func(t *testing.T) {
+		t.Parallel()
+		exec := &peakExecutor{}
+		srv := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(exec)))
+		t.Cleanup(srv.Close)
+		peakCard := a2a.AgentCard{Name: "Peak", URL: srv.URL}
+
+		delegator := NewDelegator(WithMaxConcurrency(2))
+		targets := make([]Target, 6)
+		for i := range targets {
+			targets[i] = Target{AgentID: "peak", Card: peakCard}
+		}
+
+		got := delegator.Broadcast(context.Background(), targets, "hello")
+		if got.Succeeded != len(targets) {
+			t.Fatalf("Succeeded = %d, want %d: %+v", got.Succeeded, len(targets), got.Results)
+		}
+		if peak := exec.peak.Load(); peak > 2 {
+			t.Errorf("peak concurrency = %d, want at most 2", peak)
+		}
+	})
+
+	t.Run("targets are capped at two waves", func(t *testing.T) {
+		t.Parallel()
+		if got := NewDelegator(WithMaxConcurrency(3)).MaxBroadcastTargets(); got != 6 {
+			t.Errorf("MaxBroadcastTargets() = %d, want 6", got)
+		}
+	})
+
+	t.Run("cancellation stops pending calls", func(t *testing.T) {
+		t.Parallel()
+		delegator := NewDelegator(WithTimeout(time.Minute), WithBroadcastDeadline(time.Minute))
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		got := delegator.Broadcast(ctx, []Target{{AgentID: "slow", Card: card}}, "slow")
+		if got.Results[0].State != a2a.TaskStateCanceled {
+			t.Errorf("State = %q, want canceled", got.Results[0].State)
+		}
+	})
+}
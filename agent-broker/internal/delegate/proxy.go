@@ -0,0 +1,219 @@
+package delegate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+// DownstreamMetadataKey is the task metadata key listing the downstream
+// tasks a broker task delegated to.
+const DownstreamMetadataKey = "lunarr/downstream"
+
+// DownstreamTask is the current state of a task delegated to another agent.
+type DownstreamTask struct {
+	// AgentID is the registry ID of the downstream agent.
+	AgentID string `json:"agent_id"`
+	// TaskID is the downstream task ID.
+	TaskID a2a.TaskID `json:"task_id"`
+	// State is the downstream task state, empty if it could not be fetched.
+	State a2a.TaskState `json:"state,omitempty"`
+	// Reply is the text of the downstream task's result.
+	Reply string `json:"reply,omitempty"`
+	// Error describes why the downstream call failed.
+	Error string `json:"error,omitempty"`
+}
+
+// TracksTasks reports whether d records downstream tasks in a task store.
+func (d *Delegator) TracksTasks() bool {
+	return d.tasks != nil
+}
+
+// Links returns the downstream tasks recorded for a broker task.
+func (d *Delegator) Links(ctx context.Context, brokerTaskID a2a.TaskID) ([]TaskLink, error) {
+	if d.tasks == nil {
+		return nil, nil
+	}
+	links, err := d.tasks.Links(ctx, brokerTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("load task links: %w", err)
+	}
+	return links, nil
+}
+
+// GetTasks fetches the current state of each linked downstream task.
+func (d *Delegator) GetTasks(ctx context.Context, links []TaskLink) []DownstreamTask {
+	return d.eachLink(ctx, links, func(ctx context.Context, link TaskLink) (*a2a.Task, error) {
+		client, err := d.newClient(ctx, link.card())
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = client.Destroy() }()
+		return client.GetTask(ctx, &a2a.TaskQueryParams{ID: link.TaskID})
+	})
+}
+
+// CancelTasks asks each linked downstream agent to cancel its task.
+func (d *Delegator) CancelTasks(ctx context.Context, links []TaskLink) []DownstreamTask {
+	return d.eachLink(ctx, links, func(ctx context.Context, link TaskLink) (*a2a.Task, error) {
+		client, err := d.newClient(ctx, link.card())
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = client.Destroy() }()
+		return client.CancelTask(ctx, &a2a.TaskIDParams{ID: link.TaskID})
+	})
+}
+
+// eachLink calls fn for every link in parallel, at most MaxConcurrency at a
+// time and each with the per-call timeout, and collects the resulting task
+// states in link order.
+func (d *Delegator) eachLink(ctx context.Context, links []TaskLink, fn func(context.Context, TaskLink) (*a2a.Task, error)) []DownstreamTask {
+	tasks := make([]DownstreamTask, len(links))
+	sem := make(chan struct{}, d.concurrency)
+	var wg sync.WaitGroup
+	for i, link := range links {
+		tasks[i] = DownstreamTask{AgentID: link.AgentID, TaskID: link.TaskID}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				tasks[i].Error = ctx.Err().Error()
+				return
+			}
+
+			callCtx, cancel := context.WithTimeout(ctx, d.timeout)
+			defer cancel()
+			task, err := fn(callCtx, link)
+			if err != nil {
+				tasks[i].Error = err.Error()
+				return
+			}
+			tasks[i].State = task.Status.State
+			tasks[i].Reply = taskText(task)
+		}()
+	}
+	wg.Wait()
+	return tasks
+}
+
+// TaskHandler wraps the broker's request handler so that tasks/get reports
+// the state of delegated downstream tasks and tasks/cancel propagates to
+// them. Broker tasks lost on restart are restored from their recorded
+// links.
+type TaskHandler struct {
+	a2asrv.RequestHandler
+	// delegator reaches the downstream agents.
+	delegator *Delegator
+}
+
+var _ a2asrv.RequestHandler = (*TaskHandler)(nil)
+
+// NewTaskHandler wraps next. delegator must have a task store.
+func NewTaskHandler(next a2asrv.RequestHandler, delegator *Delegator) *TaskHandler {
+	return &TaskHandler{RequestHandler: next, delegator: delegator}
+}
+
+// OnGetTask returns the broker task with the state of its downstream tasks.
+func (h *TaskHandler) OnGetTask(ctx context.Context, query *a2a.TaskQueryParams) (*a2a.Task, error) {
+	task, err := h.RequestHandler.OnGetTask(ctx, query)
+	if err != nil && !errors.Is(err, a2a.ErrTaskNotFound) {
+		return nil, err
+	}
+
+	links, linkErr := h.delegator.Links(ctx, query.ID)
+	if linkErr != nil || len(links) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return task, nil
+	}
+
+	downstream := h.delegator.GetTasks(ctx, links)
+	if task == nil {
+		task = restoredTask(query.ID, links, downstream, aggregateState(downstream))
+	}
+	return withDownstream(task, downstream), nil
+}
+
+// OnCancelTask cancels the downstream tasks, then the broker task. When
+// the links cannot be loaded, only the broker task is cancelled.
+func (h *TaskHandler) OnCancelTask(ctx context.Context, params *a2a.TaskIDParams) (*a2a.Task, error) {
+	links, linkErr := h.delegator.Links(ctx, params.ID)
+	if linkErr != nil {
+		h.delegator.logger.WarnContext(ctx, "failed to load downstream tasks to cancel",
+			"broker_task_id", params.ID, "error", linkErr)
+	}
+	downstream := h.delegator.CancelTasks(ctx, links)
+
+	task, err := h.RequestHandler.OnCancelTask(ctx, params)
+	if errors.Is(err, a2a.ErrTaskNotFound) && len(links) > 0 {
+		task, err = restoredTask(params.ID, links, downstream, a2a.TaskStateCanceled), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(downstream) == 0 {
+		return task, nil
+	}
+	return withDownstream(task, downstream), nil
+}
+
+// restoredTask rebuilds a broker task that is no longer held in memory from
+// its links and the state of its downstream tasks.
+func restoredTask(id a2a.TaskID, links []TaskLink, downstream []DownstreamTask, state a2a.TaskState) *a2a.Task {
+	task := &a2a.Task{
+		ID:        id,
+		ContextID: links[0].BrokerContextID,
+		Status:    a2a.TaskStatus{State: state},
+	}
+	var replies []string
+	for _, t := range downstream {
+		if t.Reply != "" {
+			replies = append(replies, t.Reply)
+		}
+	}
+	if len(replies) > 0 {
+		task.Status.Message = a2a.NewMessageForTask(a2a.MessageRoleAgent, task, a2a.TextPart{Text: strings.Join(replies, "\n")})
+	}
+	return task
+}
+
+// aggregateState summarizes downstream states: working while any task is
+// unfinished, failed when every task failed, completed otherwise.
+func aggregateState(downstream []DownstreamTask) a2a.TaskState {
+	failed := 0
+	for _, t := range downstream {
+		switch {
+		case t.State == "" || t.State == a2a.TaskStateFailed || t.State == a2a.TaskStateRejected || t.State == a2a.TaskStateCanceled:
+			failed++
+		case !t.State.Terminal():
+			return a2a.TaskStateWorking
+		}
+	}
+	if failed == len(downstream) {
+		return a2a.TaskStateFailed
+	}
+	return a2a.TaskStateCompleted
+}
+
+// withDownstream returns a copy of task carrying the downstream states in
+// its metadata.
+func withDownstream(task *a2a.Task, downstream []DownstreamTask) *a2a.Task {
+	out := *task
+	out.Metadata = maps.Clone(task.Metadata)
+	if out.Metadata == nil {
+		out.Metadata = make(map[string]any)
+	}
+	out.Metadata[DownstreamMetadataKey] = downstream
+	return &out
+}
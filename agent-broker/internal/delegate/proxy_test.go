@@ -0,0 +1,191 @@
+package delegate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+// newTaskHandler builds a broker request handler that delegates to target
+// and tracks downstream tasks in store.
+func newTaskHandler(store TaskStore, target Target) *TaskHandler {
+	delegator := NewDelegator(WithTimeout(5*time.Second), WithTaskStore(store))
+	exec := NewRelayExecutor(&brokerExecutor{delegator: delegator, target: target})
+	return NewTaskHandler(a2asrv.NewHandler(exec), delegator)
+}
+
+// downstreamOf returns the downstream tasks recorded in task metadata.
+func downstreamOf(t *testing.T, task *a2a.Task) []DownstreamTask {
+	t.Helper()
+	downstream, ok := task.Metadata[DownstreamMetadataKey].([]DownstreamTask)
+	if !ok {
+		t.Fatalf("task metadata = %v, want downstream tasks", task.Metadata)
+	}
+	return downstream
+}
+
+func TestTaskHandler_GetAndCancel(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := NewFileTaskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileTaskStore() error = %v", err)
+	}
+	target := Target{AgentID: "asker", Card: newFakeAgent(t)}
+
+	broker := newTaskHandler(store, target)
+	resp, err := broker.OnSendMessage(ctx, &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "ask me"}),
+	})
+	if err != nil {
+		t.Fatalf("OnSendMessage() error = %v", err)
+	}
+	brokerTask, ok := resp.(*a2a.Task)
+	if !ok {
+		t.Fatalf("OnSendMessage() = %T, want a task", resp)
+	}
+
+	t.Run("get reports downstream state", func(t *testing.T) {
+		task, err := broker.OnGetTask(ctx, &a2a.TaskQueryParams{ID: brokerTask.ID})
+		if err != nil {
+			t.Fatalf("OnGetTask() error = %v", err)
+		}
+		downstream := downstreamOf(t, task)
+		if len(downstream) != 1 || downstream[0].AgentID != "asker" || downstream[0].State != a2a.TaskStateInputRequired {
+			t.Errorf("downstream = %+v, want asker input-required", downstream)
+		}
+	})
+
+	// A new handler over the same store stands in for a restarted broker.
+	restarted := newTaskHandler(store, target)
+
+	t.Run("get after restart restores the task", func(t *testing.T) {
+		task, err := restarted.OnGetTask(ctx, &a2a.TaskQueryParams{ID: brokerTask.ID})
+		if err != nil {
+			t.Fatalf("OnGetTask() error = %v", err)
+		}
+		if task.ID != brokerTask.ID || task.ContextID != brokerTask.ContextID {
+			t.Errorf("restored task = %s/%s, want %s/%s", task.ID, task.ContextID, brokerTask.ID, brokerTask.ContextID)
+		}
+		if task.Status.State != a2a.TaskStateWorking {
+			t.Errorf("restored state = %q, want working while downstream waits", task.Status.State)
+		}
+	})
+
+	t.Run("cancel propagates downstream", func(t *testing.T) {
+		task, err := restarted.OnCancelTask(ctx, &a2a.TaskIDParams{ID: brokerTask.ID})
+		if err != nil {
+			t.Fatalf("OnCancelTask() error = %v", err)
+		}
+		if task.Status.State != a2a.TaskStateCanceled {
+			t.Errorf("State = %q, want canceled", task.Status.State)
+		}
+		if downstream := downstreamOf(t, task); downstream[0].State != a2a.TaskStateCanceled {
+			t.Errorf("downstream = %+v, want canceled", downstream)
+		}
+	})
+
+	t.Run("unknown task is not found", func(t *testing.T) {
+		_, err := restarted.OnGetTask(ctx, &a2a.TaskQueryParams{ID: "missing"})
+		if !errors.Is(err, a2a.ErrTaskNotFound) {
+			t.Errorf("OnGetTask() error = %v, want ErrTaskNotFound", err)
+		}
+	})
+}
+
+func TestTaskHandler_CancelRunningTask(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	broker := newTaskHandler(NewMemoryTaskStore(), Target{AgentID: "slow", Card: newFakeAgent(t)})
+
+	blocking := false
+	resp, err := broker.OnSendMessage(ctx, &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "slow"}),
+		Config:  &a2a.MessageSendConfig{Blocking: &blocking},
+	})
+	if err != nil {
+		t.Fatalf("OnSendMessage() error = %v", err)
+	}
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	task, err := broker.OnCancelTask(cancelCtx, &a2a.TaskIDParams{ID: resp.TaskInfo().TaskID})
+	if err != nil {
+		t.Fatalf("OnCancelTask() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("State = %q, want canceled", task.Status.State)
+	}
+}
+
+// brokenTaskStore fails to load links.
+type brokenTaskStore struct {
+	*MemoryTaskStore
+}
+
+func (brokenTaskStore) Links(context.Context, a2a.TaskID) ([]TaskLink, error) {
+	return nil, errors.New("disk unavailable")
+}
+
+func TestTaskHandler_CancelWithBrokenStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	broker := newTaskHandler(brokenTaskStore{NewMemoryTaskStore()}, Target{AgentID: "slow", Card: newFakeAgent(t)})
+
+	blocking := false
+	resp, err := broker.OnSendMessage(ctx, &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "slow"}),
+		Config:  &a2a.MessageSendConfig{Blocking: &blocking},
+	})
+	if err != nil {
+		t.Fatalf("OnSendMessage() error = %v", err)
+	}
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	task, err := broker.OnCancelTask(cancelCtx, &a2a.TaskIDParams{ID: resp.TaskInfo().TaskID})
+	if err != nil {
+		t.Fatalf("OnCancelTask() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("State = %q, want canceled", task.Status.State)
+	}
+}
+
+func TestDelegator_EachLink(t *testing.T) {
+	t.Parallel()
+	delegator := NewDelegator(WithMaxConcurrency(2))
+	links := make([]TaskLink, 6)
+	for i := range links {
+		links[i] = TaskLink{AgentID: "agent", TaskID: a2a.TaskID(fmt.Sprint(i))}
+	}
+
+	var active, peak atomic.Int32
+	got := delegator.eachLink(context.Background(), links, func(ctx context.Context, link TaskLink) (*a2a.Task, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return &a2a.Task{ID: link.TaskID, Status: a2a.TaskStatus{State: a2a.TaskStateCompleted}}, nil
+	})
+
+	for i, task := range got {
+		if task.TaskID != links[i].TaskID || task.State != a2a.TaskStateCompleted {
+			t.Errorf("eachLink()[%d] = %+v, want completed task %q", i, task, links[i].TaskID)
+		}
+	}
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+}
@@ -0,0 +1,214 @@
+package delegate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// OriginMetadataKey is the metadata key under which relayed events record
+// the downstream agent and task they came from.
+const OriginMetadataKey = "lunarr/origin"
+
+// Relay republishes events from downstream agents as events of the
+// broker's own task.
+type Relay struct {
+	// mu serializes writes from concurrent downstream streams.
+	mu sync.Mutex
+	// queue is the broker task's event queue.
+	queue eventqueue.Queue
+	// task identifies the broker task.
+	task a2a.TaskInfoProvider
+}
+
+// relayKey is the context key for the request's Relay.
+type relayKey struct{}
+
+// ContextWithRelay returns a context carrying relay.
+func ContextWithRelay(ctx context.Context, relay *Relay) context.Context {
+	return context.WithValue(ctx, relayKey{}, relay)
+}
+
+// RelayFromContext returns the context's Relay, or nil.
+func RelayFromContext(ctx context.Context) *Relay {
+	relay, _ := ctx.Value(relayKey{}).(*Relay)
+	return relay
+}
+
+// RelayExecutor wraps an executor so that delegated calls made while it
+// runs can stream their events into the broker task.
+type RelayExecutor struct {
+	// next is the wrapped executor.
+	next a2asrv.AgentExecutor
+}
+
+var _ a2asrv.AgentExecutor = (*RelayExecutor)(nil)
+
+// NewRelayExecutor wraps next with a Relay for each execution.
+func NewRelayExecutor(next a2asrv.AgentExecutor) *RelayExecutor {
+	return &RelayExecutor{next: next}
+}
+
+// Execute runs the wrapped executor with a Relay in its context.
+func (e *RelayExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	relay := &Relay{queue: queue, task: reqCtx}
+	return e.next.Execute(ContextWithRelay(ctx, relay), reqCtx, queue)
+}
+
+// Cancel delegates to the wrapped executor. Terminal status updates it
+// writes are marked final, which a2asrv needs to complete the cancel
+// request.
+func (e *RelayExecutor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	return e.next.Cancel(ctx, reqCtx, finalQueue{Queue: queue})
+}
+
+// finalQueue marks terminal status updates as final.
+type finalQueue struct {
+	eventqueue.Queue
+}
+
+// Write enqueues event, marking it final when it ends the task.
+func (q finalQueue) Write(ctx context.Context, event a2a.Event) error {
+	if update, ok := event.(*a2a.TaskStatusUpdateEvent); ok && update.Status.State.Terminal() {
+		update.Final = true
+	}
+	return q.Queue.Write(ctx, event)
+}
+
+// publish writes a downstream event to the broker task. Status changes and
+// messages become working updates, since only the broker decides when its
+// own task ends; artifacts are relayed as chunks of the broker task.
+func (r *Relay) publish(ctx context.Context, target Target, event a2a.Event) error {
+	origin := map[string]any{
+		"agent_id":   target.AgentID,
+		"agent_name": target.Card.Name,
+	}
+	if info := event.TaskInfo(); info.TaskID != "" {
+		origin["task_id"] = string(info.TaskID)
+	}
+
+	var out a2a.Event
+	switch e := event.(type) {
+	case *a2a.Task:
+		origin["state"] = string(e.Status.State)
+		out = r.statusUpdate(e.Status.Message, origin)
+	case *a2a.TaskStatusUpdateEvent:
+		origin["state"] = string(e.Status.State)
+		out = r.statusUpdate(e.Status.Message, origin)
+	case *a2a.Message:
+		out = r.statusUpdate(e, origin)
+	case *a2a.TaskArtifactUpdateEvent:
+		info := r.task.TaskInfo()
+		out = &a2a.TaskArtifactUpdateEvent{
+			TaskID:    info.TaskID,
+			ContextID: info.ContextID,
+			Artifact:  e.Artifact,
+			Append:    e.Append,
+			LastChunk: e.LastChunk,
+			Metadata:  map[string]any{OriginMetadataKey: origin},
+		}
+	default:
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.queue.Write(ctx, out); err != nil {
+		return fmt.Errorf("relay event: %w", err)
+	}
+	return nil
+}
+
+// statusUpdate builds a working update of the broker task carrying msg.
+func (r *Relay) statusUpdate(msg *a2a.Message, origin map[string]any) *a2a.TaskStatusUpdateEvent {
+	var relayed *a2a.Message
+	if msg != nil {
+		relayed = a2a.NewMessageForTask(a2a.MessageRoleAgent, r.task, msg.Parts...)
+	}
+	event := a2a.NewStatusUpdateEvent(r.task, a2a.TaskStateWorking, relayed)
+	event.Metadata = map[string]any{OriginMetadataKey: origin}
+	return event
+}
+
+// sendStream sends params over a streaming call, relays every event and
+// folds the stream into a Result. The call is cancelled when the agent
+// sends no event for the stream idle timeout.
+func (d *Delegator) sendStream(parent context.Context, relay *Relay, target Target, params *a2a.MessageSendParams) *Result {
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	idle := time.AfterFunc(d.streamIdle, func() { cancel(errStreamIdle) })
+	defer idle.Stop()
+
+	client, err := d.newClient(ctx, target.Card)
+	if err != nil {
+		return d.failure(parent, ctx, err)
+	}
+	defer func() { _ = client.Destroy() }()
+
+	task := &a2a.Task{}
+	for event, err := range client.SendStreamingMessage(ctx, params) {
+		if err != nil {
+			return d.failure(parent, ctx, fmt.Errorf("stream message: %w", err))
+		}
+		idle.Reset(d.streamIdle)
+		if err := relay.publish(ctx, target, event); err != nil {
+			return d.failure(parent, ctx, err)
+		}
+		if task.ID == "" && event.TaskInfo().TaskID != "" {
+			// Link as soon as the task exists so a cancel mid-stream reaches it.
+			d.link(ctx, target, event)
+		}
+
+		switch e := event.(type) {
+		case *a2a.Message:
+			return &Result{
+				State:     a2a.TaskStateCompleted,
+				ContextID: e.ContextID,
+				Reply:     partsText(e.Parts),
+			}
+		case *a2a.Task:
+			task = e
+		case *a2a.TaskStatusUpdateEvent:
+			task.ID, task.ContextID = e.TaskID, e.ContextID
+			task.Status = e.Status
+		case *a2a.TaskArtifactUpdateEvent:
+			task.ID, task.ContextID = e.TaskID, e.ContextID
+			applyArtifact(task, e)
+		}
+	}
+
+	if task.Status.State == "" {
+		// Without a message or task status the agent gave no answer, not
+		// one that is still pending.
+		return d.failure(parent, ctx, errEmptyStream)
+	}
+	return &Result{
+		State:     task.Status.State,
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		Reply:     taskText(task),
+	}
+}
+
+// applyArtifact adds an artifact update to task, appending chunks to the
+// artifact they continue.
+func applyArtifact(task *a2a.Task, event *a2a.TaskArtifactUpdateEvent) {
+	if event.Artifact == nil {
+		return
+	}
+	if event.Append {
+		for _, artifact := range task.Artifacts {
+			if artifact.ID == event.Artifact.ID {
+				artifact.Parts = append(artifact.Parts, event.Artifact.Parts...)
+				return
+			}
+		}
+	}
+	artifact := *event.Artifact
+	task.Artifacts = append(task.Artifacts, &artifact)
+}
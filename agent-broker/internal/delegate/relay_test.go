@@ -0,0 +1,189 @@
+package delegate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// brokerExecutor stands in for the broker agent: it delegates every
+// message to target and completes its own task with the reply.
+type brokerExecutor struct {
+	// delegator sends the delegated call.
+	delegator *Delegator
+	// target is the downstream agent.
+	target Target
+}
+
+func (e *brokerExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+		return err
+	}
+	result := e.delegator.Send(ctx, e.target, partsText(reqCtx.Message.Parts))
+	reply := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: result.Reply})
+	done := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, reply)
+	done.Final = true
+	return queue.Write(ctx, done)
+}
+
+func (e *brokerExecutor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	return queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCanceled, nil))
+}
+
+// newBroker starts a broker that relays through a RelayExecutor and
+// returns a client for it.
+func newBroker(t *testing.T, target Target) *a2aclient.Client {
+	t.Helper()
+	exec := NewRelayExecutor(&brokerExecutor{delegator: NewDelegator(WithTimeout(5 * time.Second)), target: target})
+	srv := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(exec)))
+	t.Cleanup(srv.Close)
+
+	card := &a2a.AgentCard{
+		URL:                srv.URL,
+		PreferredTransport: a2a.TransportProtocolJSONRPC,
+		Capabilities:       a2a.AgentCapabilities{Streaming: true},
+	}
+	client, err := a2aclient.NewFromCard(context.Background(), card)
+	if err != nil {
+		t.Fatalf("NewFromCard() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Destroy() })
+	return client
+}
+
+// origin returns the relay origin metadata of an event, or nil.
+func origin(event a2a.Event) map[string]any {
+	o, _ := event.Meta()[OriginMetadataKey].(map[string]any)
+	return o
+}
+
+func TestRelayExecutor_StreamsDownstreamEvents(t *testing.T) {
+	t.Parallel()
+	card := newFakeAgent(t)
+	card.Capabilities.Streaming = true
+	client := newBroker(t, Target{AgentID: "streamer", Card: card})
+
+	params := &a2a.MessageSendParams{Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "stream it"})}
+	var events []a2a.Event
+	for event, err := range client.SendStreamingMessage(context.Background(), params) {
+		if err != nil {
+			t.Fatalf("SendStreamingMessage() error = %v", err)
+		}
+		events = append(events, event)
+	}
+
+	var brokerTask a2a.TaskID
+	var relayedStatus, relayedChunks int
+	for _, event := range events {
+		if task, ok := event.(*a2a.Task); ok {
+			brokerTask = task.ID
+		}
+		o := origin(event)
+		if o == nil {
+			continue
+		}
+		if o["agent_id"] != "streamer" || o["task_id"] == "" {
+			t.Errorf("origin = %v, want agent streamer with a downstream task", o)
+		}
+		if event.TaskInfo().TaskID != brokerTask {
+			t.Errorf("relayed event task = %q, want broker task %q", event.TaskInfo().TaskID, brokerTask)
+		}
+		switch e := event.(type) {
+		case *a2a.TaskStatusUpdateEvent:
+			relayedStatus++
+			if e.Status.State != a2a.TaskStateWorking || e.Final {
+				t.Errorf("relayed status = %q final=%v, want a non-final working update", e.Status.State, e.Final)
+			}
+		case *a2a.TaskArtifactUpdateEvent:
+			relayedChunks++
+		}
+	}
+	if relayedStatus == 0 || relayedChunks != 2 {
+		t.Errorf("relayed %d status updates and %d artifact chunks, want some and 2", relayedStatus, relayedChunks)
+	}
+
+	final, ok := events[len(events)-1].(*a2a.TaskStatusUpdateEvent)
+	if !ok || final.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("last event = %#v, want completed status", events[len(events)-1])
+	}
+	if got := partsText(final.Status.Message.Parts); got != "chunk one\nchunk two" {
+		t.Errorf("broker reply = %q, want the joined artifact chunks", got)
+	}
+}
+
+func TestRelayExecutor_NonStreamingAgent(t *testing.T) {
+	t.Parallel()
+	client := newBroker(t, Target{AgentID: "plain", Card: newFakeAgent(t)})
+
+	params := &a2a.MessageSendParams{Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "stream it"})}
+	for event, err := range client.SendStreamingMessage(context.Background(), params) {
+		if err != nil {
+			t.Fatalf("SendStreamingMessage() error = %v", err)
+		}
+		if o := origin(event); o != nil {
+			t.Errorf("got relayed event %v from an agent without streaming", o)
+		}
+	}
+}
+
+// discardQueue is a broker task queue that drops relayed events.
+type discardQueue struct {
+	eventqueue.Queue
+}
+
+func (discardQueue) Write(context.Context, a2a.Event) error { return nil }
+
+func TestDelegator_Send_Stream(t *testing.T) {
+	t.Parallel()
+	card := newFakeAgent(t)
+	card.Capabilities.Streaming = true
+	reqCtx := &a2asrv.RequestContext{TaskID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
+	ctx := ContextWithRelay(context.Background(), &Relay{queue: discardQueue{}, task: reqCtx})
+	// empty answers every call with an event stream that ends at once.
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	t.Cleanup(empty.Close)
+	emptyCard := a2a.AgentCard{Name: "Empty", URL: empty.URL, Capabilities: a2a.AgentCapabilities{Streaming: true}}
+
+	tests := []struct {
+		name      string
+		card      *a2a.AgentCard
+		text      string
+		wantState a2a.TaskState
+		wantError string
+	}{
+		{name: "active stream outlives the call timeout", text: "drip", wantState: a2a.TaskStateCompleted},
+		{name: "idle stream times out", text: "slow", wantState: a2a.TaskStateFailed, wantError: "sent no event for 150ms"},
+		{name: "empty stream fails", card: &emptyCard, text: "hello", wantState: a2a.TaskStateFailed, wantError: "ended without a response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			delegator := NewDelegator(WithTimeout(100*time.Millisecond), WithStreamIdleTimeout(150*time.Millisecond))
+
+			target := Target{AgentID: "streamer", Card: card}
+			if tt.card != nil {
+				target.Card = *tt.card
+			}
+
+			got := delegator.Send(ctx, target, tt.text)
+
+			if got.State != tt.wantState || !strings.Contains(got.Error, tt.wantError) {
+				t.Errorf("Send() = %+v, want state %q and error containing %q", got, tt.wantState, tt.wantError)
+			}
+			if strings.Contains(tt.wantError, "no event") && !got.TimedOut {
+				t.Errorf("Send() TimedOut = false, want true")
+			}
+		})
+	}
+}
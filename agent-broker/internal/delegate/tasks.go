@@ -0,0 +1,343 @@
+package delegate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/periodic"
+)
+
+// TaskLink maps a broker task to a task it delegated to a downstream agent.
+type TaskLink struct {
+	// BrokerTaskID is the broker's own task ID.
+	BrokerTaskID a2a.TaskID `json:"broker_task_id"`
+	// BrokerContextID is the broker task's context ID.
+	BrokerContextID string `json:"broker_context_id,omitempty"`
+	// AgentID is the registry ID of the downstream agent.
+	AgentID string `json:"agent_id"`
+	// AgentURL is the downstream agent's endpoint.
+	AgentURL string `json:"agent_url"`
+	// Transport is the downstream agent's preferred transport.
+	Transport a2a.TransportProtocol `json:"transport,omitempty"`
+	// TaskID is the downstream task ID.
+	TaskID a2a.TaskID `json:"task_id"`
+	// ContextID is the downstream context ID.
+	ContextID string `json:"context_id,omitempty"`
+	// CreatedAt is when the link was recorded.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// card returns a minimal agent card for reaching the linked agent.
+func (l TaskLink) card() a2a.AgentCard {
+	return a2a.AgentCard{URL: l.AgentURL, PreferredTransport: l.Transport}
+}
+
+// TaskStore persists links from broker tasks to downstream tasks.
+type TaskStore interface {
+	// Save records link, replacing an earlier link to the same downstream
+	// task.
+	Save(ctx context.Context, link TaskLink) error
+	// Links returns the links of a broker task in the order they were
+	// first saved.
+	Links(ctx context.Context, brokerTaskID a2a.TaskID) ([]TaskLink, error)
+	// Prune deletes the links of broker tasks last saved before cutoff and
+	// returns how many broker tasks were pruned.
+	Prune(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// upsertLink replaces the link to the same downstream task or appends link.
+func upsertLink(links []TaskLink, link TaskLink) []TaskLink {
+	for i, l := range links {
+		if l.AgentURL == link.AgentURL && l.TaskID == link.TaskID {
+			link.CreatedAt = l.CreatedAt
+			links[i] = link
+			return links
+		}
+	}
+	return append(links, link)
+}
+
+// MemoryTaskStore keeps task links in memory.
+type MemoryTaskStore struct {
+	// mu protects links and saved.
+	mu sync.RWMutex
+	// links maps broker task IDs to their links.
+	links map[a2a.TaskID][]TaskLink
+	// saved maps broker task IDs to when a link was last saved.
+	saved map[a2a.TaskID]time.Time
+}
+
+var _ TaskStore = (*MemoryTaskStore)(nil)
+
+// NewMemoryTaskStore creates an empty MemoryTaskStore.
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{
+		links: make(map[a2a.TaskID][]TaskLink),
+		saved: make(map[a2a.TaskID]time.Time),
+	}
+}
+
+// Save records link.
+func (s *MemoryTaskStore) Save(_ context.Context, link TaskLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links[link.BrokerTaskID] = upsertLink(s.links[link.BrokerTaskID], link)
+	s.saved[link.BrokerTaskID] = time.Now()
+	return nil
+}
+
+// Links returns the links of a broker task.
+func (s *MemoryTaskStore) Links(_ context.Context, brokerTaskID a2a.TaskID) ([]TaskLink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]TaskLink(nil), s.links[brokerTaskID]...), nil
+}
+
+// Prune deletes the links of broker tasks last saved before cutoff.
+func (s *MemoryTaskStore) Prune(_ context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := 0
+	for id, saved := range s.saved {
+		if saved.Before(cutoff) {
+			delete(s.links, id)
+			delete(s.saved, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// FileTaskStore keeps task links as one JSON file per broker task, so they
+// survive restarts.
+type FileTaskStore struct {
+	// mu serializes read-modify-write cycles.
+	mu sync.Mutex
+	// dir is the directory holding the link files.
+	dir string
+}
+
+var _ TaskStore = (*FileTaskStore)(nil)
+
+// NewFileTaskStore creates a FileTaskStore rooted at dir, creating the
+// directory if needed.
+func NewFileTaskStore(dir string) (*FileTaskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create task store dir: %w", err)
+	}
+	return &FileTaskStore{dir: dir}, nil
+}
+
+// Save records link.
+func (s *FileTaskStore) Save(_ context.Context, link TaskLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links, err := s.read(link.BrokerTaskID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(upsertLink(links, link))
+	if err != nil {
+		return fmt.Errorf("encode task links: %w", err)
+	}
+
+	path := s.path(link.BrokerTaskID)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("create task links file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write task links: %w", err)
+	}
+	return nil
+}
+
+// Links returns the links of a broker task.
+func (s *FileTaskStore) Links(_ context.Context, brokerTaskID a2a.TaskID) ([]TaskLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(brokerTaskID)
+}
+
+// Prune deletes the link files last written before cutoff, along with
+// temporary files left behind by a Save that did not finish. Only link
+// files are counted.
+func (s *FileTaskStore) Prune(_ context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("list task links: %w", err)
+	}
+	pruned := 0
+	for _, entry := range entries {
+		temp := strings.Contains(entry.Name(), ".json.tmp")
+		if entry.IsDir() || !temp && !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return pruned, fmt.Errorf("stat task links: %w", err)
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return pruned, fmt.Errorf("delete task links: %w", err)
+		}
+		if !temp {
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// read loads the links of a broker task; a missing file means none.
+func (s *FileTaskStore) read(brokerTaskID a2a.TaskID) ([]TaskLink, error) {
+	data, err := os.ReadFile(s.path(brokerTaskID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read task links: %w", err)
+	}
+	var links []TaskLink
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("decode task links: %w", err)
+	}
+	return links, nil
+}
+
+// path returns the file for a broker task. IDs are hashed because they are
+// chosen by clients and may not be safe file names.
+func (s *FileTaskStore) path(brokerTaskID a2a.TaskID) string {
+	sum := sha256.Sum256([]byte(brokerTaskID))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// TaskPrunerOptions configures the TaskPruner.
+type TaskPrunerOptions struct {
+	// Retention is how long the links of a broker task are kept after they
+	// were last saved. Zero keeps them forever.
+	Retention time.Duration
+	// Interval is how often old links are looked for.
+	Interval time.Duration
+	// Logger reports pruning passes.
+	Logger *slog.Logger
+}
+
+// DefaultTaskPrunerOptions returns sensible defaults for pruner options.
+func DefaultTaskPrunerOptions() TaskPrunerOptions {
+	return TaskPrunerOptions{
+		Retention: 7 * 24 * time.Hour,
+		Interval:  10 * time.Minute,
+		Logger:    slog.Default(),
+	}
+}
+
+// TaskPrunerOption is a functional option for configuring TaskPruner.
+type TaskPrunerOption func(*TaskPrunerOptions)
+
+// WithRetention sets how long task links are kept.
+func WithRetention(d time.Duration) TaskPrunerOption {
+	return func(o *TaskPrunerOptions) {
+		o.Retention = d
+	}
+}
+
+// WithPruneInterval sets how often old task links are looked for.
+func WithPruneInterval(d time.Duration) TaskPrunerOption {
+	return func(o *TaskPrunerOptions) {
+		if d > 0 {
+			o.Interval = d
+		}
+	}
+}
+
+// WithPrunerLogger sets the pruner's logger.
+func WithPrunerLogger(logger *slog.Logger) TaskPrunerOption {
+	return func(o *TaskPrunerOptions) {
+		if logger != nil {
+			o.Logger = logger
+		}
+	}
+}
+
+// TaskPruner periodically deletes task links older than its retention, so
+// the store does not grow with every delegated task.
+type TaskPruner struct {
+	// store holds the links.
+	store TaskStore
+	// retention is how long links are kept.
+	retention time.Duration
+	// logger reports pruning passes.
+	logger *slog.Logger
+	// runner runs the pruning passes.
+	runner *periodic.Runner
+}
+
+// NewTaskPruner starts pruning store. With a zero retention it prunes
+// nothing.
+func NewTaskPruner(store TaskStore, opts ...TaskPrunerOption) *TaskPruner {
+	options := DefaultTaskPrunerOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	p := &TaskPruner{
+		store:     store,
+		retention: options.Retention,
+		logger:    options.Logger,
+	}
+	p.runner = periodic.Start(options.Interval, p.pass)
+	return p
+}
+
+// Prune deletes the links saved longer than the retention ago and returns
+// how many broker tasks were pruned.
+func (p *TaskPruner) Prune(ctx context.Context) (int, error) {
+	if p.retention <= 0 {
+		return 0, nil
+	}
+	return p.store.Prune(ctx, time.Now().Add(-p.retention))
+}
+
+// pass runs one pruning pass and logs its outcome.
+func (p *TaskPruner) pass(ctx context.Context) {
+	if n, err := p.Prune(ctx); err != nil {
+		p.logger.Warn("failed to prune task links", "error", err)
+	} else if n > 0 {
+		p.logger.Info("pruned task links", "broker_tasks", n)
+	}
+}
+
+// Close stops the pruning loop.
+func (p *TaskPruner) Close() {
+	p.runner.Close()
+}
@@ -0,0 +1,192 @@
+package delegate
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+func TestTaskStore_SaveAndLinks(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		store  func(t *testing.T) TaskStore
+		reopen func(t *testing.T) TaskStore
+	}{
+		{
+			name:  "memory",
+			store: func(t *testing.T) TaskStore { return NewMemoryTaskStore() },
+		},
+		{
+			name:   "file",
+			store:  func(t *testing.T) TaskStore { return mustFileTaskStore(t, dir) },
+			reopen: func(t *testing.T) TaskStore { return mustFileTaskStore(t, dir) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			store := tt.store(t)
+			created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			links := []TaskLink{
+				{BrokerTaskID: "broker/1", AgentID: "a", AgentURL: "http://a", TaskID: "t1", CreatedAt: created},
+				{BrokerTaskID: "broker/1", AgentID: "b", AgentURL: "http://b", TaskID: "t2", CreatedAt: created},
+				{BrokerTaskID: "broker/1", AgentID: "a", AgentURL: "http://a", TaskID: "t1", ContextID: "c1", CreatedAt: created.Add(time.Hour)},
+				{BrokerTaskID: "broker/2", AgentID: "a", AgentURL: "http://a", TaskID: "t3", CreatedAt: created},
+			}
+			for _, link := range links {
+				if err := store.Save(ctx, link); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+			if tt.reopen != nil {
+				store = tt.reopen(t)
+			}
+
+			got, err := store.Links(ctx, "broker/1")
+			if err != nil {
+				t.Fatalf("Links() error = %v", err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("Links() = %d links, want 2", len(got))
+			}
+			if got[0].TaskID != "t1" || got[0].ContextID != "c1" || !got[0].CreatedAt.Equal(created) {
+				t.Errorf("Links()[0] = %+v, want t1 updated in place", got[0])
+			}
+			if got[1].TaskID != "t2" {
+				t.Errorf("Links()[1].TaskID = %q, want t2", got[1].TaskID)
+			}
+
+			none, err := store.Links(ctx, a2a.TaskID("missing"))
+			if err != nil || len(none) != 0 {
+				t.Errorf("Links(missing) = %v, %v, want none", none, err)
+			}
+		})
+	}
+}
+
+func TestTaskStore_Prune(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		store func(t *testing.T) TaskStore
+		// age makes the links of a broker task look last saved an hour ago.
+		age func(t *testing.T, store TaskStore, id a2a.TaskID)
+	}{
+		{
+			name:  "memory",
+			store: func(t *testing.T) TaskStore { return NewMemoryTaskStore() },
+			age: func(t *testing.T, store TaskStore, id a2a.TaskID) {
+				store.(*MemoryTaskStore).saved[id] = time.Now().Add(-time.Hour)
+			},
+		},
+		{
+			name:  "file",
+			store: func(t *testing.T) TaskStore { return mustFileTaskStore(t, t.TempDir()) },
+			age: func(t *testing.T, store TaskStore, id a2a.TaskID) {
+				old := time.Now().Add(-time.Hour)
+				if err := os.Chtimes(store.(*FileTaskStore).path(id), old, old); err != nil {
+					t.Fatalf("Chtimes() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			store := tt.store(t)
+			for _, id := range []a2a.TaskID{"old", "recent"} {
+				if err := store.Save(ctx, TaskLink{BrokerTaskID: id, AgentURL: "http://a", TaskID: "t"}); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+			tt.age(t, store, "old")
+
+			pruned, err := store.Prune(ctx, time.Now().Add(-time.Minute))
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+
+			if pruned != 1 {
+				t.Errorf("Prune() = %d, want 1", pruned)
+			}
+			if links, _ := store.Links(ctx, "old"); len(links) != 0 {
+				t.Errorf("Links(old) = %v, want pruned", links)
+			}
+			if links, _ := store.Links(ctx, "recent"); len(links) != 1 {
+				t.Errorf("Links(recent) = %v, want kept", links)
+			}
+		})
+	}
+}
+
+func TestFileTaskStore_PruneTempFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	store := mustFileTaskStore(t, dir)
+	old := time.Now().Add(-time.Hour)
+	stale := filepath.Join(dir, "crashed.json.tmp123")
+	fresh := filepath.Join(dir, "saving.json.tmp456")
+	for _, path := range []string{stale, fresh} {
+		if err := os.WriteFile(path, []byte("["), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	pruned, err := store.Prune(context.Background(), time.Now().Add(-time.Minute))
+
+	if err != nil || pruned != 0 {
+		t.Errorf("Prune() = %d, %v, want 0 links pruned", pruned, err)
+	}
+	if _, err := os.Stat(stale); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("stale temp file stat error = %v, want removed", err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("recent temp file stat error = %v, want kept", err)
+	}
+}
+
+func TestTaskPruner_Prune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := NewMemoryTaskStore()
+	_ = store.Save(ctx, TaskLink{BrokerTaskID: "b", AgentURL: "http://a", TaskID: "t"})
+
+	kept := NewTaskPruner(store, WithRetention(0))
+	defer kept.Close()
+	if n, err := kept.Prune(ctx); n != 0 || err != nil {
+		t.Errorf("Prune() with zero retention = %d, %v, want 0", n, err)
+	}
+
+	pruner := NewTaskPruner(store, WithRetention(time.Nanosecond))
+	defer pruner.Close()
+	if n, err := pruner.Prune(ctx); n != 1 || err != nil {
+		t.Errorf("Prune() = %d, %v, want 1", n, err)
+	}
+}
+
+func mustFileTaskStore(t *testing.T, dir string) *FileTaskStore {
+	t.Helper()
+	store, err := NewFileTaskStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileTaskStore() error = %v", err)
+	}
+	return store
+}
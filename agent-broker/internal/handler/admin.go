@@ -29,9 +29,13 @@ func NewAdminHandler(registry *registry.RegistryService) *AdminHandler {
 func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /v1/admin/agents", h.handleList)
 	mux.HandleFunc("POST /v1/admin/agents", h.handleCreate)
+	mux.HandleFunc("POST /v1/admin/agents/batch", h.handleBatchUpsert)
+	mux.HandleFunc("POST /v1/admin/agents/batch/delete", h.handleBatchDelete)
+	mux.HandleFunc("POST /v1/admin/agents/reindex", h.handleReindex)
 	mux.HandleFunc("GET /v1/admin/agents/{id}", h.handleGet)
 	mux.HandleFunc("PUT /v1/admin/agents/{id}", h.handleUpdate)
 	mux.HandleFunc("DELETE /v1/admin/agents/{id}", h.handleDelete)
+	mux.HandleFunc("GET /v1/admin/agents/{id}/embedding-text", h.handleEmbeddingText)
 }
 
 // RegisterAgentRequest is the JSON request for registering an agent.
@@ -68,6 +72,13 @@ type AgentRecordResponse struct {
 	RegisteredAt time.Time `json:"registered_at"`
 	// UpdatedAt is the last update timestamp.
 	UpdatedAt time.Time `json:"updated_at"`
+	// EmbeddingVersion is the embedding template version of the stored vectors.
+	EmbeddingVersion string `json:"embedding_version,omitempty"`
+	// EmbeddingModel is the ID of the model that produced the stored vectors.
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+	// NeedsReembed is true when the vectors were built from a different
+	// embedding template or model than the configured ones.
+	NeedsReembed bool `json:"needs_reembed"`
 	// TODO: Add RegisteredBy field to track admin user who registered the agent.
 }
 
@@ -91,6 +102,68 @@ type PaginationResponse struct {
 	HasMore bool `json:"has_more"`
 }
 
+// ReindexResponse is the JSON response for re-embedding stored agents.
+type ReindexResponse struct {
+	// Reindexed is the number of agents whose vectors were rebuilt.
+	Reindexed int `json:"reindexed"`
+}
+
+// BatchUpsertRequest is the JSON request for upserting agents in bulk.
+type BatchUpsertRequest struct {
+	// Agents is the list of agents to create or replace.
+	Agents []RegisterAgentRequest `json:"agents"`
+}
+
+// BatchDeleteRequest is the JSON request for deleting agents in bulk.
+type BatchDeleteRequest struct {
+	// AgentIDs is the list of agent IDs to delete.
+	AgentIDs []string `json:"agent_ids"`
+}
+
+// BatchItemResponse is the JSON result for a single batch item.
+type BatchItemResponse struct {
+	// AgentID is the agent identifier.
+	AgentID string `json:"agent_id"`
+	// Status is "created", "updated", "deleted" or "failed".
+	Status string `json:"status"`
+	// Agent is the stored agent record for successful upserts.
+	Agent *AgentRecordResponse `json:"agent,omitempty"`
+	// Error describes why the item failed.
+	Error *ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchResponse is the JSON response for batch operations.
+type BatchResponse struct {
+	// Results contains per-item results in request order.
+	Results []BatchItemResponse `json:"results"`
+	// Succeeded is the number of successful items.
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of failed items.
+	Failed int `json:"failed"`
+}
+
+// EmbeddingTextResponse is the JSON response for an embedding text preview.
+type EmbeddingTextResponse struct {
+	// AgentID is the agent identifier.
+	AgentID string `json:"agent_id"`
+	// TemplateVersion is the version of the template that rendered Profile.
+	TemplateVersion string `json:"template_version"`
+	// Profile is the rendered card-level text.
+	Profile string `json:"profile"`
+	// Skills holds the text embedded for each skill.
+	Skills []SkillTextResponse `json:"skills"`
+	// Tags is the text embedded for registry tags.
+	Tags string `json:"tags,omitempty"`
+}
+
+// SkillTextResponse is the text embedded for a single skill.
+type SkillTextResponse struct {
+	// SkillID is the A2A skill ID.
+	SkillID string `json:"skill_id"`
+	// Text is the embedded text.
+	Text string `json:"text"`
+}
+
 // ErrorResponse is the JSON response for errors.
 type ErrorResponse struct {
 	// Code is the error code.
@@ -125,7 +198,7 @@ func (h *AdminHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(toAgentResponse(agent))
+	_ = json.NewEncoder(w).Encode(h.agentResponse(agent))
 }
 
 func (h *AdminHandler) handleGet(w http.ResponseWriter, r *http.Request) {
@@ -143,7 +216,7 @@ func (h *AdminHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(toAgentResponse(agent))
+	_ = json.NewEncoder(w).Encode(h.agentResponse(agent))
 }
 
 func (h *AdminHandler) handleList(w http.ResponseWriter, r *http.Request) {
@@ -169,7 +242,12 @@ func (h *AdminHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		Tags:   tags,
 		Skills: skills,
 		Query:  query.Get("q"),
+		Filter: query.Get("filter"),
 	})
+	if errors.Is(err, store.ErrInvalidFilter) {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 		return
@@ -177,7 +255,7 @@ func (h *AdminHandler) handleList(w http.ResponseWriter, r *http.Request) {
 
 	agents := make([]AgentRecordResponse, len(result.Agents))
 	for i, a := range result.Agents {
-		agents[i] = toAgentResponse(a)
+		agents[i] = h.agentResponse(a)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -217,7 +295,7 @@ func (h *AdminHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(toAgentResponse(agent))
+	_ = json.NewEncoder(w).Encode(h.agentResponse(agent))
 }
 
 func (h *AdminHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
@@ -236,6 +314,175 @@ func (h *AdminHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *AdminHandler) handleBatchUpsert(w http.ResponseWriter, r *http.Request) {
+	var req BatchUpsertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if !validBatchSize(w, len(req.Agents)) {
+		return
+	}
+
+	inputs := make([]registry.CreateInput, len(req.Agents))
+	for i, a := range req.Agents {
+		inputs[i] = registry.CreateInput{
+			ID:   a.AgentID,
+			Card: a.AgentCard,
+			Tags: a.Tags,
+		}
+	}
+
+	results, err := h.registry.BatchUpsert(r.Context(), inputs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return
+	}
+
+	writeBatchResponse(w, results, func(res registry.BatchResult) BatchItemResponse {
+		item := BatchItemResponse{AgentID: res.ID, Status: "updated"}
+		if res.Created {
+			item.Status = "created"
+		}
+		agent := h.agentResponse(res.Agent)
+		item.Agent = &agent
+		return item
+	})
+}
+
+func (h *AdminHandler) handleBatchDelete(w http.ResponseWriter, r *http.Request) {
+	var req BatchDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if !validBatchSize(w, len(req.AgentIDs)) {
+		return
+	}
+
+	results, err := h.registry.BatchDelete(r.Context(), req.AgentIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return
+	}
+
+	writeBatchResponse(w, results, func(res registry.BatchResult) BatchItemResponse {
+		return BatchItemResponse{AgentID: res.ID, Status: "deleted"}
+	})
+}
+
+// validBatchSize writes a 400 response and returns false if n is out of range.
+func validBatchSize(w http.ResponseWriter, n int) bool {
+	if n == 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "batch must contain at least one item")
+		return false
+	}
+	if n > registry.MaxBatchSize {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR",
+			"batch must contain at most "+strconv.Itoa(registry.MaxBatchSize)+" items")
+		return false
+	}
+	return true
+}
+
+// writeBatchResponse converts batch results, using onSuccess for items without errors.
+func writeBatchResponse(w http.ResponseWriter, results []registry.BatchResult, onSuccess func(registry.BatchResult) BatchItemResponse) {
+	resp := BatchResponse{Results: make([]BatchItemResponse, len(results))}
+	for i, res := range results {
+		if res.Err != nil {
+			resp.Results[i] = BatchItemResponse{
+				AgentID: res.ID,
+				Status:  "failed",
+				Error:   batchItemError(res.Err),
+			}
+			resp.Failed++
+			continue
+		}
+		resp.Results[i] = onSuccess(res)
+		resp.Succeeded++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// batchItemError classifies a failed batch item so callers can tell bad
+// input from a backend failure worth retrying.
+func batchItemError(err error) *ErrorResponse {
+	switch {
+	case errors.Is(err, registry.ErrInvalidAgent):
+		return &ErrorResponse{Code: "VALIDATION_ERROR", Message: err.Error()}
+	case errors.Is(err, store.ErrNotFound):
+		return &ErrorResponse{Code: "AGENT_NOT_FOUND", Message: err.Error()}
+	default:
+		return &ErrorResponse{Code: "INTERNAL_ERROR", Message: "internal server error"}
+	}
+}
+
+func (h *AdminHandler) handleReindex(w http.ResponseWriter, r *http.Request) {
+	reindexed, err := h.registry.Reindex(r.Context())
+	if err != nil {
+		if errors.Is(err, registry.ErrNoEmbedder) {
+			writeError(w, http.StatusNotImplemented, "NOT_SUPPORTED", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ReindexResponse{Reindexed: reindexed})
+}
+
+func (h *AdminHandler) handleEmbeddingText(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("id")
+
+	var tmpl *registry.EmbeddingTemplate
+	if source := r.URL.Query().Get("template"); source != "" {
+		var err error
+		tmpl, err = registry.ParseEmbeddingTemplate(source)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+	}
+
+	texts, err := h.registry.PreviewEmbeddingText(r.Context(), agentID, tmpl)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			writeError(w, http.StatusNotFound, "AGENT_NOT_FOUND",
+				"agent with ID '"+agentID+"' not found")
+		case errors.Is(err, registry.ErrInvalidTemplate):
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	skills := make([]SkillTextResponse, len(texts.Skills))
+	for i, skill := range texts.Skills {
+		skills[i] = SkillTextResponse{SkillID: skill.SkillID, Text: skill.Text}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(EmbeddingTextResponse{
+		AgentID:         agentID,
+		TemplateVersion: texts.Version,
+		Profile:         texts.Profile,
+		Skills:          skills,
+		Tags:            texts.Tags,
+	})
+}
+
+// agentResponse converts agent and marks whether it needs a re-embed.
+func (h *AdminHandler) agentResponse(agent *store.RegisteredAgent) AgentRecordResponse {
+	resp := toAgentResponse(agent)
+	resp.NeedsReembed = h.registry.NeedsReembed(agent)
+	return resp
+}
+
 func toAgentResponse(agent *store.RegisteredAgent) AgentRecordResponse {
 	skills := make([]string, len(agent.Card.Skills))
 	for i, s := range agent.Card.Skills {
@@ -255,6 +502,9 @@ func toAgentResponse(agent *store.RegisteredAgent) AgentRecordResponse {
 		Tags:         tags,
 		RegisteredAt: agent.CreatedAt,
 		UpdatedAt:    agent.UpdatedAt,
+
+		EmbeddingVersion: agent.EmbeddingVersion,
+		EmbeddingModel:   agent.EmbeddingModel,
 	}
 }
 
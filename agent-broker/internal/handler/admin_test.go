@@ -3,8 +3,11 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/a2aproject/a2a-go/a2a"
@@ -237,6 +240,47 @@ func TestAdminHandler_List(t *testing.T) {
 			t.Errorf("limit = %d, want 10", resp.Pagination.Limit)
 		}
 	})
+
+	t.Run("filter expression applied", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		mux.ServeHTTP(httptest.NewRecorder(), makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest()))
+
+		tests := []struct {
+			filter    string
+			wantTotal int
+		}{
+			{filter: "tags = test AND skills all (skill-1)", wantTotal: 1},
+			{filter: "NOT tags = test", wantTotal: 0},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/agents?filter="+url.QueryEscape(tt.filter), nil)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("filter %q status = %d, want %d", tt.filter, rec.Code, http.StatusOK)
+			}
+			var resp AgentListResponse
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+			if resp.Pagination.Total != tt.wantTotal {
+				t.Errorf("filter %q total = %d, want %d", tt.filter, resp.Pagination.Total, tt.wantTotal)
+			}
+		}
+	})
+
+	t.Run("invalid filter returns 400", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/agents?filter="+url.QueryEscape("owner = alice"), nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
 }
 
 func TestAdminHandler_Update(t *testing.T) {
@@ -319,6 +363,184 @@ func TestAdminHandler_Delete(t *testing.T) {
 	})
 }
 
+func TestAdminHandler_BatchUpsert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("mixed batch returns per-item results", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		createReq := makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest())
+		mux.ServeHTTP(httptest.NewRecorder(), createReq)
+
+		created := validRegisterRequest()
+		created.AgentID = "new-agent"
+		invalid := validRegisterRequest()
+		invalid.AgentID = "bad@id"
+		body := BatchUpsertRequest{Agents: []RegisterAgentRequest{validRegisterRequest(), created, invalid}}
+		req := makeJSONRequest(http.MethodPost, "/v1/admin/agents/batch", body)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var resp BatchResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		wantStatus := []string{"updated", "created", "failed"}
+		for i, want := range wantStatus {
+			if resp.Results[i].Status != want {
+				t.Errorf("results[%d].Status = %v, want %v", i, resp.Results[i].Status, want)
+			}
+		}
+		if resp.Succeeded != 2 || resp.Failed != 1 {
+			t.Errorf("succeeded/failed = %d/%d, want 2/1", resp.Succeeded, resp.Failed)
+		}
+		if err := resp.Results[2].Error; err == nil || err.Code != "VALIDATION_ERROR" {
+			t.Errorf("results[2].Error = %+v, want VALIDATION_ERROR", err)
+		}
+	})
+
+	t.Run("empty batch returns 400", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		req := makeJSONRequest(http.MethodPost, "/v1/admin/agents/batch", BatchUpsertRequest{})
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestBatchItemError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{name: "invalid agent", err: fmt.Errorf("%w: agent_id is required", registry.ErrInvalidAgent), wantCode: "VALIDATION_ERROR"},
+		{name: "missing agent", err: store.ErrNotFound, wantCode: "AGENT_NOT_FOUND"},
+		{name: "backend failure", err: errors.New("qdrant unavailable"), wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := batchItemError(tt.err); got.Code != tt.wantCode {
+				t.Errorf("batchItemError() code = %s, want %s", got.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_BatchDelete(t *testing.T) {
+	t.Parallel()
+
+	_, mux := setupHandler()
+	createReq := makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest())
+	mux.ServeHTTP(httptest.NewRecorder(), createReq)
+
+	body := BatchDeleteRequest{AgentIDs: []string{"test-agent", "not-exists"}}
+	req := makeJSONRequest(http.MethodPost, "/v1/admin/agents/batch/delete", body)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp BatchResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Results[0].Status != "deleted" {
+		t.Errorf("results[0].Status = %v, want deleted", resp.Results[0].Status)
+	}
+	if resp.Results[1].Error == nil || resp.Results[1].Error.Code != "AGENT_NOT_FOUND" {
+		t.Errorf("results[1].Error = %+v, want AGENT_NOT_FOUND", resp.Results[1].Error)
+	}
+}
+
+func TestAdminHandler_EmbeddingText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantProfile string
+	}{
+		{
+			name:        "configured template",
+			wantStatus:  http.StatusOK,
+			wantProfile: "Test Agent A test agent Skill One",
+		},
+		{
+			name:        "template override",
+			query:       "{{.Card.Name}} | {{join .Tags \", \"}}",
+			wantStatus:  http.StatusOK,
+			wantProfile: "Test Agent | test",
+		},
+		{
+			name:       "invalid template",
+			query:      "{{.Card.Unknown}}",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, mux := setupHandler()
+			mux.ServeHTTP(httptest.NewRecorder(), makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest()))
+
+			path := "/v1/admin/agents/test-agent/embedding-text"
+			if tt.query != "" {
+				path += "?template=" + url.QueryEscape(tt.query)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp EmbeddingTextResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Profile != tt.wantProfile {
+				t.Errorf("Profile = %q, want %q", resp.Profile, tt.wantProfile)
+			}
+			if len(resp.Skills) != 1 || resp.Skills[0].SkillID != "skill-1" {
+				t.Errorf("Skills = %+v, want skill-1", resp.Skills)
+			}
+			if resp.Tags != "test" {
+				t.Errorf("Tags = %q, want test", resp.Tags)
+			}
+		})
+	}
+
+	t.Run("non-existent returns 404", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/agents/not-exists/embedding-text", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+}
+
 func TestToAgentResponse(t *testing.T) {
 	t.Parallel()
 
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"maps"
 	"net/http"
 
 	"github.com/a2aproject/a2a-go/a2a"
@@ -9,6 +11,9 @@ import (
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/server/adka2a"
 	"google.golang.org/adk/session"
+
+	brokeragent "github.com/lunarr-ai/lunarr/agent-broker/internal/agent"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/delegate"
 )
 
 // BrokerHandler handles A2A protocol requests using the ADK executor.
@@ -19,17 +24,46 @@ type BrokerHandler struct {
 	agentCard *a2a.AgentCard
 }
 
+// BrokerOptions configures the BrokerHandler.
+type BrokerOptions struct {
+	// Delegator reaches the downstream tasks of delegated broker tasks. When
+	// it has a task store, tasks/get and tasks/cancel are proxied to those
+	// tasks.
+	Delegator *delegate.Delegator
+}
+
+// BrokerOption is a functional option for BrokerHandler.
+type BrokerOption func(*BrokerOptions)
+
+// WithDelegator proxies tasks/get and tasks/cancel to the downstream tasks
+// recorded by delegator, using its timeouts and concurrency.
+func WithDelegator(delegator *delegate.Delegator) BrokerOption {
+	return func(o *BrokerOptions) {
+		o.Delegator = delegator
+	}
+}
+
 // NewBrokerHandler creates a new A2A handler with the given agent and session service.
-func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service) *BrokerHandler {
+func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service, opts ...BrokerOption) *BrokerHandler {
+	var options BrokerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
 		RunnerConfig: runner.Config{
 			AppName:        brokerAgent.Name(),
 			Agent:          brokerAgent,
 			SessionService: sessionService,
 		},
+		BeforeExecuteCallback: withRequestMetadata,
 	})
 
-	handler := a2asrv.NewHandler(executor)
+	// Delegated calls relay streaming agents' events into the broker task.
+	var handler a2asrv.RequestHandler = a2asrv.NewHandler(delegate.NewRelayExecutor(executor))
+	if options.Delegator != nil && options.Delegator.TracksTasks() {
+		handler = delegate.NewTaskHandler(handler, options.Delegator)
+	}
 
 	// Build agent card from the agent
 	skills := adka2a.BuildAgentSkills(brokerAgent)
@@ -38,6 +72,9 @@ func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service) *
 		Description: brokerAgent.Description(),
 		Version:     "1.0.0",
 		Skills:      skills,
+		Capabilities: a2a.AgentCapabilities{
+			Streaming: true,
+		},
 	}
 
 	return &BrokerHandler{
@@ -46,6 +83,19 @@ func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service) *
 	}
 }
 
+// withRequestMetadata makes the request and message metadata available to
+// the broker agent, with message keys taking precedence.
+func withRequestMetadata(ctx context.Context, reqCtx *a2asrv.RequestContext) (context.Context, error) {
+	meta := maps.Clone(reqCtx.Metadata)
+	if reqCtx.Message != nil && len(reqCtx.Message.Metadata) > 0 {
+		if meta == nil {
+			meta = make(map[string]any, len(reqCtx.Message.Metadata))
+		}
+		maps.Copy(meta, reqCtx.Message.Metadata)
+	}
+	return brokeragent.ContextWithRequestMetadata(ctx, meta), nil
+}
+
 // RegisterRoutes registers A2A routes on the given ServeMux.
 func (h *BrokerHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("POST /", a2asrv.NewJSONRPCHandler(h.handler))
@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+
+	brokeragent "github.com/lunarr-ai/lunarr/agent-broker/internal/agent"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/rerank"
+)
+
+// newRulesBroker starts a broker in rules mode over a registry, built with
+// regOpts, holding a translator and a weather agent, and returns a client
+// for it.
+func newRulesBroker(t *testing.T, regOpts []registry.Option, opts ...brokeragent.Option) *a2aclient.Client {
+	t.Helper()
+	ctx := context.Background()
+	svc := registry.NewRegistryService(store.NewMemoryStore(), regOpts...)
+	for _, input := range []registry.CreateInput{
+		{ID: "translator", Tags: []string{"language"}, Card: a2a.AgentCard{
+			Name: "Translator", Description: "Translates text between languages", URL: "http://localhost:9001", Version: "1.0.0",
+			Skills: []a2a.AgentSkill{{ID: "translate", Name: "Translate text"}},
+		}},
+		{ID: "weather", Tags: []string{"forecast"}, Card: a2a.AgentCard{
+			Name: "Weather", Description: "Reports the weather forecast for a city", URL: "http://localhost:9002", Version: "1.0.0",
+			Skills: []a2a.AgentSkill{{ID: "forecast", Name: "Weather forecast"}},
+		}},
+	} {
+		if _, err := svc.Create(ctx, input); err != nil {
+			t.Fatalf("Create(%s) error = %v", input.ID, err)
+		}
+	}
+
+	broker, err := brokeragent.NewBrokerAgent(ctx, svc, append([]brokeragent.Option{brokeragent.WithMode(brokeragent.ModeRules)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewBrokerAgent() error = %v", err)
+	}
+	mux := http.NewServeMux()
+	NewBrokerHandler(broker, brokeragent.NewSessionService()).RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client, err := a2aclient.NewFromCard(ctx, &a2a.AgentCard{URL: srv.URL, PreferredTransport: a2a.TransportProtocolJSONRPC})
+	if err != nil {
+		t.Fatalf("NewFromCard() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Destroy() })
+	return client
+}
+
+// resultData returns the name and response of the function response data
+// part in a task's artifacts.
+func resultData(task *a2a.Task) (string, map[string]any) {
+	for _, artifact := range task.Artifacts {
+		for _, part := range artifact.Parts {
+			if data, ok := part.(a2a.DataPart); ok {
+				name, _ := data.Data["name"].(string)
+				response, _ := data.Data["response"].(map[string]any)
+				return name, response
+			}
+		}
+	}
+	return "", nil
+}
+
+// nameReranker scores each document by the first agent name it mentions.
+type nameReranker struct {
+	// scores maps agent names to their score.
+	scores map[string]float32
+	// err fails every call when set.
+	err error
+}
+
+func (r nameReranker) Rerank(_ context.Context, _ string, documents []string) ([]float32, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	scores := make([]float32, len(documents))
+	for i, document := range documents {
+		for name, score := range r.scores {
+			if strings.Contains(document, name) {
+				scores[i] = score
+				break
+			}
+		}
+	}
+	return scores, nil
+}
+
+func TestBrokerHandler_RulesMode(t *testing.T) {
+	t.Parallel()
+	client := newRulesBroker(t, nil)
+
+	tests := []struct {
+		name      string
+		text      string
+		metadata  map[string]any
+		wantState a2a.TaskState
+		wantName  string
+		check     func(t *testing.T, response map[string]any)
+	}{
+		{
+			name:      "default action routes",
+			text:      "translate this text",
+			wantState: a2a.TaskStateCompleted,
+			wantName:  "route",
+			check: func(t *testing.T, response map[string]any) {
+				agent, _ := response["agent"].(map[string]any)
+				card, _ := agent["card"].(map[string]any)
+				if response["found"] != true || card["name"] != "Translator" {
+					t.Errorf("route response = %v, want the translator", response)
+				}
+			},
+		},
+		{
+			name:      "metadata selects discover with args",
+			text:      "weather forecast",
+			metadata:  map[string]any{brokeragent.ActionMetadataKey: "discover", brokeragent.ArgsMetadataKey: map[string]any{"limit": 1}},
+			wantState: a2a.TaskStateCompleted,
+			wantName:  "discover",
+			check: func(t *testing.T, response map[string]any) {
+				if response["total"] != float64(1) {
+					t.Errorf("discover total = %v, want 1", response["total"])
+				}
+			},
+		},
+		{
+			name:      "args override the query",
+			text:      "please help",
+			metadata:  map[string]any{brokeragent.ActionMetadataKey: "broadcast", brokeragent.ArgsMetadataKey: map[string]any{"query": "weather city"}},
+			wantState: a2a.TaskStateCompleted,
+			wantName:  "broadcast",
+			check: func(t *testing.T, response map[string]any) {
+				agents, _ := response["agents"].([]any)
+				if len(agents) == 0 {
+					t.Fatalf("broadcast agents = %v, want matches for the args query", response)
+				}
+				first, _ := agents[0].(map[string]any)
+				card, _ := first["card"].(map[string]any)
+				if card["name"] != "Weather" {
+					t.Errorf("first agent = %v, want Weather", card["name"])
+				}
+			},
+		},
+		{
+			name:      "unknown action fails",
+			text:      "translate",
+			metadata:  map[string]any{brokeragent.ActionMetadataKey: "summon"},
+			wantState: a2a.TaskStateFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msg := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: tt.text})
+			msg.Metadata = tt.metadata
+			result, err := client.SendMessage(context.Background(), &a2a.MessageSendParams{Message: msg})
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			task, ok := result.(*a2a.Task)
+			if !ok {
+				t.Fatalf("SendMessage() = %T, want a task", result)
+			}
+			if task.Status.State != tt.wantState {
+				t.Fatalf("state = %q, want %q", task.Status.State, tt.wantState)
+			}
+			if tt.check == nil {
+				return
+			}
+			name, response := resultData(task)
+			if name != tt.wantName {
+				t.Fatalf("result name = %q, want %q", name, tt.wantName)
+			}
+			tt.check(t, response)
+		})
+	}
+}
+
+func TestBrokerHandler_RouteConfidence(t *testing.T) {
+	t.Parallel()
+
+	// Without an embedder, candidates are found by keyword overlap:
+	// "translate this text" matches only the translator, "translate text
+	// weather" both agents. The reranker then scores the translator 0.8
+	// and the weather agent 0.65.
+	reranker := nameReranker{scores: map[string]float32{"Translator": 0.8, "Weather": 0.65}}
+	tests := []struct {
+		name             string
+		reranker         rerank.Reranker
+		policy           tools.RoutePolicy
+		text             string
+		wantConfidence   string
+		wantFound        bool
+		wantAgent        string
+		wantAlternatives int
+	}{
+		{
+			name:           "default policy trusts any match",
+			reranker:       reranker,
+			text:           "translate text weather",
+			wantConfidence: "high",
+			wantFound:      true,
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "score above thresholds is high",
+			reranker:       reranker,
+			policy:         tools.RoutePolicy{MinScore: 0.7, MinMargin: 0.1, Reranked: true},
+			text:           "translate text weather",
+			wantConfidence: "high",
+			wantFound:      true,
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "score below global minimum is low",
+			reranker:       reranker,
+			policy:         tools.RoutePolicy{MinScore: 0.9, Reranked: true},
+			text:           "translate this text",
+			wantConfidence: "low",
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "tag minimum raises the threshold",
+			reranker:       reranker,
+			policy:         tools.RoutePolicy{MinScore: 0.3, TagMinScores: map[string]float32{"language": 0.9, "forecast": 0.1}, Reranked: true},
+			text:           "translate this text",
+			wantConfidence: "low",
+			wantAgent:      "Translator",
+		},
+		{
+			name:             "close runner-up is ambiguous",
+			reranker:         reranker,
+			policy:           tools.RoutePolicy{MinMargin: 0.2, Reranked: true},
+			text:             "translate text weather",
+			wantConfidence:   "ambiguous",
+			wantAgent:        "Translator",
+			wantAlternatives: 1,
+		},
+		{
+			name:           "runner-up below its threshold is no contender",
+			reranker:       reranker,
+			policy:         tools.RoutePolicy{MinMargin: 0.2, TagMinScores: map[string]float32{"forecast": 0.9}, Reranked: true},
+			text:           "translate text weather",
+			wantConfidence: "high",
+			wantFound:      true,
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "lexical match passes the default policy",
+			text:           "translate this text",
+			wantConfidence: "high",
+			wantFound:      true,
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "lexical match is low under thresholds",
+			policy:         tools.RoutePolicy{MinScore: 0.3},
+			text:           "translate this text",
+			wantConfidence: "low",
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "failed rerank is low under reranker thresholds",
+			reranker:       nameReranker{err: errors.New("reranker down")},
+			policy:         tools.RoutePolicy{MinScore: 0.3, Reranked: true},
+			text:           "translate this text",
+			wantConfidence: "low",
+			wantAgent:      "Translator",
+		},
+		{
+			name:           "no match is none",
+			policy:         tools.RoutePolicy{MinScore: 0.3},
+			text:           "zzz",
+			wantConfidence: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var regOpts []registry.Option
+			if tt.reranker != nil {
+				regOpts = append(regOpts, registry.WithReranker(tt.reranker))
+			}
+			client := newRulesBroker(t, regOpts, brokeragent.WithRoutePolicy(tt.policy))
+
+			msg := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: tt.text})
+			result, err := client.SendMessage(context.Background(), &a2a.MessageSendParams{Message: msg})
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			task, ok := result.(*a2a.Task)
+			if !ok || task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("SendMessage() = %v, want a completed task", result)
+			}
+
+			_, response := resultData(task)
+			if response["confidence"] != tt.wantConfidence || response["found"] != tt.wantFound {
+				t.Errorf("confidence = %v, found = %v, want %s, %v", response["confidence"], response["found"], tt.wantConfidence, tt.wantFound)
+			}
+			agent, _ := response["agent"].(map[string]any)
+			card, _ := agent["card"].(map[string]any)
+			if name, _ := card["name"].(string); name != tt.wantAgent {
+				t.Errorf("agent = %q, want %q", name, tt.wantAgent)
+			}
+			alternatives, _ := response["alternatives"].([]any)
+			if len(alternatives) != tt.wantAlternatives {
+				t.Errorf("alternatives = %v, want %d", alternatives, tt.wantAlternatives)
+			}
+		})
+	}
+}
+
+func TestNewBrokerAgent_RulesModeValidation(t *testing.T) {
+	t.Parallel()
+	svc := registry.NewRegistryService(store.NewMemoryStore())
+
+	tests := []struct {
+		name string
+		opts []brokeragent.Option
+	}{
+		{name: "unknown mode", opts: []brokeragent.Option{brokeragent.WithMode("oracle")}},
+		{name: "unknown default action", opts: []brokeragent.Option{brokeragent.WithMode(brokeragent.ModeRules), brokeragent.WithDefaultAction("summon")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := brokeragent.NewBrokerAgent(context.Background(), svc, tt.opts...); err == nil {
+				t.Error("NewBrokerAgent() error = nil, want an error")
+			}
+		})
+	}
+}
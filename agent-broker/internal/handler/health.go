@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
 )
 
 // HealthResponse is the JSON response for health check endpoints.
@@ -15,6 +16,10 @@ type HealthResponse struct {
 	Status string `json:"status"`
 	// Checks contains individual component health statuses.
 	Checks HealthChecks `json:"checks"`
+	// Cache contains registry cache counters when caching is enabled.
+	Cache *CacheStatsResponse `json:"cache,omitempty"`
+	// EmbeddingCache contains embedding cache counters when caching is enabled.
+	EmbeddingCache *EmbeddingCacheStatsResponse `json:"embedding_cache,omitempty"`
 }
 
 // HealthChecks contains status of individual health check components.
@@ -23,15 +28,68 @@ type HealthChecks struct {
 	Registry string `json:"registry"`
 }
 
+// CacheStatsResponse contains registry cache hit/miss counters.
+type CacheStatsResponse struct {
+	// AgentHits is the number of agent lookups served from cache.
+	AgentHits uint64 `json:"agent_hits"`
+	// AgentMisses is the number of agent lookups sent to the backend.
+	AgentMisses uint64 `json:"agent_misses"`
+	// ListHits is the number of list queries served from cache.
+	ListHits uint64 `json:"list_hits"`
+	// ListMisses is the number of list queries sent to the backend.
+	ListMisses uint64 `json:"list_misses"`
+	// SearchHits is the number of vector searches served from cache.
+	SearchHits uint64 `json:"search_hits"`
+	// SearchMisses is the number of vector searches sent to the backend.
+	SearchMisses uint64 `json:"search_misses"`
+}
+
+// EmbeddingCacheStatsResponse contains embedding cache counters.
+type EmbeddingCacheStatsResponse struct {
+	// MemoryHits is the number of texts served from the in-memory LRU.
+	MemoryHits uint64 `json:"memory_hits"`
+	// DiskHits is the number of texts served from the on-disk tier.
+	DiskHits uint64 `json:"disk_hits"`
+	// Misses is the number of texts sent to the embedding service.
+	Misses uint64 `json:"misses"`
+	// Coalesced is the number of texts that joined an in-flight request.
+	Coalesced uint64 `json:"coalesced"`
+	// HitRate is the fraction of texts not sent to the embedding service.
+	HitRate float64 `json:"hit_rate"`
+}
+
 // HealthHandler handles HTTP health check requests.
 type HealthHandler struct {
 	// store is the health checker for storage backend.
 	store store.HealthChecker
+	// embedder is reported on when it exposes cache stats.
+	embedder embedding.Embedder
+}
+
+// HealthOptions configures the HealthHandler.
+type HealthOptions struct {
+	// Embedder is the embedder to report cache stats for.
+	Embedder embedding.Embedder
+}
+
+// HealthOption is a functional option for HealthHandler.
+type HealthOption func(*HealthOptions)
+
+// WithEmbedder reports the embedder's cache stats when it has any.
+func WithEmbedder(e embedding.Embedder) HealthOption {
+	return func(o *HealthOptions) {
+		o.Embedder = e
+	}
 }
 
 // NewHealthHandler creates a HealthHandler. If checker is nil, always reports healthy.
-func NewHealthHandler(checker store.HealthChecker) *HealthHandler {
-	return &HealthHandler{store: checker}
+func NewHealthHandler(checker store.HealthChecker, opts ...HealthOption) *HealthHandler {
+	var options HealthOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &HealthHandler{store: checker, embedder: options.Embedder}
 }
 
 // ServeHTTP handles GET /health requests.
@@ -55,6 +113,29 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if reporter, ok := h.store.(store.CacheStatsReporter); ok {
+		stats := reporter.CacheStats()
+		response.Cache = &CacheStatsResponse{
+			AgentHits:    stats.AgentHits,
+			AgentMisses:  stats.AgentMisses,
+			ListHits:     stats.ListHits,
+			ListMisses:   stats.ListMisses,
+			SearchHits:   stats.SearchHits,
+			SearchMisses: stats.SearchMisses,
+		}
+	}
+
+	if reporter, ok := h.embedder.(embedding.CacheStatsReporter); ok {
+		stats := reporter.CacheStats()
+		response.EmbeddingCache = &EmbeddingCacheStatsResponse{
+			MemoryHits: stats.MemoryHits,
+			DiskHits:   stats.DiskHits,
+			Misses:     stats.Misses,
+			Coalesced:  stats.Coalesced,
+			HitRate:    stats.HitRate(),
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(response)
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/sessionstore"
+)
+
+// SessionsHandler handles admin API endpoints for broker sessions.
+type SessionsHandler struct {
+	// sessions is the broker's session service.
+	sessions session.Service
+	// apps are the app names listed when no app is requested.
+	apps []string
+	// expirer applies the idle-expiry policy, or nil.
+	expirer *sessionstore.Expirer
+}
+
+// SessionsOptions configures the SessionsHandler.
+type SessionsOptions struct {
+	// Expirer exposes its idle-expiry policy for reading and updating.
+	Expirer *sessionstore.Expirer
+}
+
+// SessionsOption is a functional option for SessionsHandler.
+type SessionsOption func(*SessionsOptions)
+
+// WithExpirer exposes the expirer's policy through the policy endpoints.
+func WithExpirer(e *sessionstore.Expirer) SessionsOption {
+	return func(o *SessionsOptions) {
+		o.Expirer = e
+	}
+}
+
+// NewSessionsHandler creates a SessionsHandler over the sessions of apps.
+func NewSessionsHandler(sessions session.Service, apps []string, opts ...SessionsOption) *SessionsHandler {
+	var options SessionsOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &SessionsHandler{sessions: sessions, apps: apps, expirer: options.Expirer}
+}
+
+// RegisterRoutes registers session admin routes on the given ServeMux.
+func (h *SessionsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/admin/sessions", h.handleList)
+	mux.HandleFunc("GET /v1/admin/sessions/policy", h.handleGetPolicy)
+	mux.HandleFunc("PUT /v1/admin/sessions/policy", h.handleSetPolicy)
+	mux.HandleFunc("GET /v1/admin/sessions/{app}/{user}/{id}", h.handleGet)
+	mux.HandleFunc("DELETE /v1/admin/sessions/{app}/{user}/{id}", h.handleDelete)
+}
+
+// SessionResponse is the JSON response for a single session.
+type SessionResponse struct {
+	// AppName is the app the session belongs to.
+	AppName string `json:"app_name"`
+	// UserID is the session's user.
+	UserID string `json:"user_id"`
+	// SessionID is the session identifier, the A2A context ID.
+	SessionID string `json:"session_id"`
+	// LastUpdateTime is when the session last changed.
+	LastUpdateTime time.Time `json:"last_update_time"`
+	// State is the session state, including app and user state.
+	State map[string]any `json:"state,omitempty"`
+	// Events are the session events, oldest first. Only set when a single
+	// session is inspected.
+	Events []SessionEventResponse `json:"events,omitempty"`
+}
+
+// SessionEventResponse is the JSON form of a session event.
+type SessionEventResponse struct {
+	// ID is the event identifier.
+	ID string `json:"id"`
+	// InvocationID groups the events of one agent run.
+	InvocationID string `json:"invocation_id"`
+	// Author is "user" or the name of the agent that produced the event.
+	Author string `json:"author"`
+	// Timestamp is when the event was created.
+	Timestamp time.Time `json:"timestamp"`
+	// Role is the role of the event content.
+	Role string `json:"role,omitempty"`
+	// Parts are the event's text, tool calls and tool results.
+	Parts []EventPartResponse `json:"parts,omitempty"`
+	// StateDelta is the state change the event applied.
+	StateDelta map[string]any `json:"state_delta,omitempty"`
+	// ErrorCode is set when the model call failed.
+	ErrorCode string `json:"error_code,omitempty"`
+	// ErrorMessage describes the model error.
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// EventPartResponse is one part of a session event.
+type EventPartResponse struct {
+	// Type is "text", "tool_call", "tool_result" or "other".
+	Type string `json:"type"`
+	// Text is the text of a text part.
+	Text string `json:"text,omitempty"`
+	// ToolCallID links a tool call to its result.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Name is the tool name of a tool call or result.
+	Name string `json:"name,omitempty"`
+	// Args are the tool call arguments.
+	Args map[string]any `json:"args,omitempty"`
+	// Response is the tool result.
+	Response map[string]any `json:"response,omitempty"`
+}
+
+// SessionListResponse is the JSON response for listing sessions.
+type SessionListResponse struct {
+	// Sessions is the list of sessions, most recently updated first.
+	Sessions []SessionResponse `json:"sessions"`
+	// Pagination contains pagination info.
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// ExpiryPolicyRequest is the JSON body for updating the idle-expiry policy.
+type ExpiryPolicyRequest struct {
+	// IdleTimeout is a Go duration such as "24h"; "0" keeps sessions forever.
+	IdleTimeout string `json:"idle_timeout"`
+}
+
+// ExpiryPolicyResponse is the JSON response for the idle-expiry policy.
+type ExpiryPolicyResponse struct {
+	// IdleTimeout is the idle timeout as a Go duration, "0s" when disabled.
+	IdleTimeout string `json:"idle_timeout"`
+	// IdleTimeoutSeconds is the idle timeout in seconds.
+	IdleTimeoutSeconds int64 `json:"idle_timeout_seconds"`
+}
+
+func (h *SessionsHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+
+	now := time.Now()
+	var olderThan, newerThan time.Duration
+	for param, d := range map[string]*time.Duration{"older_than": &olderThan, "newer_than": &newerThan} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "invalid "+param+" duration '"+v+"'")
+			return
+		}
+		*d = parsed
+	}
+
+	apps := h.apps
+	if app := query.Get("app"); app != "" {
+		apps = []string{app}
+	}
+
+	var sessions []session.Session
+	for _, app := range apps {
+		resp, err := h.sessions.List(r.Context(), &session.ListRequest{AppName: app, UserID: query.Get("user")})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+			return
+		}
+		for _, s := range resp.Sessions {
+			idle := now.Sub(s.LastUpdateTime())
+			if olderThan > 0 && idle < olderThan {
+				continue
+			}
+			if newerThan > 0 && idle > newerThan {
+				continue
+			}
+			sessions = append(sessions, s)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b session.Session) int {
+		return b.LastUpdateTime().Compare(a.LastUpdateTime())
+	})
+
+	total := len(sessions)
+	page := sessions[min(max(offset, 0), total):]
+	page = page[:min(max(limit, 0), len(page))]
+	results := make([]SessionResponse, len(page))
+	for i, s := range page {
+		results[i] = toSessionResponse(s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SessionListResponse{
+		Sessions: results,
+		Pagination: PaginationResponse{
+			Total:   total,
+			Offset:  offset,
+			Limit:   limit,
+			HasMore: offset+len(results) < total,
+		},
+	})
+}
+
+func (h *SessionsHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	app, user, id := r.PathValue("app"), r.PathValue("user"), r.PathValue("id")
+	if !h.exists(w, r, app, user, id) {
+		return
+	}
+
+	resp, err := h.sessions.Get(r.Context(), &session.GetRequest{AppName: app, UserID: user, SessionID: id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return
+	}
+
+	result := toSessionResponse(resp.Session)
+	result.Events = []SessionEventResponse{}
+	for event := range resp.Session.Events().All() {
+		result.Events = append(result.Events, toSessionEventResponse(event))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (h *SessionsHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	app, user, id := r.PathValue("app"), r.PathValue("user"), r.PathValue("id")
+	if !h.exists(w, r, app, user, id) {
+		return
+	}
+
+	if err := h.sessions.Delete(r.Context(), &session.DeleteRequest{AppName: app, UserID: user, SessionID: id}); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// exists reports whether the session exists, writing an error response
+// when it does not. Session services do not return typed not-found errors,
+// so the user's sessions are listed instead.
+func (h *SessionsHandler) exists(w http.ResponseWriter, r *http.Request, app, user, id string) bool {
+	resp, err := h.sessions.List(r.Context(), &session.ListRequest{AppName: app, UserID: user})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return false
+	}
+	for _, s := range resp.Sessions {
+		if s.ID() == id {
+			return true
+		}
+	}
+	writeError(w, http.StatusNotFound, "SESSION_NOT_FOUND", "session '"+id+"' not found")
+	return false
+}
+
+func (h *SessionsHandler) handleGetPolicy(w http.ResponseWriter, _ *http.Request) {
+	if h.expirer == nil {
+		writeError(w, http.StatusNotImplemented, "NOT_SUPPORTED", "session expiry is not enabled")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toExpiryPolicyResponse(h.expirer.Policy()))
+}
+
+// handleSetPolicy replaces the idle-expiry policy. The change lives in
+// memory only; a restart goes back to the configured retention.
+func (h *SessionsHandler) handleSetPolicy(w http.ResponseWriter, r *http.Request) {
+	if h.expirer == nil {
+		writeError(w, http.StatusNotImplemented, "NOT_SUPPORTED", "session expiry is not enabled")
+		return
+	}
+
+	var req ExpiryPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	idle, err := time.ParseDuration(req.IdleTimeout)
+	if err != nil || idle < 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "idle_timeout must be a non-negative duration such as '24h'")
+		return
+	}
+
+	policy := sessionstore.ExpiryPolicy{IdleTimeout: idle}
+	h.expirer.SetPolicy(policy)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toExpiryPolicyResponse(policy))
+}
+
+func toSessionResponse(s session.Session) SessionResponse {
+	state := make(map[string]any)
+	for k, v := range s.State().All() {
+		state[k] = v
+	}
+	return SessionResponse{
+		AppName:        s.AppName(),
+		UserID:         s.UserID(),
+		SessionID:      s.ID(),
+		LastUpdateTime: s.LastUpdateTime(),
+		State:          state,
+	}
+}
+
+func toSessionEventResponse(event *session.Event) SessionEventResponse {
+	resp := SessionEventResponse{
+		ID:           event.ID,
+		InvocationID: event.InvocationID,
+		Author:       event.Author,
+		Timestamp:    event.Timestamp,
+		StateDelta:   event.Actions.StateDelta,
+		ErrorCode:    event.ErrorCode,
+		ErrorMessage: event.ErrorMessage,
+	}
+	if event.Content == nil {
+		return resp
+	}
+	resp.Role = event.Content.Role
+	for _, part := range event.Content.Parts {
+		if part != nil {
+			resp.Parts = append(resp.Parts, toEventPartResponse(part))
+		}
+	}
+	return resp
+}
+
+func toEventPartResponse(part *genai.Part) EventPartResponse {
+	switch {
+	case part.FunctionCall != nil:
+		return EventPartResponse{
+			Type:       "tool_call",
+			ToolCallID: part.FunctionCall.ID,
+			Name:       part.FunctionCall.Name,
+			Args:       part.FunctionCall.Args,
+		}
+	case part.FunctionResponse != nil:
+		return EventPartResponse{
+			Type:       "tool_result",
+			ToolCallID: part.FunctionResponse.ID,
+			Name:       part.FunctionResponse.Name,
+			Response:   part.FunctionResponse.Response,
+		}
+	case part.Text != "":
+		return EventPartResponse{Type: "text", Text: part.Text}
+	default:
+		return EventPartResponse{Type: "other"}
+	}
+}
+
+func toExpiryPolicyResponse(policy sessionstore.ExpiryPolicy) ExpiryPolicyResponse {
+	return ExpiryPolicyResponse{
+		IdleTimeout:        policy.IdleTimeout.String(),
+		IdleTimeoutSeconds: int64(policy.IdleTimeout / time.Second),
+	}
+}
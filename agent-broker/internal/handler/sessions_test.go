@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/sessionstore"
+)
+
+// setupSessionsHandler returns a mux over an in-memory session service
+// holding ctx-1 for alice, with a routed tool call, and ctx-2 for bob.
+func setupSessionsHandler(t *testing.T) (*sessionstore.Expirer, *http.ServeMux) {
+	t.Helper()
+	ctx := context.Background()
+	svc := session.InMemoryService()
+
+	for _, s := range []struct{ user, id string }{{"alice", "ctx-1"}, {"bob", "ctx-2"}} {
+		created, err := svc.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: s.user, SessionID: s.id})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if s.id != "ctx-1" {
+			continue
+		}
+		for _, content := range []*genai.Content{
+			genai.NewContentFromText("find a translator", genai.RoleUser),
+			{Role: genai.RoleModel, Parts: []*genai.Part{
+				{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "route", Args: map[string]any{"query": "translate"}}},
+			}},
+			{Role: genai.RoleUser, Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "call-1", Name: "route", Response: map[string]any{"agent_id": "translator"}}},
+			}},
+		} {
+			event := session.NewEvent("inv-1")
+			event.Author = "broker"
+			event.Content = content
+			if err := svc.AppendEvent(ctx, created.Session, event); err != nil {
+				t.Fatalf("AppendEvent() error = %v", err)
+			}
+		}
+		// Leave ctx-2 as the most recently updated session.
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	expirer := sessionstore.NewExpirer(svc, []string{"broker"}, sessionstore.WithInterval(time.Hour))
+	t.Cleanup(expirer.Close)
+	mux := http.NewServeMux()
+	NewSessionsHandler(svc, []string{"broker"}, WithExpirer(expirer)).RegisterRoutes(mux)
+	return expirer, mux
+}
+
+func TestSessionsHandler_List(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{name: "all sessions newest first", query: "", wantStatus: http.StatusOK, wantIDs: []string{"ctx-2", "ctx-1"}},
+		{name: "filter by user", query: "?user=alice", wantStatus: http.StatusOK, wantIDs: []string{"ctx-1"}},
+		{name: "filter by unknown app", query: "?app=other", wantStatus: http.StatusOK, wantIDs: []string{}},
+		{name: "newer than keeps recent sessions", query: "?newer_than=1h", wantStatus: http.StatusOK, wantIDs: []string{"ctx-2", "ctx-1"}},
+		{name: "older than skips recent sessions", query: "?older_than=1h", wantStatus: http.StatusOK, wantIDs: []string{}},
+		{name: "pagination", query: "?offset=1&limit=1", wantStatus: http.StatusOK, wantIDs: []string{"ctx-1"}},
+		{name: "invalid duration returns 400", query: "?older_than=soon", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, mux := setupSessionsHandler(t)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/sessions"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp SessionListResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(resp.Sessions) != len(tt.wantIDs) {
+				t.Fatalf("sessions = %+v, want %v", resp.Sessions, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if resp.Sessions[i].SessionID != id {
+					t.Errorf("sessions[%d] = %s, want %s", i, resp.Sessions[i].SessionID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionsHandler_Get(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns events with tool calls and results", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupSessionsHandler(t)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/sessions/broker/alice/ctx-1", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var resp SessionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.UserID != "alice" || len(resp.Events) != 3 {
+			t.Fatalf("session = %+v, want alice with 3 events", resp)
+		}
+		want := []EventPartResponse{
+			{Type: "text", Text: "find a translator"},
+			{Type: "tool_call", ToolCallID: "call-1", Name: "route"},
+			{Type: "tool_result", ToolCallID: "call-1", Name: "route"},
+		}
+		for i, w := range want {
+			got := resp.Events[i].Parts[0]
+			if got.Type != w.Type || got.Text != w.Text || got.ToolCallID != w.ToolCallID || got.Name != w.Name {
+				t.Errorf("event %d part = %+v, want %+v", i, got, w)
+			}
+		}
+		if resp.Events[1].Parts[0].Args["query"] != "translate" {
+			t.Errorf("tool call args = %v, want query translate", resp.Events[1].Parts[0].Args)
+		}
+		if resp.Events[2].Parts[0].Response["agent_id"] != "translator" {
+			t.Errorf("tool result = %v, want agent_id translator", resp.Events[2].Parts[0].Response)
+		}
+	})
+
+	t.Run("unknown session returns 404", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupSessionsHandler(t)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/sessions/broker/bob/ctx-1", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestSessionsHandler_Delete(t *testing.T) {
+	t.Parallel()
+
+	_, mux := setupSessionsHandler(t)
+
+	for _, wantStatus := range []int{http.StatusNoContent, http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/admin/sessions/broker/alice/ctx-1", nil))
+		if rec.Code != wantStatus {
+			t.Errorf("status = %d, want %d", rec.Code, wantStatus)
+		}
+	}
+}
+
+func TestSessionsHandler_Policy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantIdle   time.Duration
+	}{
+		{name: "sets idle timeout", body: `{"idle_timeout":"24h"}`, wantStatus: http.StatusOK, wantIdle: 24 * time.Hour},
+		{name: "zero disables expiry", body: `{"idle_timeout":"0"}`, wantStatus: http.StatusOK},
+		{name: "negative timeout returns 400", body: `{"idle_timeout":"-1h"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid duration returns 400", body: `{"idle_timeout":"a day"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid JSON returns 400", body: `{invalid`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			expirer, mux := setupSessionsHandler(t)
+			expirer.SetPolicy(sessionstore.ExpiryPolicy{IdleTimeout: time.Minute})
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/v1/admin/sessions/policy", bytes.NewBufferString(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if got := expirer.Policy().IdleTimeout; got != time.Minute {
+					t.Errorf("policy changed to %v on a rejected request", got)
+				}
+				return
+			}
+			if got := expirer.Policy().IdleTimeout; got != tt.wantIdle {
+				t.Errorf("IdleTimeout = %v, want %v", got, tt.wantIdle)
+			}
+
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/sessions/policy", nil))
+			var resp ExpiryPolicyResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.IdleTimeout != tt.wantIdle.String() {
+				t.Errorf("GET policy = %+v, want %v", resp, tt.wantIdle)
+			}
+		})
+	}
+}
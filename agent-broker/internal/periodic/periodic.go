@@ -0,0 +1,50 @@
+// Package periodic runs background maintenance passes on a fixed interval.
+package periodic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Runner calls a function every interval until it is closed.
+type Runner struct {
+	// stop ends the loop.
+	stop context.CancelFunc
+	// done is closed when the loop has exited.
+	done chan struct{}
+	// closeOnce guards Close.
+	closeOnce sync.Once
+}
+
+// Start calls fn every interval in a background goroutine. The context
+// passed to fn is cancelled by Close.
+func Start(interval time.Duration, fn func(ctx context.Context)) *Runner {
+	ctx, stop := context.WithCancel(context.Background())
+	r := &Runner{stop: stop, done: make(chan struct{})}
+	go r.loop(ctx, interval, fn)
+	return r
+}
+
+// loop calls fn every interval until ctx is done.
+func (r *Runner) loop(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	defer close(r.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fn(ctx)
+	}
+}
+
+// Close stops the loop and waits for a running pass to return.
+func (r *Runner) Close() {
+	r.closeOnce.Do(func() {
+		r.stop()
+		<-r.done
+	})
+}
@@ -0,0 +1,36 @@
+package periodic
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+	var passes atomic.Int64
+	var cancelled atomic.Bool
+	r := Start(time.Millisecond, func(ctx context.Context) {
+		if passes.Add(1) == 3 {
+			<-ctx.Done()
+			cancelled.Store(true)
+		}
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for passes.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	r.Close()
+	r.Close()
+
+	if !cancelled.Load() {
+		t.Error("Close() returned before the running pass saw its context cancelled")
+	}
+	after := passes.Load()
+	time.Sleep(10 * time.Millisecond)
+	if got := passes.Load(); got != after {
+		t.Errorf("passes after Close() = %d, want %d", got, after)
+	}
+}
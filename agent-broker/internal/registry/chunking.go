@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+)
+
+// PoolingMode selects how chunk vectors are combined into one vector.
+type PoolingMode string
+
+// Pooling modes.
+const (
+	// PoolingMean averages the chunk vectors.
+	PoolingMean PoolingMode = "mean"
+	// PoolingMax takes the element-wise maximum of the chunk vectors.
+	PoolingMax PoolingMode = "max"
+)
+
+// Valid reports whether m is a known pooling mode.
+func (m PoolingMode) Valid() bool {
+	return m == PoolingMean || m == PoolingMax
+}
+
+// ChunkOptions configures how over-long embedding texts are split. Word
+// counts stand in for model tokens; most tokenizers produce about 1.3
+// tokens per English word, so the default of 300 words fits 512-token
+// models.
+type ChunkOptions struct {
+	// MaxWords is the most words embedded in one chunk. Zero disables
+	// chunking.
+	MaxWords int
+	// Overlap is the number of words repeated between adjacent chunks.
+	Overlap int
+	// Pooling combines the chunk vectors of a text.
+	Pooling PoolingMode
+	// DryRun embeds texts whole and only logs those that exceed MaxWords
+	// and would be truncated by the model.
+	DryRun bool
+}
+
+// DefaultChunkOptions returns sensible defaults.
+func DefaultChunkOptions() ChunkOptions {
+	return ChunkOptions{
+		MaxWords: 300,
+		Overlap:  30,
+		Pooling:  PoolingMean,
+	}
+}
+
+// Validate reports whether o describes a usable chunking: MaxWords must not
+// be negative, and when chunking is enabled Overlap must be at least zero
+// and below MaxWords so consecutive chunks advance.
+func (o ChunkOptions) Validate() error {
+	if !o.Pooling.Valid() {
+		return fmt.Errorf("unknown pooling mode %q", o.Pooling)
+	}
+	if o.MaxWords < 0 {
+		return fmt.Errorf("chunk size must not be negative, got %d", o.MaxWords)
+	}
+	if o.MaxWords > 0 && (o.Overlap < 0 || o.Overlap >= o.MaxWords) {
+		return fmt.Errorf("chunk overlap must be in [0, %d), got %d", o.MaxWords, o.Overlap)
+	}
+	return nil
+}
+
+// embedTextInput is a text to embed with a label for reporting.
+type embedTextInput struct {
+	// agentID is the agent the text belongs to.
+	agentID string
+	// label names the vector, e.g. "profile" or "skill:translate".
+	label string
+	// text is the text to embed.
+	text string
+}
+
+// embedTexts embeds inputs with a single embedder call, splitting texts
+// longer than the chunk limit and pooling their chunk vectors. It returns
+// one vector per input.
+func (s *RegistryService) embedTexts(ctx context.Context, inputs []embedTextInput) ([][]float32, error) {
+	var chunks []string
+	spans := make([]int, len(inputs))
+	for i, in := range inputs {
+		parts := []string{in.text}
+		if words := len(strings.Fields(in.text)); s.chunking.MaxWords > 0 && words > s.chunking.MaxWords {
+			if s.chunking.DryRun {
+				s.logger.WarnContext(ctx, "embedding text exceeds chunk limit and may be truncated",
+					"agent_id", in.agentID, "vector", in.label,
+					"words", words, "max_words", s.chunking.MaxWords)
+			} else {
+				parts = splitChunks(in.text, s.chunking.MaxWords, s.chunking.Overlap)
+			}
+		}
+		chunks = append(chunks, parts...)
+		spans[i] = len(parts)
+	}
+
+	embeddings, err := s.embedder.Embed(embedding.ContextWithMode(ctx, embedding.ModeDocument), chunks)
+	if err != nil {
+		return nil, fmt.Errorf("generate embedding: %w", err)
+	}
+	if len(embeddings) != len(chunks) {
+		return nil, fmt.Errorf("generate embedding: got %d vectors for %d texts", len(embeddings), len(chunks))
+	}
+
+	vectors := make([][]float32, len(inputs))
+	next := 0
+	for i, n := range spans {
+		if n == 1 {
+			vectors[i] = embeddings[next]
+		} else {
+			vectors[i] = poolVectors(embeddings[next:next+n], s.chunking.Pooling)
+		}
+		next += n
+	}
+	return vectors, nil
+}
+
+// splitChunks splits text into windows of at most maxWords words, with
+// overlap words shared between neighbours. The caller ensures maxWords > 0
+// and 0 <= overlap < maxWords (see ChunkOptions.Validate).
+func splitChunks(text string, maxWords, overlap int) []string {
+	words := strings.Fields(text)
+	if len(words) <= maxWords {
+		return []string{text}
+	}
+	step := maxWords - overlap
+
+	var chunks []string
+	for start := 0; start < len(words); start += step {
+		end := min(start+maxWords, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+	}
+	return chunks
+}
+
+// poolVectors combines vectors with mode and L2-normalizes the result.
+func poolVectors(vectors [][]float32, mode PoolingMode) []float32 {
+	pooled := make([]float32, len(vectors[0]))
+	if mode == PoolingMax {
+		copy(pooled, vectors[0])
+		for _, v := range vectors[1:] {
+			for j := range pooled {
+				pooled[j] = max(pooled[j], v[j])
+			}
+		}
+	} else {
+		for _, v := range vectors {
+			for j := range pooled {
+				pooled[j] += v[j] / float32(len(vectors))
+			}
+		}
+	}
+
+	var norm float64
+	for _, x := range pooled {
+		norm += float64(x) * float64(x)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for j := range pooled {
+			pooled[j] *= scale
+		}
+	}
+	return pooled
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+func TestSplitChunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		words    int
+		maxWords int
+		overlap  int
+		want     []string
+	}{
+		{name: "short text is one chunk", words: 3, maxWords: 5, overlap: 1, want: []string{"w0 w1 w2"}},
+		{name: "windows overlap", words: 7, maxWords: 4, overlap: 1, want: []string{"w0 w1 w2 w3", "w3 w4 w5 w6"}},
+		{name: "no overlap", words: 5, maxWords: 2, want: []string{"w0 w1", "w2 w3", "w4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			words := make([]string, tt.words)
+			for i := range words {
+				words[i] = "w" + string(rune('0'+i))
+			}
+
+			got := splitChunks(strings.Join(words, " "), tt.maxWords, tt.overlap)
+
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitChunks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    ChunkOptions
+		wantErr bool
+	}{
+		{name: "defaults", opts: DefaultChunkOptions()},
+		{name: "disabled ignores overlap", opts: ChunkOptions{Overlap: 30, Pooling: PoolingMax}},
+		{name: "unknown pooling", opts: ChunkOptions{MaxWords: 10, Pooling: "sum"}, wantErr: true},
+		{name: "negative size", opts: ChunkOptions{MaxWords: -1, Pooling: PoolingMean}, wantErr: true},
+		{name: "negative overlap", opts: ChunkOptions{MaxWords: 10, Overlap: -1, Pooling: PoolingMean}, wantErr: true},
+		{name: "overlap equals size", opts: ChunkOptions{MaxWords: 10, Overlap: 10, Pooling: PoolingMean}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPoolVectors(t *testing.T) {
+	t.Parallel()
+	vectors := [][]float32{{3, 0}, {1, 4}}
+
+	tests := []struct {
+		mode PoolingMode
+		want []float32
+	}{
+		{mode: PoolingMean, want: []float32{2.0 / 2.8284271, 2.0 / 2.8284271}},
+		{mode: PoolingMax, want: []float32{0.6, 0.8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			t.Parallel()
+			got := poolVectors(vectors, tt.mode)
+			for i := range got {
+				if diff := got[i] - tt.want[i]; diff > 1e-5 || diff < -1e-5 {
+					t.Errorf("poolVectors() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryService_Create_Chunking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		chunking  ChunkOptions
+		wantTexts int
+	}{
+		{name: "long profile is chunked", chunking: ChunkOptions{MaxWords: 10, Overlap: 2, Pooling: PoolingMean}, wantTexts: 5},
+		{name: "dry run embeds whole text", chunking: ChunkOptions{MaxWords: 10, Pooling: PoolingMean, DryRun: true}, wantTexts: 3},
+		{name: "chunking disabled", chunking: ChunkOptions{Pooling: PoolingMax}, wantTexts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			embedder := &fakeEmbedder{}
+			svc := NewRegistryService(store.NewMemoryStore(),
+				WithEmbedder(embedder),
+				WithChunking(tt.chunking),
+			)
+			input := validCreateInput()
+			input.Card.Description = strings.Repeat("detailed description ", 8)
+
+			agent, err := svc.Create(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			// The 20-word profile (three chunks when split), one skill and tags.
+			if len(embedder.texts) != tt.wantTexts {
+				t.Errorf("Embed() called with %d texts, want %d", len(embedder.texts), tt.wantTexts)
+			}
+			if len(agent.Embedding) != 1 || len(agent.SkillEmbeddings) != 1 {
+				t.Errorf("Create() vectors = %v, %v, want one pooled profile and one skill vector",
+					agent.Embedding, agent.SkillEmbeddings)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// lexicalPageSize is the page size used to scan agents for lexical search.
+const lexicalPageSize = 100
+
+// Field weights for lexical scoring. A query term scores the weight of the
+// strongest field it appears in.
+const (
+	weightName        = 3
+	weightSkillName   = 2
+	weightTag         = 2
+	weightDescription = 1
+	maxLexicalWeight  = weightName
+)
+
+// lexicalSearch scores agents matching filter by keyword overlap with query.
+// It is the fallback when no query embedding is available.
+func (s *RegistryService) lexicalSearch(ctx context.Context, query string, limit int, filter store.AgentFilter) (*store.SearchResult, error) {
+	terms := tokenize(query)
+
+	var scored []store.ScoredAgent
+	filter.Limit = lexicalPageSize
+	for filter.Offset = 0; ; filter.Offset += lexicalPageSize {
+		page, err := s.store.ListAgents(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("list agents: %w", err)
+		}
+		for _, agent := range page.Agents {
+			if score := lexicalScore(terms, agent); score > 0 {
+				scored = append(scored, store.ScoredAgent{Agent: agent, Score: score})
+			}
+		}
+		if len(page.Agents) < lexicalPageSize || filter.Offset+len(page.Agents) >= page.Total {
+			break
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	return &store.SearchResult{Agents: scored, Lexical: true}, nil
+}
+
+// lexicalField is a tokenized agent field with its scoring weight.
+type lexicalField struct {
+	// tokens are the field's normalized terms.
+	tokens []string
+	// weight is the score contributed by a matching term.
+	weight int
+}
+
+// lexicalScore returns the weighted fraction of query terms found in the
+// agent's name, description, skill names and tags, in the range 0-1.
+func lexicalScore(terms []string, agent *store.RegisteredAgent) float32 {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	fields := []lexicalField{
+		{tokens: tokenize(agent.Card.Name), weight: weightName},
+		{tokens: tokenize(agent.Card.Description), weight: weightDescription},
+		{tokens: tokenize(strings.Join(agent.Tags, " ")), weight: weightTag},
+	}
+	for _, skill := range agent.Card.Skills {
+		fields = append(fields, lexicalField{tokens: tokenize(skill.Name), weight: weightSkillName})
+	}
+
+	total := 0
+	for _, term := range terms {
+		best := 0
+		for _, f := range fields {
+			if f.weight > best && containsTerm(f.tokens, term) {
+				best = f.weight
+			}
+		}
+		total += best
+	}
+
+	return float32(total) / float32(len(terms)*maxLexicalWeight)
+}
+
+// containsTerm reports whether tokens contain term, treating a shared
+// prefix of at least four characters as a match so that "translate"
+// matches "translation".
+func containsTerm(tokens []string, term string) bool {
+	for _, tok := range tokens {
+		if tok == term {
+			return true
+		}
+		if len(tok) >= 4 && len(term) >= 4 && (strings.HasPrefix(tok, term) || strings.HasPrefix(term, tok)) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize lowercases s and splits it into letter and digit runs,
+// dropping single-character tokens.
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := fields[:0]
+	for _, f := range fields {
+		if len(f) > 1 {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
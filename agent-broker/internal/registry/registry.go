@@ -2,7 +2,9 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"regexp"
 	"strings"
 	"time"
@@ -11,22 +13,63 @@ import (
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
 	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/rerank"
 )
 
 var agentIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
+// ErrInvalidAgent is returned when an agent ID or card fails validation.
+var ErrInvalidAgent = errors.New("invalid agent")
+
+// MaxBatchSize is the maximum number of items in a batch operation.
+const MaxBatchSize = 100
+
 // RegistryService manages agent registrations.
 type RegistryService struct {
 	// store is the agent storage backend.
 	store store.Store
 	// embedder generates embeddings for agents (optional).
 	embedder embedding.Embedder
+	// logger reports degraded discovery.
+	logger *slog.Logger
+	// template renders the agent profile text to embed.
+	template *EmbeddingTemplate
+	// chunking controls splitting of over-long embedding texts.
+	chunking ChunkOptions
+	// model is the ID of the embedding model recorded with vectors.
+	model string
+	// version identifies the embedding template and instruction prefixes
+	// recorded with vectors.
+	version string
+	// reranker re-scores discovery candidates (optional).
+	reranker rerank.Reranker
+	// rerankCandidates is how many candidates are fetched for reranking.
+	rerankCandidates int
 }
 
 // Options configures the RegistryService.
 type Options struct {
 	// Embedder generates embeddings for agents.
 	Embedder embedding.Embedder
+	// Logger is the logger for registry events.
+	Logger *slog.Logger
+	// EmbeddingTemplate renders the agent profile text to embed.
+	EmbeddingTemplate *EmbeddingTemplate
+	// Chunking controls splitting of over-long embedding texts.
+	Chunking ChunkOptions
+	// EmbeddingModel is the model ID recorded with vectors. When empty, it
+	// is taken from the embedder if it reports one.
+	EmbeddingModel string
+	// EmbeddingPrefixes are the embedder's instruction prefixes, folded into
+	// the recorded embedding version. When zero, they are taken from the
+	// embedder if it reports them.
+	EmbeddingPrefixes embedding.Prefixes
+	// Reranker re-scores discovery candidates after the first-stage search.
+	// Nil keeps the first-stage order.
+	Reranker rerank.Reranker
+	// RerankCandidates is how many first-stage candidates are fetched for
+	// reranking. Discover never fetches fewer than its limit.
+	RerankCandidates int
 }
 
 // Option is a functional option for RegistryService.
@@ -39,16 +82,85 @@ func WithEmbedder(e embedding.Embedder) Option {
 	}
 }
 
+// WithLogger sets the logger for registry events.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithEmbeddingTemplate sets the template for the agent profile text.
+func WithEmbeddingTemplate(t *EmbeddingTemplate) Option {
+	return func(o *Options) {
+		o.EmbeddingTemplate = t
+	}
+}
+
+// WithChunking sets how over-long embedding texts are split and pooled.
+func WithChunking(opts ChunkOptions) Option {
+	return func(o *Options) {
+		o.Chunking = opts
+	}
+}
+
+// WithEmbeddingModel sets the model ID recorded with vectors.
+func WithEmbeddingModel(model string) Option {
+	return func(o *Options) {
+		o.EmbeddingModel = model
+	}
+}
+
+// WithEmbeddingPrefixes sets the instruction prefixes recorded with vectors.
+func WithEmbeddingPrefixes(prefixes embedding.Prefixes) Option {
+	return func(o *Options) {
+		o.EmbeddingPrefixes = prefixes
+	}
+}
+
+// WithReranker sets the second-stage reranker for discovery.
+func WithReranker(r rerank.Reranker) Option {
+	return func(o *Options) {
+		o.Reranker = r
+	}
+}
+
+// WithRerankCandidates sets how many candidates are fetched for reranking.
+func WithRerankCandidates(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.RerankCandidates = n
+		}
+	}
+}
+
 // NewRegistryService creates a new registry service.
 func NewRegistryService(s store.Store, opts ...Option) *RegistryService {
-	var options Options
+	options := Options{
+		Logger:            slog.Default(),
+		EmbeddingTemplate: MustParseEmbeddingTemplate(DefaultEmbeddingTemplate),
+		Chunking:          DefaultChunkOptions(),
+		RerankCandidates:  20,
+	}
 	for _, opt := range opts {
 		opt(&options)
 	}
+	if m, ok := options.Embedder.(embedding.ModelReporter); ok && options.EmbeddingModel == "" {
+		options.EmbeddingModel = m.Model()
+	}
+	if p, ok := options.Embedder.(embedding.PrefixReporter); ok && options.EmbeddingPrefixes.IsZero() {
+		options.EmbeddingPrefixes = p.Prefixes()
+	}
 
 	return &RegistryService{
-		store:    s,
-		embedder: options.Embedder,
+		store:            s,
+		embedder:         options.Embedder,
+		logger:           options.Logger,
+		template:         options.EmbeddingTemplate,
+		chunking:         options.Chunking,
+		model:            options.EmbeddingModel,
+		version:          embeddingVersion(options.EmbeddingTemplate, options.EmbeddingPrefixes),
+		reranker:         options.Reranker,
+		rerankCandidates: options.RerankCandidates,
 	}
 }
 
@@ -71,15 +183,9 @@ func (s *RegistryService) Create(ctx context.Context, input CreateInput) (*store
 		return nil, err
 	}
 
-	var emb []float32
-	if s.embedder != nil {
-		embeddings, err := s.embedder.Embed(ctx, []string{buildEmbeddingText(input.Card)})
-		if err != nil {
-			return nil, fmt.Errorf("generate embedding: %w", err)
-		}
-		if len(embeddings) > 0 {
-			emb = embeddings[0]
-		}
+	vectors, err := s.embedAgent(ctx, input.ID, input.Card, input.Tags)
+	if err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
@@ -87,10 +193,10 @@ func (s *RegistryService) Create(ctx context.Context, input CreateInput) (*store
 		ID:        input.ID,
 		Card:      input.Card,
 		Tags:      input.Tags,
-		Embedding: emb,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	vectors.apply(agent)
 
 	if err := s.store.CreateAgent(ctx, agent); err != nil {
 		return nil, err
@@ -116,6 +222,8 @@ type ListInput struct {
 	Skills []string
 	// Query searches name/description.
 	Query string
+	// Filter is an optional filter expression in store.ParseFilter syntax.
+	Filter string
 }
 
 // List returns agents matching the criteria.
@@ -130,12 +238,18 @@ func (s *RegistryService) List(ctx context.Context, input ListInput) (*store.Age
 		input.Offset = 0
 	}
 
+	expr, err := store.ParseFilter(input.Filter)
+	if err != nil {
+		return nil, err
+	}
+
 	return s.store.ListAgents(ctx, store.AgentFilter{
 		Offset: input.Offset,
 		Limit:  input.Limit,
 		Tags:   input.Tags,
 		Skills: input.Skills,
 		Query:  input.Query,
+		Expr:   expr,
 	})
 }
 
@@ -160,21 +274,15 @@ func (s *RegistryService) Update(ctx context.Context, input UpdateInput) (*store
 		return nil, err
 	}
 
-	var emb []float32
-	if s.embedder != nil {
-		embeddings, err := s.embedder.Embed(ctx, []string{buildEmbeddingText(input.Card)})
-		if err != nil {
-			return nil, fmt.Errorf("generate embedding: %w", err)
-		}
-		if len(embeddings) > 0 {
-			emb = embeddings[0]
-		}
+	vectors, err := s.embedAgent(ctx, input.ID, input.Card, input.Tags)
+	if err != nil {
+		return nil, err
 	}
 
 	existing.Card = input.Card
 	existing.Tags = input.Tags
-	existing.Embedding = emb
 	existing.UpdatedAt = time.Now()
+	vectors.apply(existing)
 
 	if err := s.store.UpdateAgent(ctx, existing); err != nil {
 		return nil, err
@@ -188,6 +296,99 @@ func (s *RegistryService) Delete(ctx context.Context, id string) error {
 	return s.store.DeleteAgent(ctx, id)
 }
 
+// BatchResult is the outcome of a single item in a batch operation.
+type BatchResult struct {
+	// ID is the agent ID the result refers to.
+	ID string
+	// Agent is the stored agent for successful upserts.
+	Agent *store.RegisteredAgent
+	// Created is true when an upsert inserted a new agent.
+	Created bool
+	// Err is the per-item error, if any.
+	Err error
+}
+
+// BatchUpsert creates or replaces agents with a single embedding call and
+// a single store write. Invalid items are reported per item and skipped.
+func (s *RegistryService) BatchUpsert(ctx context.Context, inputs []CreateInput) ([]BatchResult, error) {
+	if len(inputs) > MaxBatchSize {
+		return nil, fmt.Errorf("batch size must be at most %d", MaxBatchSize)
+	}
+
+	results := make([]BatchResult, len(inputs))
+	valid := make([]int, 0, len(inputs))
+	for i, input := range inputs {
+		results[i].ID = input.ID
+		if err := validateAgentID(input.ID); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := ValidateAgentCard(input.Card); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	docs := make([]agentDocument, len(valid))
+	for j, i := range valid {
+		docs[j] = agentDocument{id: inputs[i].ID, card: inputs[i].Card, tags: inputs[i].Tags}
+	}
+	vectors, err := s.embedAgents(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	agents := make([]*store.RegisteredAgent, len(valid))
+	for j, i := range valid {
+		agents[j] = &store.RegisteredAgent{
+			ID:        inputs[i].ID,
+			Card:      inputs[i].Card,
+			Tags:      inputs[i].Tags,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		vectors[j].apply(agents[j])
+	}
+
+	stored, err := s.store.UpsertAgents(ctx, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range valid {
+		results[i].Agent = agents[j]
+		results[i].Created = stored[j].Created
+		results[i].Err = stored[j].Err
+	}
+
+	return results, nil
+}
+
+// BatchDelete removes agents with a single store write.
+func (s *RegistryService) BatchDelete(ctx context.Context, ids []string) ([]BatchResult, error) {
+	if len(ids) > MaxBatchSize {
+		return nil, fmt.Errorf("batch size must be at most %d", MaxBatchSize)
+	}
+
+	deleted, err := s.store.DeleteAgents(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(deleted))
+	for i, d := range deleted {
+		results[i] = BatchResult{ID: d.ID, Err: d.Err}
+	}
+
+	return results, nil
+}
+
 // DiscoverInput contains input for agent discovery.
 type DiscoverInput struct {
 	// Query is the natural language search query.
@@ -198,9 +399,14 @@ type DiscoverInput struct {
 	Tags []string
 	// Skills filters by any matching skill ID.
 	Skills []string
+	// Filter is an optional filter expression in store.ParseFilter syntax.
+	Filter string
 }
 
-// Discover finds agents by semantic similarity.
+// Discover finds agents by semantic similarity. When no embedder is
+// configured or embedding fails, it falls back to keyword scoring and
+// marks the result as lexical. With a reranker, a larger candidate set is
+// fetched and reordered by reranker score.
 func (s *RegistryService) Discover(ctx context.Context, input DiscoverInput) (*store.SearchResult, error) {
 	if input.Limit <= 0 {
 		input.Limit = 10
@@ -209,22 +415,47 @@ func (s *RegistryService) Discover(ctx context.Context, input DiscoverInput) (*s
 		input.Limit = 50
 	}
 
-	if s.embedder == nil {
-		return nil, fmt.Errorf("embedder not configured")
+	expr, err := store.ParseFilter(input.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := store.AgentFilter{
+		Tags:   input.Tags,
+		Skills: input.Skills,
+		Expr:   expr,
 	}
 
-	embeddings, err := s.embedder.Embed(ctx, []string{input.Query})
+	if s.reranker == nil {
+		return s.search(ctx, input.Query, input.Limit, filter)
+	}
+	result, err := s.search(ctx, input.Query, max(input.Limit, s.rerankCandidates), filter)
 	if err != nil {
-		return nil, fmt.Errorf("generate embedding: %w", err)
+		return nil, err
 	}
-	if len(embeddings) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
+	return s.rerank(ctx, input.Query, input.Limit, result)
+}
+
+// search runs the first-stage vector search, falling back to lexical
+// search when embedding is unavailable.
+func (s *RegistryService) search(ctx context.Context, query string, limit int, filter store.AgentFilter) (*store.SearchResult, error) {
+	if s.embedder == nil {
+		return s.lexicalSearch(ctx, query, limit, filter)
 	}
 
-	return s.store.SearchAgents(ctx, embeddings[0], input.Limit, store.AgentFilter{
-		Tags:   input.Tags,
-		Skills: input.Skills,
-	})
+	embeddings, err := s.embedder.Embed(embedding.ContextWithMode(ctx, embedding.ModeQuery), []string{query})
+	if err == nil && len(embeddings) == 0 {
+		err = fmt.Errorf("no embedding returned")
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("generate embedding: %w", err)
+		}
+		s.logger.WarnContext(ctx, "embedding failed, falling back to lexical search", "error", err)
+		return s.lexicalSearch(ctx, query, limit, filter)
+	}
+
+	return s.store.SearchAgents(ctx, embeddings[0], limit, filter)
 }
 
 // ValidateAgentCard validates required fields in an AgentCard.
@@ -254,36 +485,20 @@ func ValidateAgentCard(card a2a.AgentCard) error {
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("invalid agent card: %s", strings.Join(errs, ", "))
+		return fmt.Errorf("%w card: %s", ErrInvalidAgent, strings.Join(errs, ", "))
 	}
 	return nil
 }
 
 func validateAgentID(id string) error {
 	if id == "" {
-		return fmt.Errorf("agent_id is required")
+		return fmt.Errorf("%w: agent_id is required", ErrInvalidAgent)
 	}
 	if len(id) > 64 {
-		return fmt.Errorf("agent_id must be at most 64 characters")
+		return fmt.Errorf("%w: agent_id must be at most 64 characters", ErrInvalidAgent)
 	}
 	if !agentIDPattern.MatchString(id) {
-		return fmt.Errorf("agent_id must match pattern ^[a-zA-Z0-9_-]+$")
+		return fmt.Errorf("%w: agent_id must match pattern ^[a-zA-Z0-9_-]+$", ErrInvalidAgent)
 	}
 	return nil
 }
-
-// buildEmbeddingText constructs the text to embed from an agent card.
-func buildEmbeddingText(card a2a.AgentCard) string {
-	var parts []string
-	parts = append(parts, card.Name)
-	if card.Description != "" {
-		parts = append(parts, card.Description)
-	}
-	for _, skill := range card.Skills {
-		parts = append(parts, skill.Name)
-		if skill.Description != "" {
-			parts = append(parts, skill.Description)
-		}
-	}
-	return strings.Join(parts, " ")
-}
@@ -2,12 +2,15 @@ package registry
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/a2aproject/a2a-go/a2a"
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
 )
 
 func validAgentCard() a2a.AgentCard {
@@ -357,3 +360,463 @@ func TestRegistryService_Delete_NotFound(t *testing.T) {
 		t.Errorf("Delete() error = %v, want ErrNotFound", err)
 	}
 }
+
+func TestRegistryService_BatchUpsert(t *testing.T) {
+	t.Parallel()
+	s := store.NewMemoryStore()
+	svc := NewRegistryService(s)
+	_, _ = svc.Create(context.Background(), validCreateInput())
+
+	updated := validCreateInput()
+	updated.Card.Name = "Updated Name"
+	created := validCreateInput()
+	created.ID = "new-agent"
+	invalid := validCreateInput()
+	invalid.ID = "invalid-agent"
+	invalid.Card.Name = ""
+
+	results, err := svc.BatchUpsert(context.Background(), []CreateInput{updated, created, invalid})
+	if err != nil {
+		t.Fatalf("BatchUpsert() error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("BatchUpsert() got %d results, want 3", len(results))
+	}
+	if results[0].Err != nil || results[0].Created {
+		t.Errorf("BatchUpsert() results[0] = %+v, want updated", results[0])
+	}
+	if results[1].Err != nil || !results[1].Created {
+		t.Errorf("BatchUpsert() results[1] = %+v, want created", results[1])
+	}
+	if results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "name is required") {
+		t.Errorf("BatchUpsert() results[2].Err = %v, want validation error", results[2].Err)
+	}
+
+	agent, _ := svc.Get(context.Background(), "test-agent")
+	if agent.Card.Name != "Updated Name" {
+		t.Errorf("Get() Name = %v, want Updated Name", agent.Card.Name)
+	}
+	if _, err := svc.Get(context.Background(), "invalid-agent"); err != store.ErrNotFound {
+		t.Errorf("Get() invalid-agent error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRegistryService_BatchUpsert_TooLarge(t *testing.T) {
+	t.Parallel()
+	svc := NewRegistryService(store.NewMemoryStore())
+
+	_, err := svc.BatchUpsert(context.Background(), make([]CreateInput, MaxBatchSize+1))
+	if err == nil {
+		t.Error("BatchUpsert() with oversized batch should return error")
+	}
+}
+
+func TestRegistryService_BatchDelete(t *testing.T) {
+	t.Parallel()
+	s := store.NewMemoryStore()
+	svc := NewRegistryService(s)
+	_, _ = svc.Create(context.Background(), validCreateInput())
+
+	results, err := svc.BatchDelete(context.Background(), []string{"test-agent", "not-exists"})
+	if err != nil {
+		t.Fatalf("BatchDelete() error = %v", err)
+	}
+	if results[0].Err != nil {
+		t.Errorf("BatchDelete() results[0].Err = %v, want nil", results[0].Err)
+	}
+	if results[1].Err != store.ErrNotFound {
+		t.Errorf("BatchDelete() results[1].Err = %v, want ErrNotFound", results[1].Err)
+	}
+}
+
+// fakeEmbedder returns a deterministic one-dimensional vector per text.
+type fakeEmbedder struct {
+	// texts records every embedded text.
+	texts []string
+	// modes records the embedding mode of every embedded text.
+	modes []embedding.Mode
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		e.texts = append(e.texts, text)
+		e.modes = append(e.modes, embedding.ModeFromContext(ctx))
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func (e *fakeEmbedder) Dimensions() int { return 1 }
+
+func TestRegistryService_Create_NamedVectors(t *testing.T) {
+	t.Parallel()
+	embedder := &fakeEmbedder{}
+	svc := NewRegistryService(store.NewMemoryStore(), WithEmbedder(embedder))
+	input := validCreateInput()
+	input.Card.Skills = append(input.Card.Skills, a2a.AgentSkill{
+		ID:          "skill-2",
+		Name:        "Skill Two",
+		Description: "Does the second thing",
+		Examples:    []string{"do the second thing"},
+	})
+
+	agent, err := svc.Create(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if len(embedder.texts) != 4 {
+		t.Fatalf("Embed() called with %d texts, want 4", len(embedder.texts))
+	}
+	if len(agent.Embedding) == 0 {
+		t.Error("Create() Embedding should be set")
+	}
+	if len(agent.SkillEmbeddings) != 2 {
+		t.Fatalf("Create() SkillEmbeddings = %d, want 2", len(agent.SkillEmbeddings))
+	}
+	if agent.SkillEmbeddings[1].SkillID != "skill-2" {
+		t.Errorf("Create() SkillEmbeddings[1].SkillID = %q, want skill-2", agent.SkillEmbeddings[1].SkillID)
+	}
+	if !strings.Contains(embedder.texts[2], "do the second thing") {
+		t.Errorf("skill text = %q, want examples included", embedder.texts[2])
+	}
+	if len(agent.TagsEmbedding) == 0 {
+		t.Error("Create() TagsEmbedding should be set")
+	}
+}
+
+func TestRegistryService_BatchUpsert_BlankTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tags [][]string
+	}{
+		{name: "blank tags before a tagged agent", tags: [][]string{{""}, {"finance"}}},
+		{name: "whitespace tags before a tagged agent", tags: [][]string{{" "}, {"finance"}}},
+		{name: "blank tags on the last agent", tags: [][]string{{"finance"}, {" ", ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := NewRegistryService(store.NewMemoryStore(), WithEmbedder(&fakeEmbedder{}))
+
+			inputs := make([]CreateInput, len(tt.tags))
+			for i, tags := range tt.tags {
+				inputs[i] = validCreateInput()
+				inputs[i].ID = fmt.Sprintf("agent-%d", i)
+				inputs[i].Tags = tags
+			}
+			results, err := svc.BatchUpsert(context.Background(), inputs)
+			if err != nil {
+				t.Fatalf("BatchUpsert() error = %v", err)
+			}
+
+			for i, result := range results {
+				if result.Err != nil {
+					t.Fatalf("BatchUpsert() results[%d].Err = %v", i, result.Err)
+				}
+				texts, err := buildTexts(svc.template, agentDocument{id: inputs[i].ID, card: inputs[i].Card, tags: inputs[i].Tags})
+				if err != nil {
+					t.Fatalf("buildTexts() error = %v", err)
+				}
+				// fakeEmbedder encodes each text's length, so a shifted
+				// vector shows up as the wrong length.
+				if got, want := result.Agent.Embedding[0], float32(len(texts.Profile)); got != want {
+					t.Errorf("agent %d profile vector = %v, want %v", i, got, want)
+				}
+				if texts.Tags == "" {
+					if result.Agent.TagsEmbedding != nil {
+						t.Errorf("agent %d TagsEmbedding = %v, want nil for blank tags", i, result.Agent.TagsEmbedding)
+					}
+				} else if len(result.Agent.TagsEmbedding) == 0 || result.Agent.TagsEmbedding[0] != float32(len(texts.Tags)) {
+					t.Errorf("agent %d TagsEmbedding = %v, want the vector of %q", i, result.Agent.TagsEmbedding, texts.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryService_EmbeddingModes(t *testing.T) {
+	t.Parallel()
+	embedder := &fakeEmbedder{}
+	svc := NewRegistryService(store.NewMemoryStore(), WithEmbedder(embedder))
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, validCreateInput()); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	documents := len(embedder.modes)
+	if _, err := svc.Discover(ctx, DiscoverInput{Query: "test", Limit: 5}); err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	for i, mode := range embedder.modes[:documents] {
+		if mode != embedding.ModeDocument {
+			t.Errorf("Create() text %d embedded as %s, want document", i, mode)
+		}
+	}
+	if got := embedder.modes[documents:]; len(got) != 1 || got[0] != embedding.ModeQuery {
+		t.Errorf("Discover() modes = %v, want [query]", got)
+	}
+}
+
+// failingEmbedder always returns an error, simulating an unavailable service.
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(context.Context, []string) ([][]float32, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingEmbedder) Dimensions() int { return 1 }
+
+func TestRegistryService_Discover_LexicalFallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "no embedder configured"},
+		{name: "embedder unavailable", opts: []Option{WithEmbedder(failingEmbedder{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := store.NewMemoryStore()
+			ctx := context.Background()
+
+			translator := validCreateInput()
+			translator.ID = "translator"
+			translator.Card.Name = "Translator"
+			translator.Card.Skills = []a2a.AgentSkill{{ID: "translate", Name: "Translate documents"}}
+			billing := validCreateInput()
+			billing.ID = "billing"
+			billing.Card.Name = "Billing Assistant"
+			billing.Card.Description = "Answers invoice questions"
+			billing.Tags = []string{"finance"}
+			_, _ = NewRegistryService(s).BatchUpsert(ctx, []CreateInput{translator, billing})
+
+			svc := NewRegistryService(s, tt.opts...)
+			result, err := svc.Discover(ctx, DiscoverInput{Query: "translation of documents"})
+
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if !result.Lexical {
+				t.Error("Discover() Lexical = false, want true")
+			}
+			if len(result.Agents) != 1 || result.Agents[0].Agent.ID != "translator" {
+				t.Fatalf("Discover() agents = %v, want [translator]", result.Agents)
+			}
+
+			result, _ = svc.Discover(ctx, DiscoverInput{Query: "finance invoice", Filter: "tags = finance"})
+			if len(result.Agents) != 1 || result.Agents[0].Agent.ID != "billing" {
+				t.Errorf("Discover() with filter agents = %v, want [billing]", result.Agents)
+			}
+		})
+	}
+}
+
+func TestLexicalScore(t *testing.T) {
+	t.Parallel()
+
+	agent := &store.RegisteredAgent{
+		Card: a2a.AgentCard{
+			Name:        "Billing Assistant",
+			Description: "Answers invoice questions",
+			Skills:      []a2a.AgentSkill{{ID: "refunds", Name: "Process refunds"}},
+		},
+		Tags: []string{"finance"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  float32
+	}{
+		{name: "name match", query: "billing", want: 1},
+		{name: "skill name match", query: "refund", want: 2.0 / 3},
+		{name: "tag match", query: "finance", want: 2.0 / 3},
+		{name: "description match", query: "invoices", want: 1.0 / 3},
+		{name: "partial match", query: "billing weather", want: 0.5},
+		{name: "no match", query: "weather", want: 0},
+		{name: "empty query", query: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := lexicalScore(tokenize(tt.query), agent); got != tt.want {
+				t.Errorf("lexicalScore(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// keywordReranker scores documents containing keyword 1 and others 0,
+// recording how many candidates it was given.
+type keywordReranker struct {
+	keyword    string
+	err        error
+	candidates int
+}
+
+func (r *keywordReranker) Rerank(_ context.Context, _ string, documents []string) ([]float32, error) {
+	r.candidates = len(documents)
+	if r.err != nil {
+		return nil, r.err
+	}
+	scores := make([]float32, len(documents))
+	for i, doc := range documents {
+		if strings.Contains(doc, r.keyword) {
+			scores[i] = 1
+		}
+	}
+	return scores, nil
+}
+
+func TestRegistryService_Discover_Rerank(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		reranker       *keywordReranker
+		candidates     int
+		wantAgent      string
+		wantReranked   bool
+		wantCandidates int
+	}{
+		{
+			name:           "reranker reorders candidates",
+			reranker:       &keywordReranker{keyword: "invoice"},
+			candidates:     20,
+			wantAgent:      "billing",
+			wantReranked:   true,
+			wantCandidates: 3,
+		},
+		{
+			name:           "candidate count bounds the reranked set",
+			reranker:       &keywordReranker{keyword: "invoice"},
+			candidates:     1,
+			wantAgent:      "translator",
+			wantReranked:   true,
+			wantCandidates: 1,
+		},
+		{
+			name:           "reranker failure keeps first-stage order",
+			reranker:       &keywordReranker{err: errors.New("connection refused")},
+			candidates:     20,
+			wantAgent:      "translator",
+			wantCandidates: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			embedder, err := embedding.NewLocalEmbedder(64)
+			if err != nil {
+				t.Fatalf("NewLocalEmbedder() error = %v", err)
+			}
+
+			var inputs []CreateInput
+			for _, a := range []struct{ id, name, description string }{
+				{"translator", "Translator", "Translates documents between languages"},
+				{"billing", "Billing Assistant", "Answers invoice questions about translated documents"},
+				{"weather", "Weather", "Reports the weather forecast"},
+			} {
+				input := validCreateInput()
+				input.ID, input.Card.Name, input.Card.Description = a.id, a.name, a.description
+				inputs = append(inputs, input)
+			}
+			s := store.NewMemoryStore()
+			if _, err := NewRegistryService(s, WithEmbedder(embedder)).BatchUpsert(ctx, inputs); err != nil {
+				t.Fatalf("BatchUpsert() error = %v", err)
+			}
+
+			svc := NewRegistryService(s,
+				WithEmbedder(embedder),
+				WithReranker(tt.reranker),
+				WithRerankCandidates(tt.candidates),
+			)
+			result, err := svc.Discover(ctx, DiscoverInput{Query: "translator", Limit: 1})
+
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if len(result.Agents) != 1 || result.Agents[0].Agent.ID != tt.wantAgent {
+				t.Fatalf("Discover() agents = %v, want [%s]", result.Agents, tt.wantAgent)
+			}
+			if result.Reranked != tt.wantReranked {
+				t.Errorf("Discover() Reranked = %v, want %v", result.Reranked, tt.wantReranked)
+			}
+			if tt.wantReranked && result.Agents[0].Score == 0 {
+				t.Error("Discover() dropped the first-stage score")
+			}
+			if tt.reranker.candidates != tt.wantCandidates {
+				t.Errorf("reranker got %d candidates, want %d", tt.reranker.candidates, tt.wantCandidates)
+			}
+		})
+	}
+}
+
+func TestRegistryService_Reindex(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("re-embeds agents without vectors or with stale vectors", func(t *testing.T) {
+		t.Parallel()
+		backend := store.NewMemoryStore()
+		legacy := NewRegistryService(backend)
+		input := validCreateInput()
+		input.ID = "no-vectors"
+		if _, err := legacy.Create(ctx, input); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		embedder := &fakeEmbedder{}
+		svc := NewRegistryService(backend, WithEmbedder(embedder))
+		input.ID = "current"
+		if _, err := svc.Create(ctx, input); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		input.ID = "stale"
+		stale, err := svc.Create(ctx, input)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		stale.EmbeddingVersion = "old"
+		_ = backend.UpdateAgent(ctx, stale)
+		embedder.texts = nil
+
+		reindexed, err := svc.Reindex(ctx)
+
+		if err != nil {
+			t.Fatalf("Reindex() error = %v", err)
+		}
+		if reindexed != 2 {
+			t.Errorf("Reindex() = %d, want 2", reindexed)
+		}
+		for _, id := range []string{"no-vectors", "current", "stale"} {
+			agent, _ := backend.GetAgent(ctx, id)
+			if len(agent.Embedding) == 0 || svc.NeedsReembed(agent) {
+				t.Errorf("agent %s has no current vectors after Reindex()", id)
+			}
+		}
+	})
+
+	t.Run("requires an embedder", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		if _, err := svc.Reindex(ctx); !errors.Is(err, ErrNoEmbedder) {
+			t.Errorf("Reindex() error = %v, want ErrNoEmbedder", err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrNoEmbedder is returned by Reindex when no embedder is configured.
+var ErrNoEmbedder = errors.New("no embedder configured")
+
+// Reindex re-embeds the stored agents that have no vectors, such as agents
+// copied from a legacy collection, or whose vectors NeedsReembed reports as
+// stale. Staleness is decided from the stored embedding model and version,
+// so agents are listed without loading their vectors. Vectors are rebuilt
+// from the stored cards and tags, MaxBatchSize agents per embedder call. It
+// returns the number of re-embedded agents.
+func (s *RegistryService) Reindex(ctx context.Context) (int, error) {
+	if s.embedder == nil {
+		return 0, ErrNoEmbedder
+	}
+
+	// List in one call: Qdrant scans the whole collection for every page.
+	all, err := s.store.ListAgents(ctx, store.AgentFilter{Limit: math.MaxInt})
+	if err != nil {
+		return 0, fmt.Errorf("list agents: %w", err)
+	}
+	var stale []*store.RegisteredAgent
+	for _, agent := range all.Agents {
+		if !agent.HasVectors() || s.NeedsReembed(agent) {
+			stale = append(stale, agent)
+		}
+	}
+
+	reindexed := 0
+	for batch := range slices.Chunk(stale, MaxBatchSize) {
+		docs := make([]agentDocument, len(batch))
+		for i, agent := range batch {
+			docs[i] = agentDocument{id: agent.ID, card: agent.Card, tags: agent.Tags}
+		}
+		vectors, err := s.embedAgents(ctx, docs)
+		if err != nil {
+			return reindexed, fmt.Errorf("embed agents: %w", err)
+		}
+		for i, agent := range batch {
+			vectors[i].apply(agent)
+		}
+
+		results, err := s.store.UpsertAgents(ctx, batch)
+		if err != nil {
+			return reindexed, fmt.Errorf("store agents: %w", err)
+		}
+		for _, result := range results {
+			if result.Err == nil {
+				reindexed++
+			}
+		}
+	}
+
+	return reindexed, nil
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// rerank re-scores result's candidates against query with the reranker
+// and keeps the best limit. Candidates are scored on their rendered
+// profile text, the same text their vectors were built from. When the
+// reranker fails, the first-stage order is kept.
+func (s *RegistryService) rerank(ctx context.Context, query string, limit int, result *store.SearchResult) (*store.SearchResult, error) {
+	agents := result.Agents
+	if len(agents) == 0 {
+		return result, nil
+	}
+
+	documents := make([]string, len(agents))
+	var err error
+	for i, scored := range agents {
+		if documents[i], err = s.template.Render(scored.Agent); err != nil {
+			err = fmt.Errorf("render agent %s: %w", scored.Agent.ID, err)
+			break
+		}
+	}
+	var scores []float32
+	if err == nil {
+		scores, err = s.reranker.Rerank(ctx, query, documents)
+	}
+	if err == nil && len(scores) != len(agents) {
+		err = fmt.Errorf("reranker returned %d scores for %d candidates", len(scores), len(agents))
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("rerank agents: %w", err)
+		}
+		s.logger.WarnContext(ctx, "rerank failed, keeping first-stage order", "error", err)
+		if len(agents) > limit {
+			result.Agents = agents[:limit]
+		}
+		return result, nil
+	}
+
+	for i := range agents {
+		agents[i].RerankScore = scores[i]
+	}
+	sort.SliceStable(agents, func(i, j int) bool {
+		return agents[i].RerankScore > agents[j].RerankScore
+	})
+	if len(agents) > limit {
+		agents = agents[:limit]
+	}
+
+	return &store.SearchResult{Agents: agents, Lexical: result.Lexical, Reranked: true}, nil
+}
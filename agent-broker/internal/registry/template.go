@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrInvalidTemplate is returned when an embedding template cannot be
+// parsed or rendered.
+var ErrInvalidTemplate = errors.New("invalid embedding template")
+
+// DefaultEmbeddingTemplate renders the agent name, description and skill
+// names and descriptions.
+const DefaultEmbeddingTemplate = `{{.Card.Name}} {{.Card.Description}}
+{{range .Card.Skills}}{{.Name}} {{.Description}}
+{{end}}`
+
+// EmbeddingTemplate renders the profile text embedded for an agent. It is a
+// text/template executed with the *store.RegisteredAgent as data. Rendered
+// whitespace is collapsed, so templates may span lines freely.
+type EmbeddingTemplate struct {
+	// source is the template text.
+	source string
+	// tmpl is the parsed template.
+	tmpl *template.Template
+	// version is a short hash of source.
+	version string
+}
+
+// templateFuncs are available to embedding templates in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+}
+
+// ParseEmbeddingTemplate parses an embedding text template. The template is
+// test-rendered against a sample agent so that references to unknown fields
+// fail here rather than on the first registration.
+func ParseEmbeddingTemplate(source string) (*EmbeddingTemplate, error) {
+	tmpl, err := template.New("embedding").Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	t := &EmbeddingTemplate{
+		source:  source,
+		tmpl:    tmpl,
+		version: hex.EncodeToString(sum[:8]),
+	}
+
+	sample := &store.RegisteredAgent{
+		ID:   "sample",
+		Card: a2a.AgentCard{Name: "Sample", Skills: []a2a.AgentSkill{{ID: "sample", Name: "Sample"}}},
+		Tags: []string{"sample"},
+	}
+	if _, err := t.Render(sample); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MustParseEmbeddingTemplate is like ParseEmbeddingTemplate but panics on error.
+func MustParseEmbeddingTemplate(source string) *EmbeddingTemplate {
+	t, err := ParseEmbeddingTemplate(source)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Source returns the template text.
+func (t *EmbeddingTemplate) Source() string {
+	return t.source
+}
+
+// Version returns a short hash identifying the template text.
+func (t *EmbeddingTemplate) Version() string {
+	return t.version
+}
+
+// Render executes the template for agent and collapses whitespace.
+func (t *EmbeddingTemplate) Render(agent *store.RegisteredAgent) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, agent); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// defaultTemplateVersion is the version of DefaultEmbeddingTemplate. Agents
+// stored before versioning were embedded with equivalent text.
+var defaultTemplateVersion = MustParseEmbeddingTemplate(DefaultEmbeddingTemplate).Version()
@@ -0,0 +1,165 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+)
+
+func TestParseEmbeddingTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr bool
+	}{
+		{name: "default template", source: DefaultEmbeddingTemplate},
+		{name: "tags and skill examples", source: `{{.Card.Name}} {{join .Tags ", "}} {{range .Card.Skills}}{{join .Examples " "}}{{end}}`},
+		{name: "syntax error", source: "{{.Card.Name", wantErr: true},
+		{name: "unknown field", source: "{{.Card.Owner}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseEmbeddingTemplate(tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEmbeddingTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTemplate) {
+				t.Errorf("ParseEmbeddingTemplate() error = %v, want ErrInvalidTemplate", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddingTemplate_Render(t *testing.T) {
+	t.Parallel()
+	agent := &store.RegisteredAgent{
+		ID: "translator",
+		Card: a2a.AgentCard{
+			Name:        "Translator",
+			Description: "Translates text",
+			Provider:    &a2a.AgentProvider{Org: "Acme"},
+			Skills: []a2a.AgentSkill{
+				{ID: "translate", Name: "Translate", Description: "Translate between languages", Examples: []string{"translate hello"}},
+				{ID: "detect", Name: "Detect"},
+			},
+		},
+		Tags: []string{"nlp", "language"},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "default template",
+			source: DefaultEmbeddingTemplate,
+			want:   "Translator Translates text Translate Translate between languages Detect",
+		},
+		{
+			name: "custom template",
+			source: `{{.Card.Name}} by {{with .Card.Provider}}{{.Org}}{{end}}
+tags: {{join .Tags ", "}}
+{{range .Card.Skills}}{{range .Examples}}{{.}}{{end}}{{end}}`,
+			want: "Translator by Acme tags: nlp, language translate hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := MustParseEmbeddingTemplate(tt.source).Render(agent)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryService_NeedsReembed(t *testing.T) {
+	t.Parallel()
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	original := NewRegistryService(s,
+		WithEmbedder(&fakeEmbedder{}),
+		WithEmbeddingModel("BAAI/bge-small-en-v1.5"),
+	)
+
+	agent, err := original.Create(ctx, validCreateInput())
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if agent.EmbeddingVersion == "" || agent.EmbeddingModel != "BAAI/bge-small-en-v1.5" {
+		t.Fatalf("Create() EmbeddingVersion = %q, EmbeddingModel = %q, want both set",
+			agent.EmbeddingVersion, agent.EmbeddingModel)
+	}
+
+	changed := NewRegistryService(s,
+		WithEmbedder(&fakeEmbedder{}),
+		WithEmbeddingTemplate(MustParseEmbeddingTemplate("{{.Card.Name}} {{join .Tags \" \"}}")),
+	)
+	newModel := NewRegistryService(s,
+		WithEmbedder(&fakeEmbedder{}),
+		WithEmbeddingModel("intfloat/e5-small-v2"),
+	)
+	prefixed := NewRegistryService(s,
+		WithEmbedder(embedding.NewPrefixEmbedder(&fakeEmbedder{}, embedding.DefaultPrefixes("BAAI/bge-small-en-v1.5"))),
+		WithEmbeddingModel("BAAI/bge-small-en-v1.5"),
+	)
+	// Update below re-embeds the stored agent in place, so compare copies.
+	current := *agent
+	legacy := *agent
+	legacy.EmbeddingVersion = ""
+	// Stores may list agents without loading their vectors.
+	listed := *agent
+	listed.Embedding, listed.SkillEmbeddings, listed.TagsEmbedding = nil, nil, nil
+	listed.Embedded = true
+	unembedded := listed
+	unembedded.Embedded = false
+
+	tests := []struct {
+		name  string
+		svc   *RegistryService
+		agent *store.RegisteredAgent
+		want  bool
+	}{
+		{name: "same template", svc: original, agent: &current, want: false},
+		{name: "changed template", svc: changed, agent: &current, want: true},
+		{name: "legacy agent with default template", svc: original, agent: &legacy, want: false},
+		{name: "legacy agent with changed template", svc: changed, agent: &legacy, want: true},
+		{name: "changed model", svc: newModel, agent: &current, want: true},
+		{name: "added prefixes", svc: prefixed, agent: &current, want: true},
+		{name: "legacy agent with added prefixes", svc: prefixed, agent: &legacy, want: true},
+		{name: "listed without vectors", svc: changed, agent: &listed, want: true},
+		{name: "stored without vectors", svc: changed, agent: &unembedded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.svc.NeedsReembed(tt.agent); got != tt.want {
+				t.Errorf("NeedsReembed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	updated, err := changed.Update(ctx, UpdateInput{ID: agent.ID, Card: agent.Card, Tags: agent.Tags})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if changed.NeedsReembed(updated) {
+		t.Error("NeedsReembed() after Update = true, want false")
+	}
+}
@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+)
+
+// agentDocument is the embedding input for a single agent.
+type agentDocument struct {
+	// id is the agent ID.
+	id string
+	// card is the agent card.
+	card a2a.AgentCard
+	// tags are the registry classification tags.
+	tags []string
+}
+
+// agentVectors holds the named embeddings generated for an agent.
+type agentVectors struct {
+	// profile is the card-level vector.
+	profile []float32
+	// skills holds one vector per card skill.
+	skills []store.SkillEmbedding
+	// tags is the tags vector, nil when the agent has no tags.
+	tags []float32
+	// version is the embedding template version, empty when no vectors
+	// were generated.
+	version string
+	// model is the embedding model ID, empty when no vectors were generated.
+	model string
+}
+
+// apply copies the vectors into agent.
+func (v agentVectors) apply(agent *store.RegisteredAgent) {
+	agent.Embedding = v.profile
+	agent.SkillEmbeddings = v.skills
+	agent.TagsEmbedding = v.tags
+	agent.EmbeddingVersion = v.version
+	agent.EmbeddingModel = v.model
+}
+
+// EmbeddingTexts are the texts embedded for an agent's named vectors.
+type EmbeddingTexts struct {
+	// Version is the embedding template version that rendered Profile.
+	Version string
+	// Profile is the rendered card-level text.
+	Profile string
+	// Skills holds the text of each skill, in card order.
+	Skills []SkillText
+	// Tags is the registry tags text, empty when the agent has no tags.
+	Tags string
+}
+
+// SkillText is the text embedded for a single skill.
+type SkillText struct {
+	// SkillID is the A2A skill ID.
+	SkillID string
+	// Text is the embedded text.
+	Text string
+}
+
+// buildTexts renders the embedding texts of a document with tmpl.
+func buildTexts(tmpl *EmbeddingTemplate, doc agentDocument) (*EmbeddingTexts, error) {
+	profile, err := tmpl.Render(&store.RegisteredAgent{ID: doc.id, Card: doc.card, Tags: doc.tags})
+	if err != nil {
+		return nil, err
+	}
+
+	texts := &EmbeddingTexts{
+		Version: tmpl.Version(),
+		Profile: profile,
+		Skills:  make([]SkillText, len(doc.card.Skills)),
+	}
+	for i, skill := range doc.card.Skills {
+		texts.Skills[i] = SkillText{SkillID: skill.ID, Text: buildSkillText(skill)}
+	}
+	texts.Tags = buildTagsText(doc.tags)
+	return texts, nil
+}
+
+// embedAgents generates profile, skill and tags vectors for every document
+// with a single embedder call, chunking over-long texts. It returns empty
+// vectors when no embedder is configured.
+func (s *RegistryService) embedAgents(ctx context.Context, docs []agentDocument) ([]agentVectors, error) {
+	vectors := make([]agentVectors, len(docs))
+	if s.embedder == nil || len(docs) == 0 {
+		return vectors, nil
+	}
+
+	var inputs []embedTextInput
+	docTexts := make([]*EmbeddingTexts, len(docs))
+	for i, doc := range docs {
+		var err error
+		if docTexts[i], err = buildTexts(s.template, doc); err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, embedTextInput{agentID: doc.id, label: "profile", text: docTexts[i].Profile})
+		for _, skill := range docTexts[i].Skills {
+			inputs = append(inputs, embedTextInput{agentID: doc.id, label: "skill:" + skill.SkillID, text: skill.Text})
+		}
+		if docTexts[i].Tags != "" {
+			inputs = append(inputs, embedTextInput{agentID: doc.id, label: "tags", text: docTexts[i].Tags})
+		}
+	}
+
+	embeddings, err := s.embedTexts(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 0
+	// Consume vectors with the same conditions that produced the texts, so
+	// each agent gets its own vectors.
+	for i := range docs {
+		vectors[i].version = s.version
+		vectors[i].model = s.model
+		vectors[i].profile = embeddings[next]
+		next++
+		for _, skill := range docTexts[i].Skills {
+			vectors[i].skills = append(vectors[i].skills, store.SkillEmbedding{
+				SkillID: skill.SkillID,
+				Vector:  embeddings[next],
+			})
+			next++
+		}
+		if docTexts[i].Tags != "" {
+			vectors[i].tags = embeddings[next]
+			next++
+		}
+	}
+
+	return vectors, nil
+}
+
+// embedAgent generates the named vectors for a single agent.
+func (s *RegistryService) embedAgent(ctx context.Context, id string, card a2a.AgentCard, tags []string) (agentVectors, error) {
+	vectors, err := s.embedAgents(ctx, []agentDocument{{id: id, card: card, tags: tags}})
+	if err != nil {
+		return agentVectors{}, err
+	}
+	return vectors[0], nil
+}
+
+// PreviewEmbeddingText renders the texts that would be embedded for a
+// stored agent. A nil tmpl uses the configured template.
+func (s *RegistryService) PreviewEmbeddingText(ctx context.Context, id string, tmpl *EmbeddingTemplate) (*EmbeddingTexts, error) {
+	agent, err := s.store.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		tmpl = s.template
+	}
+	return buildTexts(tmpl, agentDocument{id: agent.ID, card: agent.Card, tags: agent.Tags})
+}
+
+// embeddingVersion returns the version recorded with vectors: the template
+// version, followed by the prefixes' version when prefixes are set.
+func embeddingVersion(tmpl *EmbeddingTemplate, prefixes embedding.Prefixes) string {
+	if v := prefixes.Version(); v != "" {
+		return tmpl.Version() + "+" + v
+	}
+	return tmpl.Version()
+}
+
+// NeedsReembed reports whether agent's vectors were built from a different
+// embedding template, instruction prefixes or model than the configured
+// ones. It compares the stored model and version, so it also works on
+// agents listed without their vectors. Agents the store reports as having
+// no vectors are never stale; agents stored before versioning count as
+// embedded with DefaultEmbeddingTemplate and no prefixes by the current
+// model.
+func (s *RegistryService) NeedsReembed(agent *store.RegisteredAgent) bool {
+	if s.embedder == nil || !agent.HasVectors() {
+		return false
+	}
+	if agent.EmbeddingModel != "" && s.model != "" && agent.EmbeddingModel != s.model {
+		return true
+	}
+	version := agent.EmbeddingVersion
+	if version == "" {
+		version = defaultTemplateVersion
+	}
+	return version != s.version
+}
+
+// buildSkillText constructs the text to embed for a single skill,
+// including its tags and examples.
+func buildSkillText(skill a2a.AgentSkill) string {
+	parts := []string{skill.Name}
+	if skill.Description != "" {
+		parts = append(parts, skill.Description)
+	}
+	parts = append(parts, skill.Tags...)
+	parts = append(parts, skill.Examples...)
+	return strings.Join(parts, " ")
+}
+
+// buildTagsText constructs the text to embed for registry tags. It is
+// empty when no tag has any text, and then no tags vector is built.
+func buildTagsText(tags []string) string {
+	return strings.Join(strings.Fields(strings.Join(tags, " ")), " ")
+}
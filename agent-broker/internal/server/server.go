@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -29,7 +30,9 @@ type Options struct {
 	Logger *slog.Logger
 	// ReadTimeout is the max duration for reading the entire request.
 	ReadTimeout time.Duration
-	// WriteTimeout is the max duration before timing out writes.
+	// WriteTimeout is the max duration before timing out writes. Server-sent
+	// event streams instead get WriteTimeout for each write, so they can stay
+	// open while long-running agents work.
 	WriteTimeout time.Duration
 	// IdleTimeout is the max time to wait for the next request.
 	IdleTimeout time.Duration
@@ -104,7 +107,7 @@ func New(handler http.Handler, opts ...Option) *Server {
 	return &Server{
 		httpServer: &http.Server{
 			Addr:         fmt.Sprintf(":%d", options.Port),
-			Handler:      loggingMiddleware(options.Logger)(handler),
+			Handler:      loggingMiddleware(options.Logger)(streamDeadlineMiddleware(options.WriteTimeout)(handler)),
 			ReadTimeout:  options.ReadTimeout,
 			WriteTimeout: options.WriteTimeout,
 			IdleTimeout:  options.IdleTimeout,
@@ -178,3 +181,72 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Flush sends buffered data to the client, so server-sent event streams
+// pass through the logging middleware.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// streamDeadlineMiddleware moves the write deadline of server-sent event
+// responses forward before each write, so a stream is not cut off after
+// timeout but a client that stops reading still is.
+func streamDeadlineMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&streamWriter{ResponseWriter: w, timeout: timeout}, r)
+		})
+	}
+}
+
+// streamWriter extends the write deadline of event stream responses.
+type streamWriter struct {
+	http.ResponseWriter
+	// timeout bounds each write of an event stream.
+	timeout time.Duration
+	// wroteHeader is true once the response status was sent.
+	wroteHeader bool
+	// streaming is true when the response is an event stream.
+	streaming bool
+}
+
+func (sw *streamWriter) WriteHeader(code int) {
+	if !sw.wroteHeader {
+		sw.wroteHeader = true
+		sw.streaming = strings.HasPrefix(sw.Header().Get("Content-Type"), "text/event-stream")
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *streamWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	if sw.streaming {
+		// Not every writer supports deadlines; the server's own applies then.
+		_ = http.NewResponseController(sw.ResponseWriter).SetWriteDeadline(time.Now().Add(sw.timeout))
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client.
+func (sw *streamWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (sw *streamWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
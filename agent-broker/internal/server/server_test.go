@@ -0,0 +1,76 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggingMiddleware_Flush(t *testing.T) {
+	t.Parallel()
+	var flushable bool
+	handler := loggingMiddleware(slog.New(slog.DiscardHandler))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f http.Flusher
+		f, flushable = w.(http.Flusher)
+		if flushable {
+			f.Flush()
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if !flushable {
+		t.Fatal("wrapped ResponseWriter does not implement http.Flusher")
+	}
+	if !rec.Flushed {
+		t.Error("Flush() did not reach the underlying ResponseWriter")
+	}
+}
+
+func TestStreamDeadlineMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		wantEvents  int
+	}{
+		{name: "event stream outlives the write timeout", contentType: "text/event-stream", wantEvents: 3},
+		{name: "plain response is cut off", contentType: "text/plain", wantEvents: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			const timeout = 100 * time.Millisecond
+			srv := httptest.NewUnstartedServer(streamDeadlineMiddleware(timeout)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(http.StatusOK)
+				for range 3 {
+					time.Sleep(timeout)
+					_, _ = w.Write([]byte("data: tick\n\n"))
+					w.(http.Flusher).Flush()
+				}
+			})))
+			srv.Config.WriteTimeout = timeout
+			srv.Start()
+			t.Cleanup(srv.Close)
+
+			// A cut-off response fails the request or ends its body early.
+			var body []byte
+			if resp, err := http.Get(srv.URL); err == nil {
+				body, _ = io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+			}
+
+			if got := strings.Count(string(body), "tick"); got != tt.wantEvents {
+				t.Errorf("received %d events, want %d", got, tt.wantEvents)
+			}
+		})
+	}
+}
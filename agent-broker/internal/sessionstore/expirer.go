@@ -0,0 +1,169 @@
+package sessionstore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/periodic"
+)
+
+// Pruner is implemented by session services that can delete idle sessions
+// directly, without listing them first.
+type Pruner interface {
+	// Prune deletes sessions last updated before cutoff and returns how
+	// many were deleted.
+	Prune(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ExpiryPolicy decides when idle sessions are deleted.
+type ExpiryPolicy struct {
+	// IdleTimeout is how long a session is kept after its last update.
+	// Zero keeps sessions forever.
+	IdleTimeout time.Duration
+}
+
+// Expirer periodically deletes sessions that have been idle for longer than
+// its policy allows. The policy can be changed while it runs.
+type Expirer struct {
+	// svc holds the sessions.
+	svc session.Service
+	// apps are the app names whose sessions are expired.
+	apps []string
+	// logger reports expiry passes.
+	logger *slog.Logger
+	// mu protects policy.
+	mu sync.RWMutex
+	// policy is the current expiry policy.
+	policy ExpiryPolicy
+	// runner runs the expiry passes.
+	runner *periodic.Runner
+}
+
+// ExpirerOptions configures the Expirer.
+type ExpirerOptions struct {
+	// Policy is the initial expiry policy.
+	Policy ExpiryPolicy
+	// Interval is how often idle sessions are looked for.
+	Interval time.Duration
+	// Logger reports expiry passes.
+	Logger *slog.Logger
+}
+
+// DefaultExpirerOptions returns sensible defaults for expirer options.
+func DefaultExpirerOptions() ExpirerOptions {
+	return ExpirerOptions{
+		Interval: 10 * time.Minute,
+		Logger:   slog.Default(),
+	}
+}
+
+// ExpirerOption is a functional option for configuring Expirer.
+type ExpirerOption func(*ExpirerOptions)
+
+// WithIdleTimeout sets the initial idle timeout.
+func WithIdleTimeout(d time.Duration) ExpirerOption {
+	return func(o *ExpirerOptions) {
+		o.Policy.IdleTimeout = d
+	}
+}
+
+// WithInterval sets how often idle sessions are looked for.
+func WithInterval(d time.Duration) ExpirerOption {
+	return func(o *ExpirerOptions) {
+		if d > 0 {
+			o.Interval = d
+		}
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l *slog.Logger) ExpirerOption {
+	return func(o *ExpirerOptions) {
+		if l != nil {
+			o.Logger = l
+		}
+	}
+}
+
+// NewExpirer starts expiring the sessions of apps held by svc.
+func NewExpirer(svc session.Service, apps []string, opts ...ExpirerOption) *Expirer {
+	options := DefaultExpirerOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	e := &Expirer{
+		svc:    svc,
+		apps:   apps,
+		logger: options.Logger,
+		policy: options.Policy,
+	}
+	e.runner = periodic.Start(options.Interval, e.pass)
+	return e
+}
+
+// Policy returns the current expiry policy.
+func (e *Expirer) Policy() ExpiryPolicy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policy
+}
+
+// SetPolicy replaces the expiry policy. It applies from the next pass.
+func (e *Expirer) SetPolicy(policy ExpiryPolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policy = policy
+}
+
+// Expire deletes the sessions idle for longer than the policy allows and
+// returns how many were deleted.
+func (e *Expirer) Expire(ctx context.Context) (int64, error) {
+	idle := e.Policy().IdleTimeout
+	if idle <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-idle)
+
+	if pruner, ok := e.svc.(Pruner); ok {
+		return pruner.Prune(ctx, cutoff)
+	}
+
+	var deleted int64
+	for _, app := range e.apps {
+		resp, err := e.svc.List(ctx, &session.ListRequest{AppName: app})
+		if err != nil {
+			return deleted, fmt.Errorf("list sessions: %w", err)
+		}
+		for _, s := range resp.Sessions {
+			if !s.LastUpdateTime().Before(cutoff) {
+				continue
+			}
+			err := e.svc.Delete(ctx, &session.DeleteRequest{AppName: s.AppName(), UserID: s.UserID(), SessionID: s.ID()})
+			if err != nil {
+				return deleted, fmt.Errorf("delete session: %w", err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// pass runs one expiry pass and logs its outcome.
+func (e *Expirer) pass(ctx context.Context) {
+	if n, err := e.Expire(ctx); err != nil {
+		e.logger.Warn("failed to expire sessions", "error", err)
+	} else if n > 0 {
+		e.logger.Info("expired idle sessions", "count", n)
+	}
+}
+
+// Close stops the expiry loop.
+func (e *Expirer) Close() {
+	e.runner.Close()
+}
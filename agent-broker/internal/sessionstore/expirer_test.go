@@ -0,0 +1,94 @@
+package sessionstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+func TestExpirer_Expire(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		svc  func(t *testing.T) session.Service
+	}{
+		{name: "memory", svc: func(*testing.T) session.Service { return session.InMemoryService() }},
+		{name: "sqlite", svc: func(t *testing.T) session.Service {
+			return mustSQLiteService(t, filepath.Join(t.TempDir(), "broker.db"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			svc := tt.svc(t)
+			e := NewExpirer(svc, []string{"broker"}, WithInterval(time.Hour))
+			t.Cleanup(e.Close)
+
+			if _, err := svc.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: "idle"}); err != nil {
+				t.Fatalf("Create(idle) error = %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if _, err := svc.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: "fresh"}); err != nil {
+				t.Fatalf("Create(fresh) error = %v", err)
+			}
+
+			// Without an idle timeout nothing expires.
+			if n, err := e.Expire(ctx); err != nil || n != 0 {
+				t.Fatalf("Expire() without policy = %d, %v, want 0", n, err)
+			}
+
+			e.SetPolicy(ExpiryPolicy{IdleTimeout: 25 * time.Millisecond})
+			if got := e.Policy().IdleTimeout; got != 25*time.Millisecond {
+				t.Errorf("Policy().IdleTimeout = %v, want 25ms", got)
+			}
+			n, err := e.Expire(ctx)
+			if err != nil {
+				t.Fatalf("Expire() error = %v", err)
+			}
+			if n != 1 {
+				t.Errorf("Expire() = %d, want 1", n)
+			}
+
+			resp, err := svc.List(ctx, &session.ListRequest{AppName: "broker"})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(resp.Sessions) != 1 || resp.Sessions[0].ID() != "fresh" {
+				t.Errorf("remaining sessions = %d, want only fresh", len(resp.Sessions))
+			}
+		})
+	}
+}
+
+func TestExpirer_Loop(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := session.InMemoryService()
+	e := NewExpirer(svc, []string{"broker"},
+		WithIdleTimeout(30*time.Millisecond),
+		WithInterval(10*time.Millisecond),
+	)
+	t.Cleanup(e.Close)
+
+	if _, err := svc.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: "idle"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := svc.List(ctx, &session.ListRequest{AppName: "broker"})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(resp.Sessions) == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("idle session was not expired within 2s")
+}
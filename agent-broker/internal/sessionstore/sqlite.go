@@ -0,0 +1,112 @@
+// Package sessionstore provides durable session services for the broker.
+package sessionstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SQLiteService is a session.Service stored in an embedded SQLite database
+// file, so session state and events survive restarts. The file belongs to a
+// single broker process; running several replicas against shared sessions
+// is out of scope.
+type SQLiteService struct {
+	session.Service
+	// db is the database shared with the embedded service.
+	db *gorm.DB
+	// conn is the underlying connection pool.
+	conn *sql.DB
+}
+
+var (
+	_ session.Service = (*SQLiteService)(nil)
+	_ Pruner          = (*SQLiteService)(nil)
+)
+
+// NewSQLiteService opens or creates the session database at path.
+func NewSQLiteService(path string) (*SQLiteService, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create session db dir: %w", err)
+	}
+	conn, err := sql.Open(sqlite.DriverName, "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open session db: %w", err)
+	}
+	// SQLite allows one writer; a single connection avoids busy errors
+	// between concurrent transactions.
+	conn.SetMaxOpenConns(1)
+
+	dialector := &sqlite.Dialector{Conn: conn}
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	svc, err := database.NewSessionService(dialector, gormConfig)
+	if err == nil {
+		err = database.AutoMigrate(svc)
+	}
+	var db *gorm.DB
+	if err == nil {
+		db, err = gorm.Open(dialector, gormConfig)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("init session db: %w", err)
+	}
+
+	return &SQLiteService{Service: svc, db: db, conn: conn}, nil
+}
+
+// Delete removes a session together with its events in one transaction.
+// The embedded service's Delete leaves the events behind, so a session
+// recreated under the same ID would inherit them.
+func (s *SQLiteService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return fmt.Errorf("app_name, user_id and session_id are required, got %q, %q, %q", req.AppName, req.UserID, req.SessionID)
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM events WHERE app_name = ? AND user_id = ? AND session_id = ?", req.AppName, req.UserID, req.SessionID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM sessions WHERE app_name = ? AND user_id = ? AND id = ?", req.AppName, req.UserID, req.SessionID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes sessions of every app, and their events, last updated
+// before cutoff. It returns the number of sessions deleted.
+func (s *SQLiteService) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM events WHERE EXISTS (
+			SELECT 1 FROM sessions s
+			WHERE s.app_name = events.app_name AND s.user_id = events.user_id AND s.id = events.session_id
+			AND s.update_time < ?)`, cutoff).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Exec("DELETE FROM sessions WHERE update_time < ?", cutoff)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("prune sessions: %w", err)
+	}
+	return deleted, nil
+}
+
+// Close closes the database.
+func (s *SQLiteService) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,131 @@
+package sessionstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func mustSQLiteService(t *testing.T, path string) *SQLiteService {
+	t.Helper()
+	s, err := NewSQLiteService(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteService() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// appendText appends a text event with a state delta to the stored session.
+func appendText(t *testing.T, s session.Service, id, text string, delta map[string]any) {
+	t.Helper()
+	ctx := context.Background()
+	got, err := s.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: id})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	event := session.NewEvent("inv-1")
+	event.Author = "user"
+	event.Content = genai.NewContentFromText(text, genai.RoleUser)
+	event.Actions.StateDelta = delta
+	if err := s.AppendEvent(ctx, got.Session, event); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+}
+
+func TestSQLiteService_SurvivesRestart(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sessions", "broker.db")
+
+	first := mustSQLiteService(t, path)
+	if _, err := first.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	appendText(t, first, "ctx-1", "find a translator", map[string]any{"topic": "translation", "user:lang": "fr"})
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := mustSQLiteService(t, path)
+	got, err := reopened.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"})
+	if err != nil {
+		t.Fatalf("Get() after reopen error = %v", err)
+	}
+	if got.Session.Events().Len() != 1 || got.Session.Events().At(0).Content.Parts[0].Text != "find a translator" {
+		t.Errorf("events after reopen = %d, want the appended message", got.Session.Events().Len())
+	}
+	for key, want := range map[string]string{"topic": "translation", "user:lang": "fr"} {
+		if v, err := got.Session.State().Get(key); err != nil || v != want {
+			t.Errorf("state %q = %v (%v), want %q", key, v, err, want)
+		}
+	}
+}
+
+func TestSQLiteService_DeleteRemovesEvents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := mustSQLiteService(t, filepath.Join(t.TempDir(), "broker.db"))
+
+	if _, err := s.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	appendText(t, s, "ctx-1", "hello", nil)
+	if err := s.Delete(ctx, &session.DeleteRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"}); err == nil {
+		t.Error("Get() after Delete error = nil, want an error")
+	}
+
+	// A2A clients reuse context IDs; a recreated session must start empty.
+	if _, err := s.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"}); err != nil {
+		t.Fatalf("Create() again error = %v", err)
+	}
+	got, err := s.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: "ctx-1"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if n := got.Session.Events().Len(); n != 0 {
+		t.Errorf("recreated session has %d events, want 0", n)
+	}
+}
+
+func TestSQLiteService_Prune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := mustSQLiteService(t, filepath.Join(t.TempDir(), "broker.db"))
+
+	for _, id := range []string{"old", "new"} {
+		if _, err := s.Create(ctx, &session.CreateRequest{AppName: "broker", UserID: "user", SessionID: id}); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+		appendText(t, s, id, "hello "+id, nil)
+		if id == "old" {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	old, err := s.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: "old"})
+	if err != nil {
+		t.Fatalf("Get(old) error = %v", err)
+	}
+	n, err := s.Prune(ctx, old.Session.LastUpdateTime().Add(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Prune() = %d, want 1", n)
+	}
+
+	if _, err := s.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: "old"}); err == nil {
+		t.Error("old session still exists after Prune")
+	}
+	if _, err := s.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user", SessionID: "new"}); err != nil {
+		t.Errorf("Get(new) after Prune error = %v", err)
+	}
+}
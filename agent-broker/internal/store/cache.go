@@ -0,0 +1,414 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// CacheOptions configures the CachedStore.
+type CacheOptions struct {
+	// AgentTTL is how long GetAgent results are cached.
+	AgentTTL time.Duration
+	// ListTTL is how long ListAgents results are cached.
+	ListTTL time.Duration
+	// SearchTTL is how long SearchAgents results are cached.
+	SearchTTL time.Duration
+	// MaxEntries bounds the number of entries per cache kind.
+	MaxEntries int
+}
+
+// DefaultCacheOptions returns CacheOptions with sensible defaults.
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		AgentTTL:   5 * time.Minute,
+		ListTTL:    30 * time.Second,
+		SearchTTL:  10 * time.Second,
+		MaxEntries: 1000,
+	}
+}
+
+// CacheOption is a functional option for configuring CachedStore.
+type CacheOption func(*CacheOptions)
+
+// WithAgentTTL sets the TTL for cached GetAgent results.
+func WithAgentTTL(d time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.AgentTTL = d
+	}
+}
+
+// WithListTTL sets the TTL for cached ListAgents results.
+func WithListTTL(d time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.ListTTL = d
+	}
+}
+
+// WithSearchTTL sets the TTL for cached SearchAgents results.
+func WithSearchTTL(d time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.SearchTTL = d
+	}
+}
+
+// WithMaxEntries sets the maximum number of entries per cache kind.
+func WithMaxEntries(n int) CacheOption {
+	return func(o *CacheOptions) {
+		if n > 0 {
+			o.MaxEntries = n
+		}
+	}
+}
+
+// CacheStats contains hit/miss counters for a CachedStore.
+type CacheStats struct {
+	// AgentHits is the number of GetAgent calls served from cache.
+	AgentHits uint64
+	// AgentMisses is the number of GetAgent calls forwarded to the backend.
+	AgentMisses uint64
+	// ListHits is the number of ListAgents calls served from cache.
+	ListHits uint64
+	// ListMisses is the number of ListAgents calls forwarded to the backend.
+	ListMisses uint64
+	// SearchHits is the number of SearchAgents calls served from cache.
+	SearchHits uint64
+	// SearchMisses is the number of SearchAgents calls forwarded to the backend.
+	SearchMisses uint64
+}
+
+// CacheStatsReporter exposes cache statistics.
+type CacheStatsReporter interface {
+	CacheStats() CacheStats
+}
+
+// cacheEntry is a cached value with its expiry time.
+type cacheEntry[T any] struct {
+	// value is the cached result.
+	value T
+	// expiresAt is when the entry becomes stale.
+	expiresAt time.Time
+}
+
+// CachedStore is a read-through caching decorator for a Store.
+// Writes made through it invalidate affected entries.
+type CachedStore struct {
+	// next is the wrapped storage backend.
+	next Store
+	// opts holds the cache configuration.
+	opts CacheOptions
+	// now returns the current time (overridable in tests).
+	now func() time.Time
+
+	// mu protects the cache maps.
+	mu sync.Mutex
+	// agents caches GetAgent results by agent ID.
+	agents map[string]cacheEntry[*RegisteredAgent]
+	// lists caches ListAgents results by filter key.
+	lists map[string]cacheEntry[*AgentListResult]
+	// searches caches SearchAgents results by query hash and filter key.
+	searches map[string]cacheEntry[*SearchResult]
+	// gen is bumped on every invalidation so that reads racing a write
+	// do not repopulate the cache with stale results.
+	gen uint64
+
+	agentHits, agentMisses   atomic.Uint64
+	listHits, listMisses     atomic.Uint64
+	searchHits, searchMisses atomic.Uint64
+}
+
+// NewCachedStore wraps next with a read-through cache.
+func NewCachedStore(next Store, opts ...CacheOption) *CachedStore {
+	options := DefaultCacheOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &CachedStore{
+		next:     next,
+		opts:     options,
+		now:      time.Now,
+		agents:   make(map[string]cacheEntry[*RegisteredAgent]),
+		lists:    make(map[string]cacheEntry[*AgentListResult]),
+		searches: make(map[string]cacheEntry[*SearchResult]),
+	}
+}
+
+// Ping checks if the wrapped backend is reachable.
+func (s *CachedStore) Ping(ctx context.Context) error {
+	return s.next.Ping(ctx)
+}
+
+// Close releases resources of the wrapped backend.
+func (s *CachedStore) Close() error {
+	return s.next.Close()
+}
+
+// CreateAgent stores a new agent and invalidates list and search caches.
+func (s *CachedStore) CreateAgent(ctx context.Context, agent *RegisteredAgent) error {
+	err := s.next.CreateAgent(ctx, agent)
+	s.invalidate(agent.ID)
+	return err
+}
+
+// GetAgent returns a cached agent or loads it from the backend.
+func (s *CachedStore) GetAgent(ctx context.Context, id string) (*RegisteredAgent, error) {
+	agent, gen, ok := lookup(s, s.agents, id)
+	if ok {
+		s.agentHits.Add(1)
+		return cloneAgent(agent), nil
+	}
+	s.agentMisses.Add(1)
+
+	agent, err := s.next.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	put(s, s.agents, gen, id, cloneAgent(agent), s.opts.AgentTTL)
+	return agent, nil
+}
+
+// ListAgents returns cached list results or loads them from the backend.
+func (s *CachedStore) ListAgents(ctx context.Context, filter AgentFilter) (*AgentListResult, error) {
+	key := filterKey(filter)
+	cached, gen, ok := lookup(s, s.lists, key)
+	if ok {
+		s.listHits.Add(1)
+		return cloneListResult(cached), nil
+	}
+	s.listMisses.Add(1)
+
+	result, err := s.next.ListAgents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	put(s, s.lists, gen, key, cloneListResult(result), s.opts.ListTTL)
+	return result, nil
+}
+
+// SearchAgents returns cached search results or queries the backend.
+func (s *CachedStore) SearchAgents(ctx context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error) {
+	key := fmt.Sprintf("%s|%d|%s", vectorHash(query), limit, filterKey(filter))
+	cached, gen, ok := lookup(s, s.searches, key)
+	if ok {
+		s.searchHits.Add(1)
+		return cloneSearchResult(cached), nil
+	}
+	s.searchMisses.Add(1)
+
+	result, err := s.next.SearchAgents(ctx, query, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	put(s, s.searches, gen, key, cloneSearchResult(result), s.opts.SearchTTL)
+	return result, nil
+}
+
+// UpdateAgent updates an agent and invalidates its cached entries.
+func (s *CachedStore) UpdateAgent(ctx context.Context, agent *RegisteredAgent) error {
+	err := s.next.UpdateAgent(ctx, agent)
+	s.invalidate(agent.ID)
+	return err
+}
+
+// DeleteAgent removes an agent and invalidates its cached entries.
+func (s *CachedStore) DeleteAgent(ctx context.Context, id string) error {
+	err := s.next.DeleteAgent(ctx, id)
+	s.invalidate(id)
+	return err
+}
+
+// UpsertAgents upserts agents and invalidates their cached entries.
+func (s *CachedStore) UpsertAgents(ctx context.Context, agents []*RegisteredAgent) ([]BatchItemResult, error) {
+	results, err := s.next.UpsertAgents(ctx, agents)
+	ids := make([]string, len(agents))
+	for i, agent := range agents {
+		ids[i] = agent.ID
+	}
+	s.invalidate(ids...)
+	return results, err
+}
+
+// DeleteAgents removes agents and invalidates their cached entries.
+func (s *CachedStore) DeleteAgents(ctx context.Context, ids []string) ([]BatchItemResult, error) {
+	results, err := s.next.DeleteAgents(ctx, ids)
+	s.invalidate(ids...)
+	return results, err
+}
+
+// CacheStats returns a snapshot of the cache hit/miss counters.
+func (s *CachedStore) CacheStats() CacheStats {
+	return CacheStats{
+		AgentHits:    s.agentHits.Load(),
+		AgentMisses:  s.agentMisses.Load(),
+		ListHits:     s.listHits.Load(),
+		ListMisses:   s.listMisses.Load(),
+		SearchHits:   s.searchHits.Load(),
+		SearchMisses: s.searchMisses.Load(),
+	}
+}
+
+// invalidate drops the agent entries and every list and search result,
+// since any write may change their membership or ordering.
+func (s *CachedStore) invalidate(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen++
+	for _, id := range ids {
+		delete(s.agents, id)
+	}
+	clear(s.lists)
+	clear(s.searches)
+}
+
+// lookup returns a non-expired entry from m along with the current generation.
+func lookup[T any](s *CachedStore, m map[string]cacheEntry[T], key string) (T, uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var zero T
+	entry, ok := m[key]
+	if !ok {
+		return zero, s.gen, false
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(m, key)
+		return zero, s.gen, false
+	}
+	return entry.value, s.gen, true
+}
+
+// put adds an entry to m, evicting expired entries when the cache is full.
+// The entry is dropped if an invalidation happened since gen was read.
+func put[T any](s *CachedStore, m map[string]cacheEntry[T], gen uint64, key string, value T, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if gen != s.gen {
+		return
+	}
+
+	now := s.now()
+	if len(m) >= s.opts.MaxEntries {
+		for k, e := range m {
+			if !now.Before(e.expiresAt) {
+				delete(m, k)
+			}
+		}
+		if len(m) >= s.opts.MaxEntries {
+			clear(m)
+		}
+	}
+
+	m[key] = cacheEntry[T]{value: value, expiresAt: now.Add(ttl)}
+}
+
+// filterKey builds a stable cache key from an AgentFilter. Strings are
+// quoted so values containing separators cannot collide.
+func filterKey(filter AgentFilter) string {
+	return fmt.Sprintf("%d|%d|%q|%q|%q|%q",
+		filter.Offset,
+		filter.Limit,
+		filter.Tags,
+		filter.Skills,
+		filter.Query,
+		filter.Expr.String(),
+	)
+}
+
+// vectorHash returns a hex SHA-256 digest of a query vector.
+func vectorHash(v []float32) string {
+	h := sha256.New()
+	buf := make([]byte, 4)
+	for _, f := range v {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(f))
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cloneAgent returns a deep copy of the agent's slices so callers cannot
+// mutate cached entries.
+func cloneAgent(agent *RegisteredAgent) *RegisteredAgent {
+	if agent == nil {
+		return nil
+	}
+	c := *agent
+	c.Card = cloneCard(agent.Card)
+	c.Tags = slices.Clone(agent.Tags)
+	c.Embedding = slices.Clone(agent.Embedding)
+	c.TagsEmbedding = slices.Clone(agent.TagsEmbedding)
+	if agent.SkillEmbeddings != nil {
+		c.SkillEmbeddings = make([]SkillEmbedding, len(agent.SkillEmbeddings))
+		for i, se := range agent.SkillEmbeddings {
+			c.SkillEmbeddings[i] = SkillEmbedding{SkillID: se.SkillID, Vector: slices.Clone(se.Vector)}
+		}
+	}
+	return &c
+}
+
+// cloneCard copies the slices of an agent card. Security requirements and
+// schemes are shared; the broker never mutates them.
+func cloneCard(card a2a.AgentCard) a2a.AgentCard {
+	c := card
+	c.AdditionalInterfaces = slices.Clone(card.AdditionalInterfaces)
+	c.Capabilities.Extensions = slices.Clone(card.Capabilities.Extensions)
+	c.DefaultInputModes = slices.Clone(card.DefaultInputModes)
+	c.DefaultOutputModes = slices.Clone(card.DefaultOutputModes)
+	c.Security = slices.Clone(card.Security)
+	c.Signatures = slices.Clone(card.Signatures)
+	if card.Provider != nil {
+		provider := *card.Provider
+		c.Provider = &provider
+	}
+	if card.Skills != nil {
+		c.Skills = make([]a2a.AgentSkill, len(card.Skills))
+		for i, skill := range card.Skills {
+			skill.Examples = slices.Clone(skill.Examples)
+			skill.InputModes = slices.Clone(skill.InputModes)
+			skill.OutputModes = slices.Clone(skill.OutputModes)
+			skill.Security = slices.Clone(skill.Security)
+			skill.Tags = slices.Clone(skill.Tags)
+			c.Skills[i] = skill
+		}
+	}
+	return c
+}
+
+func cloneListResult(result *AgentListResult) *AgentListResult {
+	agents := make([]*RegisteredAgent, len(result.Agents))
+	for i, a := range result.Agents {
+		agents[i] = cloneAgent(a)
+	}
+	out := *result
+	out.Agents = agents
+	return &out
+}
+
+func cloneSearchResult(result *SearchResult) *SearchResult {
+	agents := make([]ScoredAgent, len(result.Agents))
+	for i, a := range result.Agents {
+		agents[i] = a
+		agents[i].Agent = cloneAgent(a.Agent)
+	}
+	out := *result
+	out.Agents = agents
+	return &out
+}
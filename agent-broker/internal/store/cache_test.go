@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCachedStore_GetAgent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("second read is served from cache", func(t *testing.T) {
+		t.Parallel()
+		backend := NewMemoryStore()
+		_ = backend.CreateAgent(ctx, validAgent("agent-1"))
+		s := NewCachedStore(backend)
+
+		_, _ = s.GetAgent(ctx, "agent-1")
+		agent, err := s.GetAgent(ctx, "agent-1")
+
+		if err != nil {
+			t.Fatalf("GetAgent() error = %v", err)
+		}
+		if agent.ID != "agent-1" {
+			t.Errorf("GetAgent() ID = %v, want agent-1", agent.ID)
+		}
+		stats := s.CacheStats()
+		if stats.AgentHits != 1 || stats.AgentMisses != 1 {
+			t.Errorf("stats = %+v, want 1 hit and 1 miss", stats)
+		}
+	})
+
+	t.Run("not found is not cached", func(t *testing.T) {
+		t.Parallel()
+		s := NewCachedStore(NewMemoryStore())
+
+		_, err := s.GetAgent(ctx, "not-exists")
+		if err != ErrNotFound {
+			t.Fatalf("GetAgent() error = %v, want ErrNotFound", err)
+		}
+		_ = s.CreateAgent(ctx, validAgent("not-exists"))
+
+		if _, err := s.GetAgent(ctx, "not-exists"); err != nil {
+			t.Errorf("GetAgent() after create error = %v", err)
+		}
+	})
+
+	t.Run("entry expires after TTL", func(t *testing.T) {
+		t.Parallel()
+		backend := NewMemoryStore()
+		_ = backend.CreateAgent(ctx, validAgent("agent-1"))
+		s := NewCachedStore(backend, WithAgentTTL(time.Minute))
+		now := time.Now()
+		s.now = func() time.Time { return now }
+
+		_, _ = s.GetAgent(ctx, "agent-1")
+		now = now.Add(2 * time.Minute)
+		_, _ = s.GetAgent(ctx, "agent-1")
+
+		if stats := s.CacheStats(); stats.AgentMisses != 2 {
+			t.Errorf("AgentMisses = %d, want 2", stats.AgentMisses)
+		}
+	})
+
+	t.Run("mutating returned agent does not affect cache", func(t *testing.T) {
+		t.Parallel()
+		backend := NewMemoryStore()
+		_ = backend.CreateAgent(ctx, validAgent("agent-1"))
+		s := NewCachedStore(backend)
+
+		_, _ = s.GetAgent(ctx, "agent-1")
+		agent, _ := s.GetAgent(ctx, "agent-1")
+		agent.Card.Name = "Mutated"
+
+		cached, _ := s.GetAgent(ctx, "agent-1")
+		if cached.Card.Name != "Test Agent" {
+			t.Errorf("cached Name = %v, want Test Agent", cached.Card.Name)
+		}
+	})
+
+	t.Run("mutating returned slices does not affect cache", func(t *testing.T) {
+		t.Parallel()
+		backend := NewMemoryStore()
+		agent := validAgent("agent-1")
+		agent.Embedding = []float32{1, 0}
+		agent.SkillEmbeddings = []SkillEmbedding{{SkillID: "skill-1", Vector: []float32{0, 1}}}
+		_ = backend.CreateAgent(ctx, agent)
+		s := NewCachedStore(backend)
+
+		_, _ = s.GetAgent(ctx, "agent-1")
+		got, _ := s.GetAgent(ctx, "agent-1")
+		got.Tags[0] = "mutated"
+		got.Embedding[0] = 9
+		got.SkillEmbeddings[0].Vector[0] = 9
+		got.Card.Skills[0].Name = "Mutated"
+
+		cached, _ := s.GetAgent(ctx, "agent-1")
+		if cached.Tags[0] != "test" {
+			t.Errorf("cached Tags = %v, want [test]", cached.Tags)
+		}
+		if cached.Embedding[0] != 1 {
+			t.Errorf("cached Embedding = %v, want [1 0]", cached.Embedding)
+		}
+		if cached.SkillEmbeddings[0].Vector[0] != 0 {
+			t.Errorf("cached skill vector = %v, want [0 1]", cached.SkillEmbeddings[0].Vector)
+		}
+		if cached.Card.Skills[0].Name != "Skill One" {
+			t.Errorf("cached skill Name = %v, want Skill One", cached.Card.Skills[0].Name)
+		}
+	})
+}
+
+func TestFilterKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b AgentFilter
+	}{
+		{
+			name: "tag containing separator",
+			a:    AgentFilter{Tags: []string{"a,b"}},
+			b:    AgentFilter{Tags: []string{"a", "b"}},
+		},
+		{
+			name: "skill and query containing separator",
+			a:    AgentFilter{Skills: []string{"x|y"}, Query: "z"},
+			b:    AgentFilter{Skills: []string{"x"}, Query: "y|z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if filterKey(tt.a) == filterKey(tt.b) {
+				t.Errorf("filterKey() collides: %q", filterKey(tt.a))
+			}
+		})
+	}
+}
+
+func TestCachedStore_Invalidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("update invalidates agent and list entries", func(t *testing.T) {
+		t.Parallel()
+		s := NewCachedStore(NewMemoryStore())
+		_ = s.CreateAgent(ctx, validAgent("agent-1"))
+		_, _ = s.GetAgent(ctx, "agent-1")
+		_, _ = s.ListAgents(ctx, AgentFilter{Limit: 10})
+
+		updated := validAgent("agent-1")
+		updated.Card.Name = "Updated Name"
+		if err := s.UpdateAgent(ctx, updated); err != nil {
+			t.Fatalf("UpdateAgent() error = %v", err)
+		}
+
+		agent, _ := s.GetAgent(ctx, "agent-1")
+		if agent.Card.Name != "Updated Name" {
+			t.Errorf("GetAgent() Name = %v, want Updated Name", agent.Card.Name)
+		}
+		list, _ := s.ListAgents(ctx, AgentFilter{Limit: 10})
+		if list.Agents[0].Card.Name != "Updated Name" {
+			t.Errorf("ListAgents() Name = %v, want Updated Name", list.Agents[0].Card.Name)
+		}
+	})
+
+	t.Run("create invalidates list entries", func(t *testing.T) {
+		t.Parallel()
+		s := NewCachedStore(NewMemoryStore())
+		_, _ = s.ListAgents(ctx, AgentFilter{Limit: 10})
+
+		_ = s.CreateAgent(ctx, validAgent("agent-1"))
+
+		list, _ := s.ListAgents(ctx, AgentFilter{Limit: 10})
+		if list.Total != 1 {
+			t.Errorf("ListAgents() total = %d, want 1", list.Total)
+		}
+	})
+
+	t.Run("delete invalidates search entries", func(t *testing.T) {
+		t.Parallel()
+		s := NewCachedStore(NewMemoryStore())
+		agent := validAgent("agent-1")
+		agent.Embedding = []float32{1, 0}
+		_ = s.CreateAgent(ctx, agent)
+		query := []float32{1, 0}
+		_, _ = s.SearchAgents(ctx, query, 5, AgentFilter{})
+
+		_ = s.DeleteAgent(ctx, "agent-1")
+
+		result, _ := s.SearchAgents(ctx, query, 5, AgentFilter{})
+		if len(result.Agents) != 0 {
+			t.Errorf("SearchAgents() got %d agents, want 0", len(result.Agents))
+		}
+		if stats := s.CacheStats(); stats.SearchHits != 0 || stats.SearchMisses != 2 {
+			t.Errorf("stats = %+v, want 0 hits and 2 misses", stats)
+		}
+	})
+}
+
+func TestCachedStore_SearchAgents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := NewCachedStore(NewMemoryStore())
+	agent := validAgent("agent-1")
+	agent.Embedding = []float32{1, 0}
+	_ = s.CreateAgent(ctx, agent)
+
+	_, _ = s.SearchAgents(ctx, []float32{1, 0}, 5, AgentFilter{})
+	_, _ = s.SearchAgents(ctx, []float32{1, 0}, 5, AgentFilter{})
+	_, _ = s.SearchAgents(ctx, []float32{0, 1}, 5, AgentFilter{})
+
+	stats := s.CacheStats()
+	if stats.SearchHits != 1 {
+		t.Errorf("SearchHits = %d, want 1", stats.SearchHits)
+	}
+	if stats.SearchMisses != 2 {
+		t.Errorf("SearchMisses = %d, want 2", stats.SearchMisses)
+	}
+}
+
+func TestCloneSearchResult(t *testing.T) {
+	t.Parallel()
+	result := &SearchResult{
+		Agents:   []ScoredAgent{{Agent: validAgent("agent-1"), Score: 0.5}},
+		Lexical:  true,
+		Reranked: true,
+	}
+
+	clone := cloneSearchResult(result)
+
+	if !clone.Lexical || !clone.Reranked {
+		t.Errorf("cloneSearchResult() = %+v, want the Lexical and Reranked flags kept", clone)
+	}
+	if clone.Agents[0].Agent == result.Agents[0].Agent {
+		t.Error("cloneSearchResult() shares the agent with the original")
+	}
+}
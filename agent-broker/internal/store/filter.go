@@ -0,0 +1,627 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidFilter is returned when a filter expression cannot be parsed
+// or is not valid for its field.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// FilterField is an agent field that can be used in a filter expression.
+type FilterField string
+
+// Filterable agent fields.
+const (
+	FieldID          FilterField = "id"
+	FieldName        FilterField = "name"
+	FieldDescription FilterField = "description"
+	FieldVersion     FilterField = "version"
+	FieldTags        FilterField = "tags"
+	FieldSkills      FilterField = "skills"
+	FieldCreatedAt   FilterField = "created_at"
+	FieldUpdatedAt   FilterField = "updated_at"
+)
+
+// FilterOp is a comparison operator in a filter condition.
+type FilterOp string
+
+// Filter operators.
+const (
+	// OpAny matches when the field equals, or for lists contains, any value.
+	OpAny FilterOp = "in"
+	// OpAll matches when a list field contains every value.
+	OpAll FilterOp = "all"
+	// OpContains matches text containing every word of a value, ignoring
+	// case, the way Qdrant's full-text index matches.
+	OpContains FilterOp = "~"
+	// OpGt, OpGte, OpLt and OpLte compare timestamps.
+	OpGt  FilterOp = ">"
+	OpGte FilterOp = ">="
+	OpLt  FilterOp = "<"
+	OpLte FilterOp = "<="
+)
+
+// fieldOps lists the operators each field supports.
+var fieldOps = map[FilterField][]FilterOp{
+	FieldID:          {OpAny},
+	FieldName:        {OpContains},
+	FieldDescription: {OpContains},
+	FieldVersion:     {OpAny},
+	FieldTags:        {OpAny, OpAll},
+	FieldSkills:      {OpAny, OpAll},
+	FieldCreatedAt:   {OpGt, OpGte, OpLt, OpLte},
+	FieldUpdatedAt:   {OpGt, OpGte, OpLt, OpLte},
+}
+
+// FilterExpr is a boolean expression over agent fields.
+// Exactly one of And, Or, Not or Cond is set.
+type FilterExpr struct {
+	// And matches when every sub-expression matches.
+	And []*FilterExpr
+	// Or matches when any sub-expression matches.
+	Or []*FilterExpr
+	// Not matches when the sub-expression does not match.
+	Not *FilterExpr
+	// Cond is a single field condition.
+	Cond *FilterCond
+}
+
+// FilterCond compares a single agent field.
+type FilterCond struct {
+	// Field is the agent field to compare.
+	Field FilterField
+	// Op is the comparison operator.
+	Op FilterOp
+	// Values are the operands for OpAny, OpAll and OpContains.
+	Values []string
+	// Time is the operand for timestamp comparisons.
+	Time time.Time
+}
+
+// Validate checks that the expression is well formed and every condition
+// uses an operator its field supports.
+func (e *FilterExpr) Validate() error {
+	if e == nil {
+		return nil
+	}
+
+	set := 0
+	if len(e.And) > 0 {
+		set++
+	}
+	if len(e.Or) > 0 {
+		set++
+	}
+	if e.Not != nil {
+		set++
+	}
+	if e.Cond != nil {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("%w: expression must have exactly one of and, or, not, condition", ErrInvalidFilter)
+	}
+
+	for _, sub := range e.And {
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, sub := range e.Or {
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+	}
+	if e.Not != nil {
+		return e.Not.Validate()
+	}
+	if e.Cond != nil {
+		return e.Cond.validate()
+	}
+	return nil
+}
+
+func (c *FilterCond) validate() error {
+	ops, ok := fieldOps[c.Field]
+	if !ok {
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, c.Field)
+	}
+	if !slices.Contains(ops, c.Op) {
+		return fmt.Errorf("%w: operator %q not supported for %s", ErrInvalidFilter, c.Op, c.Field)
+	}
+	if c.isRange() {
+		if c.Time.IsZero() {
+			return fmt.Errorf("%w: %s requires a timestamp", ErrInvalidFilter, c.Field)
+		}
+		return nil
+	}
+	if len(c.Values) == 0 {
+		return fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, c.Field)
+	}
+	return nil
+}
+
+// isRange reports whether the condition is a timestamp comparison.
+func (c *FilterCond) isRange() bool {
+	switch c.Op {
+	case OpGt, OpGte, OpLt, OpLte:
+		return true
+	}
+	return false
+}
+
+// Match reports whether the agent satisfies the expression.
+// A nil expression matches every agent.
+func (e *FilterExpr) Match(agent *RegisteredAgent) bool {
+	switch {
+	case e == nil:
+		return true
+	case len(e.And) > 0:
+		for _, sub := range e.And {
+			if !sub.Match(agent) {
+				return false
+			}
+		}
+		return true
+	case len(e.Or) > 0:
+		for _, sub := range e.Or {
+			if sub.Match(agent) {
+				return true
+			}
+		}
+		return false
+	case e.Not != nil:
+		return !e.Not.Match(agent)
+	case e.Cond != nil:
+		return e.Cond.match(agent)
+	}
+	return true
+}
+
+func (c *FilterCond) match(agent *RegisteredAgent) bool {
+	switch c.Field {
+	case FieldID:
+		return slices.Contains(c.Values, agent.ID)
+	case FieldVersion:
+		return slices.Contains(c.Values, agent.Card.Version)
+	case FieldName:
+		return containsAnyWords(agent.Card.Name, c.Values)
+	case FieldDescription:
+		return containsAnyWords(agent.Card.Description, c.Values)
+	case FieldTags:
+		return matchList(agent.Tags, c.Op, c.Values)
+	case FieldSkills:
+		skillIDs := make([]string, len(agent.Card.Skills))
+		for i, skill := range agent.Card.Skills {
+			skillIDs[i] = skill.ID
+		}
+		return matchList(skillIDs, c.Op, c.Values)
+	case FieldCreatedAt:
+		return compareTime(agent.CreatedAt, c.Op, c.Time)
+	case FieldUpdatedAt:
+		return compareTime(agent.UpdatedAt, c.Op, c.Time)
+	}
+	return false
+}
+
+// containsAnyWords reports whether s holds every word of any value. Words
+// are compared whole, so "bill" does not match "billing".
+func containsAnyWords(s string, values []string) bool {
+	have := textWords(s)
+	for _, v := range values {
+		want := textWords(v)
+		if len(want) > 0 && matchList(have, OpAll, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// textWords lowercases s and splits it into words on anything that is not a
+// letter or digit, like Qdrant's word tokenizer.
+func textWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchList(have []string, op FilterOp, values []string) bool {
+	if op == OpAll {
+		for _, v := range values {
+			if !slices.Contains(have, v) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if slices.Contains(have, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareTime compares at second precision, matching what Qdrant stores.
+func compareTime(t time.Time, op FilterOp, operand time.Time) bool {
+	a, b := t.Unix(), operand.Unix()
+	switch op {
+	case OpGt:
+		return a > b
+	case OpGte:
+		return a >= b
+	case OpLt:
+		return a < b
+	case OpLte:
+		return a <= b
+	}
+	return false
+}
+
+// String formats the expression in the syntax accepted by ParseFilter.
+func (e *FilterExpr) String() string {
+	switch {
+	case e == nil:
+		return ""
+	case len(e.And) > 0:
+		return joinExprs(e.And, " AND ")
+	case len(e.Or) > 0:
+		return joinExprs(e.Or, " OR ")
+	case e.Not != nil:
+		return "NOT (" + e.Not.String() + ")"
+	case e.Cond != nil:
+		return e.Cond.String()
+	}
+	return ""
+}
+
+func joinExprs(exprs []*FilterExpr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, sub := range exprs {
+		parts[i] = "(" + sub.String() + ")"
+	}
+	return strings.Join(parts, sep)
+}
+
+// String formats the condition in the syntax accepted by ParseFilter.
+func (c *FilterCond) String() string {
+	if c.isRange() {
+		return fmt.Sprintf("%s %s %s", c.Field, c.Op, c.Time.UTC().Format(time.RFC3339))
+	}
+	quoted := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		quoted[i] = strconv.Quote(v)
+	}
+	if len(quoted) == 1 && c.Op != OpAll {
+		op := "="
+		if c.Op == OpContains {
+			op = "~"
+		}
+		return fmt.Sprintf("%s %s %s", c.Field, op, quoted[0])
+	}
+	return fmt.Sprintf("%s %s (%s)", c.Field, c.Op, strings.Join(quoted, ", "))
+}
+
+// ParseFilter parses a filter expression such as
+//
+//	tags in (prod, beta) AND NOT skills all (translate, summarize)
+//	OR (name ~ "billing" AND created_at >= 2025-01-01)
+//
+// Conditions are "field op value" or "field op (value, ...)". Operators are
+// = and in (any value matches), != (no value matches), all (list contains
+// every value), ~ (text holds every word of the value; "bill" does not
+// match "billing") and >, >=, <, <= for created_at and updated_at, which
+// accept RFC 3339 timestamps or YYYY-MM-DD dates.
+// Conditions combine with AND, OR, NOT and parentheses; AND binds tighter
+// than OR. An empty string yields a nil expression.
+func ParseFilter(input string) (*FilterExpr, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, p.peek().text)
+	}
+	if err := expr.Validate(); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// filterTokenKind classifies filter tokens.
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// filterToken is a lexical token of a filter expression.
+type filterToken struct {
+	// kind is the token class.
+	kind filterTokenKind
+	// text is the token text, unquoted for strings.
+	text string
+}
+
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: tokenLParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: tokenRParen, text: ")"})
+			i++
+		case c == ',':
+			tokens = append(tokens, filterToken{kind: tokenComma, text: ","})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			text, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad string %s", ErrInvalidFilter, input[i:end+1])
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: text})
+			i = end + 1
+		case strings.ContainsRune("=!~<>", rune(c)):
+			end := i + 1
+			if end < len(input) && input[end] == '=' {
+				end++
+			}
+			op := input[i:end]
+			if op == "!" || op == "~=" || op == "==" {
+				return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op)
+			}
+			tokens = append(tokens, filterToken{kind: tokenOp, text: op})
+			i = end
+		default:
+			end := i
+			for end < len(input) && isWordByte(input[end]) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalidFilter, c)
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: input[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func isWordByte(c byte) bool {
+	return c > unicode.MaxASCII || !strings.ContainsRune(" \t\n\r(),\"=!~<>", rune(c))
+}
+
+// filterParser is a recursive descent parser over filter tokens.
+type filterParser struct {
+	// tokens is the token stream.
+	tokens []filterToken
+	// pos is the index of the next token.
+	pos int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.done() {
+		return filterToken{}, fmt.Errorf("%w: unexpected end of expression", ErrInvalidFilter)
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+// keyword reports whether the next token is the given case-insensitive word.
+func (p *filterParser) keyword(word string) bool {
+	tok := p.peek()
+	return !p.done() && tok.kind == tokenWord && strings.EqualFold(tok.text, word)
+}
+
+func (p *filterParser) parseOr() (*FilterExpr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []*FilterExpr{first}
+	for p.keyword("OR") {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, next)
+	}
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &FilterExpr{Or: exprs}, nil
+}
+
+func (p *filterParser) parseAnd() (*FilterExpr, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []*FilterExpr{first}
+	for p.keyword("AND") {
+		p.pos++
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, next)
+	}
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &FilterExpr{And: exprs}, nil
+}
+
+func (p *filterParser) parseUnary() (*FilterExpr, error) {
+	if p.keyword("NOT") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &FilterExpr{Not: inner}, nil
+	}
+	if p.peek().kind == tokenLParen && !p.done() {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, err := p.next(); err != nil || tok.kind != tokenRParen {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidFilter)
+		}
+		return inner, nil
+	}
+	return p.parseCond()
+}
+
+func (p *filterParser) parseCond() (*FilterExpr, error) {
+	fieldTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if fieldTok.kind != tokenWord {
+		return nil, fmt.Errorf("%w: expected field, got %q", ErrInvalidFilter, fieldTok.text)
+	}
+	field := FilterField(strings.ToLower(fieldTok.text))
+
+	opTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var op FilterOp
+	negate := false
+	switch strings.ToLower(opTok.text) {
+	case "=", "in":
+		op = OpAny
+	case "!=":
+		op, negate = OpAny, true
+	case "all":
+		op = OpAll
+	case "~":
+		op = OpContains
+	case ">", ">=", "<", "<=":
+		op = FilterOp(opTok.text)
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, opTok.text)
+	}
+
+	values, err := p.parseValues()
+	if err != nil {
+		return nil, err
+	}
+
+	cond := &FilterCond{Field: field, Op: op, Values: values}
+	if cond.isRange() {
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%w: %s %s takes a single timestamp", ErrInvalidFilter, field, op)
+		}
+		cond.Time, err = parseFilterTime(values[0])
+		if err != nil {
+			return nil, err
+		}
+		cond.Values = nil
+	}
+
+	expr := &FilterExpr{Cond: cond}
+	if negate {
+		expr = &FilterExpr{Not: expr}
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseValues() ([]string, error) {
+	if p.peek().kind != tokenLParen || p.done() {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind != tokenWord && tok.kind != tokenString {
+			return nil, fmt.Errorf("%w: expected value, got %q", ErrInvalidFilter, tok.text)
+		}
+		return []string{tok.text}, nil
+	}
+
+	p.pos++
+	var values []string
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind != tokenWord && tok.kind != tokenString {
+			return nil, fmt.Errorf("%w: expected value, got %q", ErrInvalidFilter, tok.text)
+		}
+		values = append(values, tok.text)
+
+		sep, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if sep.kind == tokenRParen {
+			return values, nil
+		}
+		if sep.kind != tokenComma {
+			return nil, fmt.Errorf("%w: expected , or ), got %q", ErrInvalidFilter, sep.text)
+		}
+	}
+}
+
+func parseFilterTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: bad timestamp %q, want RFC 3339 or YYYY-MM-DD", ErrInvalidFilter, s)
+}
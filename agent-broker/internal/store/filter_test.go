@@ -0,0 +1,110 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+func filterTestAgent() *RegisteredAgent {
+	agent := validAgent("billing-bot")
+	agent.Card.Name = "Billing Assistant"
+	agent.Card.Description = "Answers invoice questions"
+	agent.Card.Version = "2.1.0"
+	agent.Card.Skills = []a2a.AgentSkill{
+		{ID: "invoices", Name: "Invoices"},
+		{ID: "refunds", Name: "Refunds"},
+	}
+	agent.Tags = []string{"prod", "finance"}
+	agent.CreatedAt = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	agent.UpdatedAt = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	return agent
+}
+
+func TestParseFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "empty matches all", input: "", want: true},
+		{name: "tag equals", input: "tags = prod", want: true},
+		{name: "tag any", input: "tags in (beta, finance)", want: true},
+		{name: "tag any miss", input: "tags in (beta, dev)", want: false},
+		{name: "skills all", input: "skills all (invoices, refunds)", want: true},
+		{name: "skills all miss", input: "skills all (invoices, payroll)", want: false},
+		{name: "tag exclusion", input: "tags != prod", want: false},
+		{name: "not keyword", input: "NOT tags = beta", want: true},
+		{name: "text contains", input: `name ~ "billing"`, want: true},
+		{name: "description contains", input: "description ~ INVOICE", want: true},
+		{name: "text contains every word", input: `description ~ "questions, invoice"`, want: true},
+		{name: "text missing word", input: `description ~ "invoice refunds"`, want: false},
+		{name: "text partial word", input: "name ~ bill", want: false},
+		{name: "id and version", input: "id = billing-bot AND version in (2.0.0, 2.1.0)", want: true},
+		{name: "created after date", input: "created_at >= 2025-01-01", want: true},
+		{name: "created before date", input: "created_at < 2025-01-01", want: false},
+		{name: "updated range", input: "updated_at > 2025-05-31T00:00:00Z and updated_at <= 2025-06-01T12:00:00Z", want: true},
+		{name: "or", input: "tags = beta OR skills = refunds", want: true},
+		{name: "and binds tighter than or", input: "tags = beta AND skills = refunds OR tags = prod", want: true},
+		{name: "parentheses", input: "tags = beta AND (skills = refunds OR tags = prod)", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := ParseFilter(tt.input)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error = %v", tt.input, err)
+			}
+
+			if got := expr.Match(filterTestAgent()); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+
+			// The canonical form must parse back to an equivalent expression.
+			reparsed, err := ParseFilter(expr.String())
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error = %v", expr.String(), err)
+			}
+			if got := reparsed.Match(filterTestAgent()); got != tt.want {
+				t.Errorf("reparsed Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "unknown field", input: "owner = alice"},
+		{name: "unsupported operator", input: "name = billing"},
+		{name: "range on list", input: "tags > prod"},
+		{name: "bad timestamp", input: "created_at > yesterday"},
+		{name: "missing value", input: "tags ="},
+		{name: "unclosed parenthesis", input: "(tags = prod"},
+		{name: "unclosed value list", input: "tags in (a, b"},
+		{name: "unterminated string", input: `name ~ "billing`},
+		{name: "dangling and", input: "tags = prod AND"},
+		{name: "trailing tokens", input: "tags = prod skills = x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseFilter(tt.input)
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter(%q) error = %v, want ErrInvalidFilter", tt.input, err)
+			}
+		})
+	}
+}
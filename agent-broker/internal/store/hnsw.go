@@ -0,0 +1,463 @@
+package store
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+)
+
+// HNSWOptions configures the approximate nearest-neighbor index.
+type HNSWOptions struct {
+	// M is the number of neighbors kept per node on upper layers.
+	// Layer 0 keeps 2*M neighbors. Values below 2 are raised to 2.
+	M int
+	// EfConstruction is the candidate list size used while inserting.
+	EfConstruction int
+	// EfSearch is the candidate list size used while searching.
+	// Higher values improve recall at the cost of latency.
+	EfSearch int
+	// Seed seeds the level generator so index layouts are reproducible.
+	Seed uint64
+}
+
+// DefaultHNSWOptions returns HNSWOptions with sensible defaults.
+func DefaultHNSWOptions() HNSWOptions {
+	return HNSWOptions{
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+		Seed:           1,
+	}
+}
+
+// hnswNode is a single vector in the graph.
+type hnswNode struct {
+	// id is the agent ID.
+	id string
+	// vec is the L2-normalized embedding.
+	vec []float32
+	// neighbors holds neighbor node indexes per layer.
+	neighbors [][]int
+	// inbound holds, per layer, the indexes of the nodes that list this
+	// node as a neighbor, so a delete repairs them without a full scan.
+	inbound []map[int]struct{}
+}
+
+// hnswIndex is a Hierarchical Navigable Small World graph over
+// cosine distance. It is not safe for concurrent mutation; MemoryStore
+// serializes writes with its own lock.
+type hnswIndex struct {
+	// opts holds the index configuration.
+	opts HNSWOptions
+	// levelMult is the normalization factor for level generation.
+	levelMult float64
+	// rng generates node levels.
+	rng *rand.Rand
+	// nodes holds graph nodes; deleted slots are nil.
+	nodes []*hnswNode
+	// free lists reusable node slots.
+	free []int
+	// byID maps agent IDs to node indexes.
+	byID map[string]int
+	// entry is the entry point node index, or -1 when empty.
+	entry int
+	// maxLevel is the level of the entry point.
+	maxLevel int
+}
+
+// newHNSWIndex creates an empty index.
+func newHNSWIndex(opts HNSWOptions) *hnswIndex {
+	defaults := DefaultHNSWOptions()
+	if opts.M <= 0 {
+		opts.M = defaults.M
+	}
+	// The level multiplier is 1/ln(M), which is infinite for M=1.
+	opts.M = max(opts.M, 2)
+	if opts.EfConstruction <= 0 {
+		opts.EfConstruction = defaults.EfConstruction
+	}
+	if opts.EfSearch <= 0 {
+		opts.EfSearch = defaults.EfSearch
+	}
+
+	return &hnswIndex{
+		opts:      opts,
+		levelMult: 1 / math.Log(float64(opts.M)),
+		rng:       rand.New(rand.NewPCG(opts.Seed, opts.Seed)),
+		byID:      make(map[string]int),
+		entry:     -1,
+	}
+}
+
+// Len returns the number of indexed vectors.
+func (h *hnswIndex) Len() int {
+	return len(h.byID)
+}
+
+// Insert adds or replaces the vector for id.
+func (h *hnswIndex) Insert(id string, vec []float32) {
+	h.Delete(id)
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	node := &hnswNode{
+		id:        id,
+		vec:       normalize(vec),
+		neighbors: make([][]int, level+1),
+		inbound:   make([]map[int]struct{}, level+1),
+	}
+	for lc := range node.inbound {
+		node.inbound[lc] = make(map[int]struct{})
+	}
+
+	idx := h.allocate(node)
+	h.byID[id] = idx
+
+	if h.entry < 0 {
+		h.entry = idx
+		h.maxLevel = level
+		return
+	}
+
+	ep := h.entry
+	for lc := h.maxLevel; lc > level; lc-- {
+		ep = h.searchLayer(node.vec, []int{ep}, 1, lc)[0].idx
+	}
+
+	eps := []int{ep}
+	for lc := min(level, h.maxLevel); lc >= 0; lc-- {
+		candidates := h.searchLayer(node.vec, eps, h.opts.EfConstruction, lc)
+		neighbors := h.selectNeighbors(candidates, h.opts.M)
+		h.setNeighbors(idx, lc, neighbors)
+
+		for _, n := range neighbors {
+			h.link(n, idx, lc)
+		}
+
+		eps = eps[:0]
+		for _, c := range candidates {
+			eps = append(eps, c.idx)
+		}
+	}
+
+	if level > h.maxLevel {
+		h.entry = idx
+		h.maxLevel = level
+	}
+}
+
+// Delete removes the vector for id, repairing the neighbors it leaves behind.
+func (h *hnswIndex) Delete(id string) {
+	idx, ok := h.byID[id]
+	if !ok {
+		return
+	}
+	node := h.nodes[idx]
+	delete(h.byID, id)
+	h.nodes[idx] = nil
+	h.free = append(h.free, idx)
+
+	// Edges are directed, so repair the nodes pointing at the deleted one
+	// and drop its own edges from its neighbors' inbound sets.
+	for lc, inbound := range node.inbound {
+		for n := range inbound {
+			h.repair(n, idx, node.neighbors[lc], lc)
+		}
+	}
+	for lc, neighbors := range node.neighbors {
+		for _, n := range neighbors {
+			if other := h.nodes[n]; other != nil {
+				delete(other.inbound[lc], idx)
+			}
+		}
+	}
+
+	if h.entry == idx {
+		h.entry = -1
+		h.maxLevel = 0
+		for i, n := range h.nodes {
+			if n != nil && (h.entry < 0 || len(n.neighbors)-1 > h.maxLevel) {
+				h.entry = i
+				h.maxLevel = len(n.neighbors) - 1
+			}
+		}
+	}
+}
+
+// Search returns up to ef nearest node IDs to query ordered by similarity.
+func (h *hnswIndex) Search(query []float32, ef int) []string {
+	if h.entry < 0 {
+		return nil
+	}
+	ef = max(ef, h.opts.EfSearch)
+	q := normalize(query)
+
+	ep := h.entry
+	for lc := h.maxLevel; lc > 0; lc-- {
+		ep = h.searchLayer(q, []int{ep}, 1, lc)[0].idx
+	}
+
+	candidates := h.searchLayer(q, []int{ep}, ef, 0)
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = h.nodes[c.idx].id
+	}
+	return ids
+}
+
+// allocate stores node in a free slot and returns its index.
+func (h *hnswIndex) allocate(node *hnswNode) int {
+	if n := len(h.free); n > 0 {
+		idx := h.free[n-1]
+		h.free = h.free[:n-1]
+		h.nodes[idx] = node
+		return idx
+	}
+	h.nodes = append(h.nodes, node)
+	return len(h.nodes) - 1
+}
+
+// maxNeighbors returns the neighbor cap for a layer.
+func (h *hnswIndex) maxNeighbors(layer int) int {
+	if layer == 0 {
+		return 2 * h.opts.M
+	}
+	return h.opts.M
+}
+
+// link adds a directed edge from -> to, pruning from's list if it overflows.
+func (h *hnswIndex) link(from, to, layer int) {
+	node := h.nodes[from]
+	neighbors := append(slices.Clone(node.neighbors[layer]), to)
+	if len(neighbors) > h.maxNeighbors(layer) {
+		neighbors = h.prune(node.vec, neighbors, h.maxNeighbors(layer))
+	}
+	h.setNeighbors(from, layer, neighbors)
+}
+
+// setNeighbors replaces n's neighbors on a layer and updates the inbound
+// sets of the nodes it stops or starts pointing at.
+func (h *hnswIndex) setNeighbors(n, layer int, neighbors []int) {
+	node := h.nodes[n]
+	for _, old := range node.neighbors[layer] {
+		if other := h.nodes[old]; other != nil {
+			delete(other.inbound[layer], n)
+		}
+	}
+	node.neighbors[layer] = neighbors
+	for _, c := range neighbors {
+		h.nodes[c].inbound[layer][n] = struct{}{}
+	}
+}
+
+// repair removes a deleted node from n's neighbors on a layer and
+// reconnects n through the deleted node's neighbors.
+func (h *hnswIndex) repair(n, deleted int, orphans []int, layer int) {
+	node := h.nodes[n]
+	if node == nil || layer >= len(node.neighbors) {
+		return
+	}
+
+	seen := map[int]bool{n: true, deleted: true}
+	merged := make([]int, 0, len(node.neighbors[layer])+len(orphans))
+	for _, list := range [][]int{node.neighbors[layer], orphans} {
+		for _, c := range list {
+			if seen[c] || h.nodes[c] == nil || layer >= len(h.nodes[c].neighbors) {
+				continue
+			}
+			seen[c] = true
+			merged = append(merged, c)
+		}
+	}
+
+	h.setNeighbors(n, layer, h.prune(node.vec, merged, h.maxNeighbors(layer)))
+}
+
+// prune keeps the limit candidates closest to vec.
+func (h *hnswIndex) prune(vec []float32, candidates []int, limit int) []int {
+	scored := make([]hnswCandidate, len(candidates))
+	for i, c := range candidates {
+		scored[i] = hnswCandidate{idx: c, dist: cosineDistance(vec, h.nodes[c].vec)}
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].dist < scored[j].dist
+	})
+	return h.selectNeighbors(scored, limit)
+}
+
+// selectNeighbors picks up to limit neighbors from candidates sorted by
+// ascending distance, preferring candidates that are closer to the base
+// node than to any already selected neighbor. This keeps edges spread in
+// different directions, which improves recall on clustered data. Skipped
+// candidates fill any remaining slots.
+func (h *hnswIndex) selectNeighbors(sorted []hnswCandidate, limit int) []int {
+	if len(sorted) <= limit {
+		return closest(sorted, limit)
+	}
+
+	selected := make([]int, 0, limit)
+	var skipped []int
+	for _, c := range sorted {
+		if len(selected) == limit {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if cosineDistance(h.nodes[c.idx].vec, h.nodes[s].vec) < c.dist {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c.idx)
+		} else {
+			skipped = append(skipped, c.idx)
+		}
+	}
+
+	for _, idx := range skipped {
+		if len(selected) == limit {
+			break
+		}
+		selected = append(selected, idx)
+	}
+	return selected
+}
+
+// searchLayer runs a greedy best-first search on one layer and returns
+// up to ef candidates ordered by ascending distance.
+func (h *hnswIndex) searchLayer(q []float32, eps []int, ef, layer int) []hnswCandidate {
+	visited := newBitset(len(h.nodes))
+	candidates := &minHeap{}
+	results := &maxHeap{}
+
+	for _, ep := range eps {
+		if visited.testAndSet(ep) {
+			continue
+		}
+		c := hnswCandidate{idx: ep, dist: cosineDistance(q, h.nodes[ep].vec)}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.dist > (*results)[0].dist {
+			break
+		}
+
+		for _, n := range h.nodes[c.idx].neighbors[layer] {
+			if visited.testAndSet(n) {
+				continue
+			}
+
+			d := cosineDistance(q, h.nodes[n].vec)
+			if results.Len() < ef || d < (*results)[0].dist {
+				heap.Push(candidates, hnswCandidate{idx: n, dist: d})
+				heap.Push(results, hnswCandidate{idx: n, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]hnswCandidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return out
+}
+
+// bitset tracks visited node indexes during a search.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, n/64+1)
+}
+
+// testAndSet marks i as visited and reports whether it already was.
+func (b bitset) testAndSet(i int) bool {
+	word, bit := i/64, uint64(1)<<(i%64)
+	seen := b[word]&bit != 0
+	b[word] |= bit
+	return seen
+}
+
+// hnswCandidate is a node index with its distance to the query.
+type hnswCandidate struct {
+	idx  int
+	dist float32
+}
+
+// closest returns the indexes of the first limit candidates.
+func closest(sorted []hnswCandidate, limit int) []int {
+	n := min(limit, len(sorted))
+	out := make([]int, n)
+	for i := range n {
+		out[i] = sorted[i].idx
+	}
+	return out
+}
+
+// minHeap orders candidates by ascending distance.
+type minHeap []hnswCandidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(hnswCandidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// maxHeap orders candidates by descending distance.
+type maxHeap []hnswCandidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(hnswCandidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// normalize returns an L2-normalized copy of v.
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	inv := 1 / math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = float32(float64(x) * inv)
+	}
+	return out
+}
+
+// cosineDistance returns 1 - dot(a, b) for normalized vectors.
+// Vectors of different length are treated as orthogonal.
+func cosineDistance(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 1
+	}
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
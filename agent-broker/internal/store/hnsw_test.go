@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func randomVector(rng *rand.Rand, dim int) []float32 {
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = float32(rng.NormFloat64())
+	}
+	return v
+}
+
+// clusteredVector returns a point near one of a fixed set of topic centers,
+// which resembles real embedding distributions better than uniform noise.
+func clusteredVector(rng *rand.Rand, centers [][]float32) []float32 {
+	center := centers[rng.IntN(len(centers))]
+	v := make([]float32, len(center))
+	for i := range v {
+		v[i] = center[i] + 0.5*float32(rng.NormFloat64())
+	}
+	return v
+}
+
+// topicCenters returns a deterministic set of cluster centers.
+func topicCenters(dim int) [][]float32 {
+	rng := rand.New(rand.NewPCG(1, 2))
+	centers := make([][]float32, 50)
+	for i := range centers {
+		centers[i] = randomVector(rng, dim)
+	}
+	return centers
+}
+
+// populate creates n agents with clustered embeddings in every store.
+func populate(t testing.TB, n, dim int, stores ...*MemoryStore) {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(42, 42))
+	centers := topicCenters(dim)
+	for i := range n {
+		agent := validAgent(fmt.Sprintf("agent-%d", i))
+		agent.Embedding = clusteredVector(rng, centers)
+		if i%2 == 0 {
+			agent.Tags = []string{"even"}
+		}
+		for _, s := range stores {
+			if err := s.CreateAgent(context.Background(), agent); err != nil {
+				t.Fatalf("CreateAgent() error = %v", err)
+			}
+		}
+	}
+}
+
+// recallAt returns the fraction of exact top-k IDs found by the approximate search.
+func recallAt(exact, approx []ScoredAgent) float64 {
+	if len(exact) == 0 {
+		return 1
+	}
+	want := make(map[string]bool, len(exact))
+	for _, a := range exact {
+		want[a.Agent.ID] = true
+	}
+	hits := 0
+	for _, a := range approx {
+		if want[a.Agent.ID] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(exact))
+}
+
+func TestMemoryStore_SearchAgents_HNSW(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("recall matches brute force", func(t *testing.T) {
+		t.Parallel()
+		exact := NewMemoryStore()
+		approx := NewMemoryStore(WithHNSWIndex(DefaultHNSWOptions()))
+		populate(t, 1000, 32, exact, approx)
+
+		rng := rand.New(rand.NewPCG(7, 7))
+		centers := topicCenters(32)
+		var total float64
+		const queries = 50
+		for range queries {
+			q := clusteredVector(rng, centers)
+			want, _ := exact.SearchAgents(ctx, q, 10, AgentFilter{})
+			got, _ := approx.SearchAgents(ctx, q, 10, AgentFilter{})
+			total += recallAt(want.Agents, got.Agents)
+		}
+
+		if recall := total / queries; recall < 0.9 {
+			t.Errorf("recall@10 = %.2f, want >= 0.9", recall)
+		}
+	})
+
+	t.Run("M below 2 is clamped", func(t *testing.T) {
+		t.Parallel()
+		opts := DefaultHNSWOptions()
+		opts.M = 1
+		s := NewMemoryStore(WithHNSWIndex(opts))
+		populate(t, 50, 8, s)
+
+		result, err := s.SearchAgents(ctx, randomVector(rand.New(rand.NewPCG(1, 1)), 8), 5, AgentFilter{})
+
+		if err != nil {
+			t.Fatalf("SearchAgents() error = %v", err)
+		}
+		if len(result.Agents) != 5 {
+			t.Errorf("SearchAgents() returned %d agents, want 5", len(result.Agents))
+		}
+	})
+
+	t.Run("filter is applied to candidates", func(t *testing.T) {
+		t.Parallel()
+		s := NewMemoryStore(WithHNSWIndex(DefaultHNSWOptions()))
+		populate(t, 200, 16, s)
+
+		result, err := s.SearchAgents(ctx, randomVector(rand.New(rand.NewPCG(1, 1)), 16), 20, AgentFilter{Tags: []string{"even"}})
+
+		if err != nil {
+			t.Fatalf("SearchAgents() error = %v", err)
+		}
+		if len(result.Agents) != 20 {
+			t.Errorf("SearchAgents() got %d agents, want 20", len(result.Agents))
+		}
+		for _, a := range result.Agents {
+			if len(a.Agent.Tags) == 0 || a.Agent.Tags[0] != "even" {
+				t.Errorf("SearchAgents() returned %s without tag even", a.Agent.ID)
+			}
+		}
+	})
+
+	t.Run("deleted and updated agents are reindexed", func(t *testing.T) {
+		t.Parallel()
+		s := NewMemoryStore(WithHNSWIndex(DefaultHNSWOptions()))
+		populate(t, 100, 8, s)
+		target := []float32{1, 1, 1, 1, 1, 1, 1, 1}
+
+		moved := validAgent("agent-5")
+		moved.Embedding = target
+		_ = s.UpdateAgent(ctx, moved)
+
+		result, _ := s.SearchAgents(ctx, target, 1, AgentFilter{})
+		if len(result.Agents) != 1 || result.Agents[0].Agent.ID != "agent-5" {
+			t.Fatalf("SearchAgents() after update = %v, want agent-5", result.Agents)
+		}
+
+		_ = s.DeleteAgent(ctx, "agent-5")
+
+		result, _ = s.SearchAgents(ctx, target, 100, AgentFilter{})
+		for _, a := range result.Agents {
+			if a.Agent.ID == "agent-5" {
+				t.Error("SearchAgents() returned deleted agent")
+			}
+		}
+		if len(result.Agents) != 99 {
+			t.Errorf("SearchAgents() got %d agents, want 99", len(result.Agents))
+		}
+	})
+}
+
+func TestHNSWIndex_Delete(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewPCG(3, 3))
+	h := newHNSWIndex(HNSWOptions{M: 4})
+	for i := range 200 {
+		h.Insert(fmt.Sprintf("node-%d", i), randomVector(rng, 8))
+	}
+	for i := range 200 {
+		switch {
+		case i%3 == 0:
+			h.Delete(fmt.Sprintf("node-%d", i))
+		case i%3 == 1:
+			h.Insert(fmt.Sprintf("node-%d", i), randomVector(rng, 8))
+		}
+	}
+
+	if h.Len() != 133 {
+		t.Errorf("Len() = %d, want 133", h.Len())
+	}
+	// Every edge must be mirrored by an inbound entry and vice versa, or
+	// deletes leave dangling edges behind.
+	edges := 0
+	for n, node := range h.nodes {
+		if node == nil {
+			continue
+		}
+		for lc, neighbors := range node.neighbors {
+			for _, c := range neighbors {
+				if h.nodes[c] == nil {
+					t.Fatalf("node %d points at deleted node %d on layer %d", n, c, lc)
+				}
+				if _, ok := h.nodes[c].inbound[lc][n]; !ok {
+					t.Fatalf("edge %d -> %d on layer %d missing from inbound", n, c, lc)
+				}
+				edges++
+			}
+		}
+	}
+	inbound := 0
+	for _, node := range h.nodes {
+		if node == nil {
+			continue
+		}
+		for _, set := range node.inbound {
+			inbound += len(set)
+		}
+	}
+	if inbound != edges {
+		t.Errorf("inbound entries = %d, want %d edges", inbound, edges)
+	}
+}
+
+func BenchmarkMemoryStore_SearchAgents(b *testing.B) {
+	const dim = 384
+	ctx := context.Background()
+
+	for _, n := range []int{1000, 10000} {
+		exact := NewMemoryStore()
+		populate(b, n, dim, exact)
+
+		rng := rand.New(rand.NewPCG(7, 7))
+		centers := topicCenters(dim)
+		queries := make([][]float32, 100)
+		for i := range queries {
+			queries[i] = clusteredVector(rng, centers)
+		}
+
+		b.Run(fmt.Sprintf("bruteforce/n=%d", n), func(b *testing.B) {
+			for i := 0; b.Loop(); i++ {
+				_, _ = exact.SearchAgents(ctx, queries[i%len(queries)], 10, AgentFilter{})
+			}
+		})
+
+		for _, ef := range []int{16, 64, 256} {
+			opts := DefaultHNSWOptions()
+			opts.EfSearch = ef
+			approx := NewMemoryStore(WithHNSWIndex(opts))
+			populate(b, n, dim, approx)
+
+			b.Run(fmt.Sprintf("hnsw/n=%d/ef=%d", n, ef), func(b *testing.B) {
+				var total float64
+				for _, q := range queries {
+					want, _ := exact.SearchAgents(ctx, q, 10, AgentFilter{})
+					got, _ := approx.SearchAgents(ctx, q, 10, AgentFilter{})
+					total += recallAt(want.Agents, got.Agents)
+				}
+
+				for i := 0; b.Loop(); i++ {
+					_, _ = approx.SearchAgents(ctx, queries[i%len(queries)], 10, AgentFilter{})
+				}
+				b.ReportMetric(total/float64(len(queries)), "recall@10")
+			})
+		}
+	}
+}
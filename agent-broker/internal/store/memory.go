@@ -10,17 +10,45 @@ import (
 
 // MemoryStore implements AgentStore with in-memory storage.
 type MemoryStore struct {
-	// mu protects agents map.
+	// mu protects agents map and index.
 	mu sync.RWMutex
 	// agents is the in-memory agent storage.
 	agents map[string]*RegisteredAgent
+	// index is the optional approximate nearest-neighbor index.
+	index *hnswIndex
+}
+
+// MemoryOptions configures the MemoryStore.
+type MemoryOptions struct {
+	// HNSW enables the approximate nearest-neighbor index when set.
+	// When nil, SearchAgents performs an exact linear scan.
+	HNSW *HNSWOptions
+}
+
+// MemoryOption is a functional option for configuring MemoryStore.
+type MemoryOption func(*MemoryOptions)
+
+// WithHNSWIndex enables the approximate nearest-neighbor index.
+func WithHNSWIndex(opts HNSWOptions) MemoryOption {
+	return func(o *MemoryOptions) {
+		o.HNSW = &opts
+	}
 }
 
 // NewMemoryStore creates a new in-memory store.
-func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{
+func NewMemoryStore(opts ...MemoryOption) *MemoryStore {
+	var options MemoryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s := &MemoryStore{
 		agents: make(map[string]*RegisteredAgent),
 	}
+	if options.HNSW != nil {
+		s.index = newHNSWIndex(*options.HNSW)
+	}
+	return s
 }
 
 // Ping always returns nil for memory store.
@@ -43,6 +71,7 @@ func (s *MemoryStore) CreateAgent(_ context.Context, agent *RegisteredAgent) err
 	}
 
 	s.agents[agent.ID] = agent
+	s.indexAgent(nil, agent)
 	return nil
 }
 
@@ -91,11 +120,13 @@ func (s *MemoryStore) UpdateAgent(_ context.Context, agent *RegisteredAgent) err
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.agents[agent.ID]; !exists {
+	existing, exists := s.agents[agent.ID]
+	if !exists {
 		return ErrNotFound
 	}
 
 	s.agents[agent.ID] = agent
+	s.indexAgent(existing, agent)
 	return nil
 }
 
@@ -104,38 +135,78 @@ func (s *MemoryStore) DeleteAgent(_ context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.agents[id]; !exists {
+	existing, exists := s.agents[id]
+	if !exists {
 		return ErrNotFound
 	}
 
 	delete(s.agents, id)
+	s.indexAgent(existing, nil)
 	return nil
 }
 
+// UpsertAgents creates or replaces agents under a single lock.
+func (s *MemoryStore) UpsertAgents(_ context.Context, agents []*RegisteredAgent) ([]BatchItemResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]BatchItemResult, len(agents))
+	for i, agent := range agents {
+		existing, exists := s.agents[agent.ID]
+		if exists {
+			agent.CreatedAt = existing.CreatedAt
+		}
+		s.agents[agent.ID] = agent
+		s.indexAgent(existing, agent)
+		results[i] = BatchItemResult{ID: agent.ID, Created: !exists}
+	}
+
+	return results, nil
+}
+
+// DeleteAgents removes agents under a single lock.
+func (s *MemoryStore) DeleteAgents(_ context.Context, ids []string) ([]BatchItemResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]BatchItemResult, len(ids))
+	for i, id := range ids {
+		results[i] = BatchItemResult{ID: id}
+		existing, exists := s.agents[id]
+		if !exists {
+			results[i].Err = ErrNotFound
+			continue
+		}
+		delete(s.agents, id)
+		s.indexAgent(existing, nil)
+	}
+
+	return results, nil
+}
+
 // SearchAgents finds agents by vector similarity with optional filtering.
+// Each agent is scored by its best-matching profile, skill or tags vector.
+// With an HNSW index configured, results are approximate.
 func (s *MemoryStore) SearchAgents(_ context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if s.index != nil && limit > 0 {
+		return &SearchResult{Agents: s.searchIndex(query, limit, filter)}, nil
+	}
+
 	var scored []ScoredAgent
 	for _, agent := range s.agents {
 		if !matchesFilter(agent, filter) {
 			continue
 		}
-		if len(agent.Embedding) == 0 {
-			continue
-		}
 
-		score := cosineSimilarity(query, agent.Embedding)
-		scored = append(scored, ScoredAgent{
-			Agent: agent,
-			Score: score,
-		})
+		if match, ok := scoreAgent(query, agent); ok {
+			scored = append(scored, match)
+		}
 	}
 
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].Score > scored[j].Score
-	})
+	sortByScore(scored)
 
 	if limit > 0 && len(scored) > limit {
 		scored = scored[:limit]
@@ -144,6 +215,132 @@ func (s *MemoryStore) SearchAgents(_ context.Context, query []float32, limit int
 	return &SearchResult{Agents: scored}, nil
 }
 
+// searchIndex queries the HNSW index, widening the candidate list until
+// enough agents pass the filter or the whole index has been visited.
+func (s *MemoryStore) searchIndex(query []float32, limit int, filter AgentFilter) []ScoredAgent {
+	var scored []ScoredAgent
+	for ef := limit; ; ef *= 2 {
+		keys := s.index.Search(query, ef)
+
+		scored = scored[:0]
+		seen := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			id := agentIDFromVectorKey(key)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			agent := s.agents[id]
+			if !matchesFilter(agent, filter) {
+				continue
+			}
+			if match, ok := scoreAgent(query, agent); ok {
+				scored = append(scored, match)
+			}
+		}
+
+		if len(scored) >= limit || len(keys) >= s.index.Len() || len(keys) < ef {
+			break
+		}
+	}
+
+	sortByScore(scored)
+
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored
+}
+
+// indexAgent replaces old's vectors in the HNSW index with agent's, if enabled.
+// Either argument may be nil.
+func (s *MemoryStore) indexAgent(old, agent *RegisteredAgent) {
+	if s.index == nil {
+		return
+	}
+	if old != nil {
+		for key := range namedVectors(old) {
+			s.index.Delete(key)
+		}
+	}
+	if agent != nil {
+		for key, vec := range namedVectors(agent) {
+			s.index.Insert(key, vec)
+		}
+	}
+}
+
+// vectorKeySep separates the agent ID from the vector name in index keys.
+const vectorKeySep = "\x00"
+
+// namedVectors returns the agent's non-empty vectors keyed by index key.
+func namedVectors(agent *RegisteredAgent) map[string][]float32 {
+	vectors := make(map[string][]float32, len(agent.SkillEmbeddings)+2)
+	if len(agent.Embedding) > 0 {
+		vectors[agent.ID+vectorKeySep+"profile"] = agent.Embedding
+	}
+	for _, skill := range agent.SkillEmbeddings {
+		if len(skill.Vector) > 0 {
+			vectors[agent.ID+vectorKeySep+"skill:"+skill.SkillID] = skill.Vector
+		}
+	}
+	if len(agent.TagsEmbedding) > 0 {
+		vectors[agent.ID+vectorKeySep+"tags"] = agent.TagsEmbedding
+	}
+	return vectors
+}
+
+// agentIDFromVectorKey extracts the agent ID from an index key.
+func agentIDFromVectorKey(key string) string {
+	id, _, _ := strings.Cut(key, vectorKeySep)
+	return id
+}
+
+// scoreAgent scores an agent by its best-matching named vector and reports
+// its best-matching skill. It returns false if the agent has no vectors.
+func scoreAgent(query []float32, agent *RegisteredAgent) (ScoredAgent, bool) {
+	match := ScoredAgent{Agent: agent}
+	found := false
+	consider := func(vec []float32) float32 {
+		score := cosineSimilarity(query, vec)
+		if !found || score > match.Score {
+			match.Score = score
+		}
+		found = true
+		return score
+	}
+
+	if len(agent.Embedding) > 0 {
+		consider(agent.Embedding)
+	}
+
+	var bestSkill float32
+	for _, skill := range agent.SkillEmbeddings {
+		if len(skill.Vector) == 0 {
+			continue
+		}
+		score := consider(skill.Vector)
+		if match.MatchedSkill == "" || score > bestSkill {
+			bestSkill = score
+			match.MatchedSkill = skill.SkillID
+		}
+	}
+
+	if len(agent.TagsEmbedding) > 0 {
+		consider(agent.TagsEmbedding)
+	}
+
+	return match, found
+}
+
+// sortByScore orders scored agents by descending score.
+func sortByScore(scored []ScoredAgent) {
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+}
+
 // cosineSimilarity calculates the cosine similarity between two vectors.
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -165,6 +362,10 @@ func cosineSimilarity(a, b []float32) float32 {
 }
 
 func matchesFilter(agent *RegisteredAgent, filter AgentFilter) bool {
+	if !filter.Expr.Match(agent) {
+		return false
+	}
+
 	if len(filter.Tags) > 0 {
 		hasTag := false
 		for _, t := range filter.Tags {
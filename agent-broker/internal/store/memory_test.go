@@ -338,3 +338,146 @@ func TestMemoryStore_DeleteAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryStore_UpsertAgents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := NewMemoryStore()
+	existing := validAgent("agent-1")
+	existing.CreatedAt = time.Now().Add(-time.Hour)
+	_ = s.CreateAgent(ctx, existing)
+
+	updated := validAgent("agent-1")
+	updated.Card.Name = "Updated Name"
+	results, err := s.UpsertAgents(ctx, []*RegisteredAgent{updated, validAgent("agent-2")})
+
+	if err != nil {
+		t.Fatalf("UpsertAgents() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("UpsertAgents() got %d results, want 2", len(results))
+	}
+	if results[0].Created {
+		t.Error("UpsertAgents() agent-1 should be reported as updated")
+	}
+	if !results[1].Created {
+		t.Error("UpsertAgents() agent-2 should be reported as created")
+	}
+
+	agent, _ := s.GetAgent(ctx, "agent-1")
+	if agent.Card.Name != "Updated Name" {
+		t.Errorf("GetAgent() Name = %v, want Updated Name", agent.Card.Name)
+	}
+	if !agent.CreatedAt.Equal(existing.CreatedAt) {
+		t.Error("UpsertAgents() should preserve CreatedAt")
+	}
+}
+
+func TestMemoryStore_DeleteAgents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := NewMemoryStore()
+	_ = s.CreateAgent(ctx, validAgent("agent-1"))
+
+	results, err := s.DeleteAgents(ctx, []string{"agent-1", "not-exists"})
+
+	if err != nil {
+		t.Fatalf("DeleteAgents() error = %v", err)
+	}
+	if results[0].Err != nil {
+		t.Errorf("DeleteAgents() agent-1 error = %v, want nil", results[0].Err)
+	}
+	if results[1].Err != ErrNotFound {
+		t.Errorf("DeleteAgents() not-exists error = %v, want ErrNotFound", results[1].Err)
+	}
+	if _, err := s.GetAgent(ctx, "agent-1"); err != ErrNotFound {
+		t.Errorf("GetAgent() after delete should return ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryStore_SearchAgents_NamedVectors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newAgents := func() []*RegisteredAgent {
+		generalist := validAgent("generalist")
+		generalist.Embedding = []float32{1, 0, 0}
+
+		specialist := validAgent("specialist")
+		specialist.Embedding = []float32{0, 0, 1}
+		specialist.Card.Skills = []a2a.AgentSkill{
+			{ID: "translate", Name: "Translate"},
+			{ID: "summarize", Name: "Summarize"},
+		}
+		specialist.SkillEmbeddings = []SkillEmbedding{
+			{SkillID: "translate", Vector: []float32{0, 1, 0}},
+			{SkillID: "summarize", Vector: []float32{0.7, 0.7, 0}},
+		}
+
+		tagged := validAgent("tagged")
+		tagged.TagsEmbedding = []float32{-1, 0, 0}
+
+		return []*RegisteredAgent{generalist, specialist, tagged}
+	}
+
+	tests := []struct {
+		name      string
+		opts      []MemoryOption
+		query     []float32
+		wantID    string
+		wantSkill string
+	}{
+		{
+			name:      "skill vector outranks profile",
+			query:     []float32{0, 1, 0},
+			wantID:    "specialist",
+			wantSkill: "translate",
+		},
+		{
+			name:   "profile vector matches",
+			query:  []float32{1, 0, 0},
+			wantID: "generalist",
+		},
+		{
+			name:   "tags vector matches",
+			query:  []float32{-1, 0, 0},
+			wantID: "tagged",
+		},
+		{
+			name:      "skill vector with HNSW index",
+			opts:      []MemoryOption{WithHNSWIndex(DefaultHNSWOptions())},
+			query:     []float32{0, 1, 0},
+			wantID:    "specialist",
+			wantSkill: "translate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := NewMemoryStore(tt.opts...)
+			for _, agent := range newAgents() {
+				_ = s.CreateAgent(ctx, agent)
+			}
+
+			result, err := s.SearchAgents(ctx, tt.query, 3, AgentFilter{})
+
+			if err != nil {
+				t.Fatalf("SearchAgents() error = %v", err)
+			}
+			if len(result.Agents) != 3 {
+				t.Fatalf("SearchAgents() got %d agents, want 3", len(result.Agents))
+			}
+			top := result.Agents[0]
+			if top.Agent.ID != tt.wantID {
+				t.Errorf("SearchAgents() top = %s, want %s", top.Agent.ID, tt.wantID)
+			}
+			if top.MatchedSkill != tt.wantSkill {
+				t.Errorf("SearchAgents() MatchedSkill = %q, want %q", top.MatchedSkill, tt.wantSkill)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -91,8 +92,20 @@ type QdrantStore struct {
 	client *qdrant.Client
 	// collectionName is the name of the agents collection.
 	collectionName string
+	// migrated is true when startup migrated a legacy collection.
+	migrated bool
 }
 
+// Named vectors stored per point.
+const (
+	// vectorProfile is the card-level profile vector.
+	vectorProfile = "profile"
+	// vectorSkills is a multivector with one row per skill.
+	vectorSkills = "skills"
+	// vectorTags is the optional tags vector.
+	vectorTags = "tags"
+)
+
 // NewQdrantStore creates a QdrantStore with the given options.
 // VectorDimension must be set via WithVectorDimension().
 func NewQdrantStore(ctx context.Context, opts ...Option) (*QdrantStore, error) {
@@ -105,14 +118,9 @@ func NewQdrantStore(ctx context.Context, opts ...Option) (*QdrantStore, error) {
 		return nil, fmt.Errorf("VectorDimension must be set via WithVectorDimension()")
 	}
 
-	client, err := qdrant.NewClient(&qdrant.Config{
-		Host:   options.Host,
-		Port:   options.Port,
-		APIKey: options.APIKey,
-		UseTLS: options.UseTLS,
-	})
+	client, err := newQdrantClient(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create qdrant client: %w", err)
+		return nil, err
 	}
 
 	store := &QdrantStore{
@@ -133,34 +141,237 @@ func NewQdrantStore(ctx context.Context, opts ...Option) (*QdrantStore, error) {
 	return store, nil
 }
 
-// ensureCollection creates the collection if it doesn't exist.
+// CollectionDimension returns the vector dimension of the existing agents
+// collection, or 0 when it does not exist yet. It lets a caller that cannot
+// detect the embedding dimension reuse the one agents were stored with.
+func CollectionDimension(ctx context.Context, opts ...Option) (uint64, error) {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	client, err := newQdrantClient(options)
+	if err != nil {
+		return 0, err
+	}
+	store := &QdrantStore{client: client, collectionName: options.CollectionName}
+	defer func() { _ = store.Close() }()
+
+	exists, err := store.collectionOrAliasExists(ctx, options.CollectionName)
+	if err != nil || !exists {
+		return 0, err
+	}
+	return store.collectionDimension(ctx, options.CollectionName)
+}
+
+// newQdrantClient creates a gRPC client for the configured server.
+func newQdrantClient(options Options) (*qdrant.Client, error) {
+	client, err := qdrant.NewClient(&qdrant.Config{
+		Host:   options.Host,
+		Port:   options.Port,
+		APIKey: options.APIKey,
+		UseTLS: options.UseTLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create qdrant client: %w", err)
+	}
+	return client, nil
+}
+
+// ensureCollection creates the collection if it doesn't exist, migrates a
+// collection created before named vectors and brings the payload indexes
+// and fields of an existing collection up to date.
 func (s *QdrantStore) ensureCollection(ctx context.Context, opts Options) error {
-	exists, err := s.client.CollectionExists(ctx, opts.CollectionName)
+	exists, err := s.collectionOrAliasExists(ctx, opts.CollectionName)
 	if err != nil {
-		return fmt.Errorf("check collection exists: %w", err)
+		return err
 	}
 
+	migrated := migratedCollectionName(opts.CollectionName)
+	if !exists {
+		// A migration interrupted after dropping the legacy collection
+		// leaves the migrated one without its alias.
+		found, err := s.client.CollectionExists(ctx, migrated)
+		if err != nil {
+			return fmt.Errorf("check collection exists: %w", err)
+		}
+		if found {
+			if err := s.client.CreateAlias(ctx, opts.CollectionName, migrated); err != nil {
+				return fmt.Errorf("create alias: %w", err)
+			}
+			s.migrated = true
+			return s.checkDimension(ctx, opts.CollectionName, opts.VectorDimension)
+		}
+		return s.createCollection(ctx, opts.CollectionName, opts.VectorDimension)
+	}
+
+	legacy, err := s.isLegacyCollection(ctx, opts.CollectionName)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		if err := s.migrateCollection(ctx, opts.CollectionName, migrated, opts.VectorDimension); err != nil {
+			return err
+		}
+	} else if err := s.checkDimension(ctx, opts.CollectionName, opts.VectorDimension); err != nil {
+		return err
+	}
+
+	if err := s.ensurePayloadIndexes(ctx, opts.CollectionName); err != nil {
+		return err
+	}
+	if err := s.backfillCardVersion(ctx); err != nil {
+		return err
+	}
+	return s.backfillHasVectors(ctx)
+}
+
+// checkDimension returns ErrDimensionMismatch when the existing collection
+// stores vectors of another size than dim, which would fail every upsert
+// and search.
+func (s *QdrantStore) checkDimension(ctx context.Context, name string, dim uint64) error {
+	stored, err := s.collectionDimension(ctx, name)
+	if err != nil {
+		return err
+	}
+	if stored != dim {
+		return fmt.Errorf("%w: collection %q stores %d-dimensional vectors, embedder produces %d",
+			ErrDimensionMismatch, name, stored, dim)
+	}
+	return nil
+}
+
+// collectionDimension returns the size of the collection's profile vector,
+// or of its single unnamed vector for a legacy collection.
+func (s *QdrantStore) collectionDimension(ctx context.Context, name string) (uint64, error) {
+	info, err := s.client.GetCollectionInfo(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("get collection info: %w", err)
+	}
+
+	vectors := info.GetConfig().GetParams().GetVectorsConfig()
+	if profile, ok := vectors.GetParamsMap().GetMap()[vectorProfile]; ok {
+		return profile.GetSize(), nil
+	}
+	return vectors.GetParams().GetSize(), nil
+}
+
+// backfillCardVersion sets the card_version payload field on agents stored
+// before it was indexed, so version filters match them.
+func (s *QdrantStore) backfillCardVersion(ctx context.Context) error {
+	points, err := s.scrollAll(ctx, &qdrant.Filter{
+		Must: []*qdrant.Condition{qdrant.NewIsEmpty("card_version")},
+	})
+	if err != nil {
+		return fmt.Errorf("find agents without card_version: %w", err)
+	}
+
+	byVersion := make(map[string][]*qdrant.PointId)
+	for _, point := range points {
+		var card a2a.AgentCard
+		if err := json.Unmarshal([]byte(point.Payload["card"].GetStringValue()), &card); err != nil {
+			return fmt.Errorf("unmarshal agent card: %w", err)
+		}
+		byVersion[card.Version] = append(byVersion[card.Version], point.Id)
+	}
+
+	for version, ids := range byVersion {
+		_, err := s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+			CollectionName: s.collectionName,
+			Wait:           qdrant.PtrOf(true),
+			Payload:        qdrant.NewValueMap(map[string]any{"card_version": version}),
+			PointsSelector: qdrant.NewPointsSelector(ids...),
+		})
+		if err != nil {
+			return fmt.Errorf("backfill card_version: %w", err)
+		}
+	}
+	return nil
+}
+
+// backfillHasVectors sets the has_vectors payload field on agents stored
+// with vectors before it was written, so listings, which do not load
+// vectors, report them as embedded.
+func (s *QdrantStore) backfillHasVectors(ctx context.Context) error {
+	_, err := s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Payload:        qdrant.NewValueMap(map[string]any{"has_vectors": true}),
+		PointsSelector: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewHasVector(vectorProfile),
+				qdrant.NewIsEmpty("has_vectors"),
+			},
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("backfill has_vectors: %w", err)
+	}
+	return nil
+}
+
+// collectionOrAliasExists reports whether name is a collection or an alias
+// of one.
+func (s *QdrantStore) collectionOrAliasExists(ctx context.Context, name string) (bool, error) {
+	exists, err := s.client.CollectionExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("check collection exists: %w", err)
+	}
 	if exists {
-		return nil
+		return true, nil
 	}
 
-	err = s.client.CreateCollection(ctx, &qdrant.CreateCollection{
-		CollectionName: opts.CollectionName,
-		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
-			Size:     opts.VectorDimension,
-			Distance: qdrant.Distance_Cosine,
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		return false, fmt.Errorf("list aliases: %w", err)
+	}
+	for _, alias := range aliases {
+		if alias.GetAliasName() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createCollection creates a collection with the named vectors and its
+// payload indexes.
+func (s *QdrantStore) createCollection(ctx context.Context, name string, dim uint64) error {
+	err := s.client.CreateCollection(ctx, &qdrant.CreateCollection{
+		CollectionName: name,
+		VectorsConfig: qdrant.NewVectorsConfigMap(map[string]*qdrant.VectorParams{
+			vectorProfile: {
+				Size:     dim,
+				Distance: qdrant.Distance_Cosine,
+			},
+			vectorSkills: {
+				Size:     dim,
+				Distance: qdrant.Distance_Cosine,
+				MultivectorConfig: &qdrant.MultiVectorConfig{
+					Comparator: qdrant.MultiVectorComparator_MaxSim,
+				},
+			},
+			vectorTags: {
+				Size:     dim,
+				Distance: qdrant.Distance_Cosine,
+			},
 		}),
 	})
 	if err != nil {
 		return fmt.Errorf("create collection: %w", err)
 	}
 
-	// Create payload indexes for efficient filtering
+	return s.ensurePayloadIndexes(ctx, name)
+}
+
+// ensurePayloadIndexes creates the payload indexes used for filtering.
+// Creating an index that already exists is a no-op, so indexes added in
+// later releases are created on existing collections at startup.
+func (s *QdrantStore) ensurePayloadIndexes(ctx context.Context, name string) error {
 	// Index on agent ID for lookups
-	keywordIndexes := []string{"id", "tags", "skill_ids"}
+	keywordIndexes := []string{"id", "tags", "skill_ids", "card_version"}
 	for _, field := range keywordIndexes {
-		_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-			CollectionName: opts.CollectionName,
+		_, err := s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: name,
 			FieldName:      field,
 			FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeKeyword),
 		})
@@ -171,8 +382,8 @@ func (s *QdrantStore) ensureCollection(ctx context.Context, opts Options) error
 
 	textIndexes := []string{"card_name", "card_description"}
 	for _, field := range textIndexes {
-		_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-			CollectionName: opts.CollectionName,
+		_, err := s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: name,
 			FieldName:      field,
 			FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeText),
 		})
@@ -181,27 +392,113 @@ func (s *QdrantStore) ensureCollection(ctx context.Context, opts Options) error
 		}
 	}
 
-	// Create integer index with range support for ordering
-	_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-		CollectionName: opts.CollectionName,
-		FieldName:      "created_at",
-		FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeInteger),
-		FieldIndexParams: &qdrant.PayloadIndexParams{
-			IndexParams: &qdrant.PayloadIndexParams_IntegerIndexParams{
-				IntegerIndexParams: &qdrant.IntegerIndexParams{
-					Lookup: qdrant.PtrOf(true),
-					Range:  qdrant.PtrOf(true),
+	// Create integer indexes with range support for ordering and date filters
+	integerIndexes := []string{"created_at", "updated_at"}
+	for _, field := range integerIndexes {
+		_, err := s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: name,
+			FieldName:      field,
+			FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeInteger),
+			FieldIndexParams: &qdrant.PayloadIndexParams{
+				IndexParams: &qdrant.PayloadIndexParams_IntegerIndexParams{
+					IntegerIndexParams: &qdrant.IntegerIndexParams{
+						Lookup: qdrant.PtrOf(true),
+						Range:  qdrant.PtrOf(true),
+					},
 				},
 			},
-		},
-	})
+		})
+		if err != nil {
+			return fmt.Errorf("create %s index: %w", field, err)
+		}
+	}
+
+	return nil
+}
+
+// isLegacyCollection reports whether an existing collection predates named
+// vectors and stores a single unnamed vector per point.
+func (s *QdrantStore) isLegacyCollection(ctx context.Context, name string) (bool, error) {
+	info, err := s.client.GetCollectionInfo(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("get collection info: %w", err)
+	}
+
+	params := info.GetConfig().GetParams().GetVectorsConfig().GetParamsMap().GetMap()
+	for _, vector := range []string{vectorProfile, vectorSkills, vectorTags} {
+		if _, ok := params[vector]; !ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// migratedCollectionName is the collection a legacy collection is migrated
+// into. The legacy name becomes an alias of it.
+func migratedCollectionName(name string) string {
+	return name + "_named"
+}
+
+// migrateCollection copies the agents of a legacy collection into a new
+// collection with named vectors, drops the legacy collection and points an
+// alias with its name at the new one. Payloads are copied as is and the
+// old vectors are dropped: the agents have no vectors until they are
+// re-embedded, and discovery falls back to lexical search for them.
+func (s *QdrantStore) migrateCollection(ctx context.Context, name, migrated string, dim uint64) error {
+	// Start over if an earlier migration was interrupted before the
+	// legacy collection was dropped.
+	partial, err := s.client.CollectionExists(ctx, migrated)
+	if err != nil {
+		return fmt.Errorf("check collection exists: %w", err)
+	}
+	if partial {
+		if err := s.client.DeleteCollection(ctx, migrated); err != nil {
+			return fmt.Errorf("delete partial migration: %w", err)
+		}
+	}
+	if err := s.createCollection(ctx, migrated, dim); err != nil {
+		return err
+	}
+
+	points, err := s.scrollAll(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("create created_at index: %w", err)
+		return fmt.Errorf("read legacy agents: %w", err)
+	}
+	for batch := range slices.Chunk(points, 100) {
+		copies := make([]*qdrant.PointStruct, len(batch))
+		for i, point := range batch {
+			copies[i] = &qdrant.PointStruct{
+				Id:      point.Id,
+				Vectors: qdrant.NewVectorsMap(map[string]*qdrant.Vector{}),
+				Payload: point.Payload,
+			}
+		}
+		_, err := s.client.Upsert(ctx, &qdrant.UpsertPoints{
+			CollectionName: migrated,
+			Wait:           qdrant.PtrOf(true),
+			Points:         copies,
+		})
+		if err != nil {
+			return fmt.Errorf("copy legacy agents: %w", err)
+		}
 	}
 
+	if err := s.client.DeleteCollection(ctx, name); err != nil {
+		return fmt.Errorf("delete legacy collection: %w", err)
+	}
+	if err := s.client.CreateAlias(ctx, name, migrated); err != nil {
+		return fmt.Errorf("create alias: %w", err)
+	}
+	s.migrated = true
 	return nil
 }
 
+// Migrated reports whether the store migrated a legacy collection at
+// startup. The migrated agents have no vectors until they are re-embedded.
+func (s *QdrantStore) Migrated() bool {
+	return s.migrated
+}
+
 // Ping checks if Qdrant is reachable and healthy.
 func (s *QdrantStore) Ping(ctx context.Context) error {
 	_, err := s.client.HealthCheck(ctx)
@@ -244,7 +541,7 @@ func (s *QdrantStore) CreateAgent(ctx context.Context, agent *RegisteredAgent) e
 		Points: []*qdrant.PointStruct{
 			{
 				Id:      qdrant.NewID(pointID),
-				Vectors: qdrant.NewVectorsDense(agent.Embedding),
+				Vectors: agentToVectors(agent),
 				Payload: payload,
 			},
 		},
@@ -292,14 +589,7 @@ func (s *QdrantStore) GetAgent(ctx context.Context, id string) (*RegisteredAgent
 	if err != nil {
 		return nil, fmt.Errorf("parse payload: %w", err)
 	}
-
-	if point.Vectors != nil {
-		if vec := point.Vectors.GetVector(); vec != nil {
-			if dense := vec.GetDense(); dense != nil {
-				agent.Embedding = dense.GetData()
-			}
-		}
-	}
+	applyVectors(agent, point.Vectors, point.Payload)
 
 	return agent, nil
 }
@@ -402,7 +692,7 @@ func (s *QdrantStore) UpdateAgent(ctx context.Context, agent *RegisteredAgent) e
 		Points: []*qdrant.PointStruct{
 			{
 				Id:      point.Id,
-				Vectors: qdrant.NewVectorsDense(agent.Embedding),
+				Vectors: agentToVectors(agent),
 				Payload: payload,
 			},
 		},
@@ -437,47 +727,252 @@ func (s *QdrantStore) DeleteAgent(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpsertAgents creates or replaces agents with a single multi-point upsert.
+func (s *QdrantStore) UpsertAgents(ctx context.Context, agents []*RegisteredAgent) ([]BatchItemResult, error) {
+	if len(agents) == 0 {
+		return []BatchItemResult{}, nil
+	}
+
+	ids := make([]string, len(agents))
+	for i, agent := range agents {
+		ids[i] = agent.ID
+	}
+
+	existing, err := s.findPointsByAgentIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("find agents: %w", err)
+	}
+
+	results := make([]BatchItemResult, len(agents))
+	points := make([]*qdrant.PointStruct, 0, len(agents))
+	// assigned tracks point IDs given to new agents so that duplicate IDs
+	// in one batch update the same point.
+	assigned := make(map[string]*qdrant.PointId)
+	for i, agent := range agents {
+		point, exists := existing[agent.ID]
+		pointID, seen := assigned[agent.ID]
+		switch {
+		case exists:
+			pointID = point.Id
+			agent.CreatedAt = time.Unix(point.Payload["created_at"].GetIntegerValue(), 0)
+		case !seen:
+			pointID = qdrant.NewID(uuid.New().String())
+			assigned[agent.ID] = pointID
+		}
+		exists = exists || seen
+
+		payload, err := agentToPayload(agent)
+		if err != nil {
+			return nil, fmt.Errorf("build payload for %s: %w", agent.ID, err)
+		}
+
+		points = append(points, &qdrant.PointStruct{
+			Id:      pointID,
+			Vectors: agentToVectors(agent),
+			Payload: payload,
+		})
+		results[i] = BatchItemResult{ID: agent.ID, Created: !exists}
+	}
+
+	_, err = s.client.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         points,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upsert points: %w", err)
+	}
+
+	return results, nil
+}
+
+// DeleteAgents removes agents with a single multi-point delete.
+func (s *QdrantStore) DeleteAgents(ctx context.Context, ids []string) ([]BatchItemResult, error) {
+	if len(ids) == 0 {
+		return []BatchItemResult{}, nil
+	}
+
+	existing, err := s.findPointsByAgentIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("find agents: %w", err)
+	}
+
+	results := make([]BatchItemResult, len(ids))
+	pointIDs := make([]*qdrant.PointId, 0, len(existing))
+	for i, id := range ids {
+		results[i] = BatchItemResult{ID: id}
+		point, exists := existing[id]
+		if !exists {
+			results[i].Err = ErrNotFound
+			continue
+		}
+		pointIDs = append(pointIDs, point.Id)
+		// Guard against duplicate IDs in the request.
+		delete(existing, id)
+	}
+
+	if len(pointIDs) == 0 {
+		return results, nil
+	}
+
+	_, err = s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qdrant.NewPointsSelectorIDs(pointIDs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete points: %w", err)
+	}
+
+	return results, nil
+}
+
+// findPointsByAgentIDs returns existing points keyed by agent ID.
+func (s *QdrantStore) findPointsByAgentIDs(ctx context.Context, ids []string) (map[string]*qdrant.RetrievedPoint, error) {
+	points, err := s.scrollAll(ctx, &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatchKeywords("id", ids...),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*qdrant.RetrievedPoint, len(points))
+	for _, point := range points {
+		byID[point.Payload["id"].GetStringValue()] = point
+	}
+	return byID, nil
+}
+
 // SearchAgents finds agents by vector similarity with optional filtering.
+// The profile, skills and tags vectors are queried in one batch and each
+// agent keeps its best score.
 func (s *QdrantStore) SearchAgents(ctx context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error) {
 	qdrantFilter := buildFilter(filter)
 
-	resp, err := s.client.Query(ctx, &qdrant.QueryPoints{
+	names := []string{vectorProfile, vectorSkills, vectorTags}
+	queries := make([]*qdrant.QueryPoints, len(names))
+	for i, name := range names {
+		queries[i] = &qdrant.QueryPoints{
+			CollectionName: s.collectionName,
+			Query:          qdrant.NewQueryDense(query),
+			Using:          qdrant.PtrOf(name),
+			Limit:          qdrant.PtrOf(uint64(limit)),
+			Filter:         qdrantFilter,
+			WithPayload:    qdrant.NewWithPayload(true),
+			// Only the skill vectors are read, to find the matched skill.
+			WithVectors: qdrant.NewWithVectorsInclude(vectorSkills),
+		}
+	}
+
+	resp, err := s.client.QueryBatch(ctx, &qdrant.QueryBatchPoints{
 		CollectionName: s.collectionName,
-		Query:          qdrant.NewQueryDense(query),
-		Limit:          qdrant.PtrOf(uint64(limit)),
-		Filter:         qdrantFilter,
-		WithPayload:    qdrant.NewWithPayload(true),
-		WithVectors:    qdrant.NewWithVectors(true),
+		QueryPoints:    queries,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 
-	agents := make([]ScoredAgent, 0, len(resp))
-	for _, point := range resp {
-		id := point.Payload["id"].GetStringValue()
-		agent, err := payloadToAgent(id, point.Payload)
-		if err != nil {
-			return nil, fmt.Errorf("parse payload for %s: %w", id, err)
-		}
+	byID := make(map[string]*ScoredAgent)
+	for _, batch := range resp {
+		for _, point := range batch.GetResult() {
+			id := point.Payload["id"].GetStringValue()
+			if existing, ok := byID[id]; ok {
+				existing.Score = max(existing.Score, point.Score)
+				continue
+			}
+
+			agent, err := payloadToAgent(id, point.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("parse payload for %s: %w", id, err)
+			}
+			applyVectors(agent, point.Vectors, point.Payload)
 
-		if point.Vectors != nil {
-			if vec := point.Vectors.GetVector(); vec != nil {
-				if dense := vec.GetDense(); dense != nil {
-					agent.Embedding = dense.GetData()
-				}
+			byID[id] = &ScoredAgent{
+				Agent:        agent,
+				Score:        point.Score,
+				MatchedSkill: bestSkill(query, agent.SkillEmbeddings),
 			}
 		}
+	}
 
-		agents = append(agents, ScoredAgent{
-			Agent: agent,
-			Score: point.Score,
-		})
+	agents := make([]ScoredAgent, 0, len(byID))
+	for _, scored := range byID {
+		agents = append(agents, *scored)
+	}
+	sortByScore(agents)
+
+	if limit > 0 && len(agents) > limit {
+		agents = agents[:limit]
 	}
 
 	return &SearchResult{Agents: agents}, nil
 }
 
+// agentToVectors converts an agent's embeddings to Qdrant named vectors.
+func agentToVectors(agent *RegisteredAgent) *qdrant.Vectors {
+	vectors := make(map[string]*qdrant.Vector, 3)
+	if len(agent.Embedding) > 0 {
+		vectors[vectorProfile] = qdrant.NewVectorDense(agent.Embedding)
+	}
+
+	skills := make([][]float32, 0, len(agent.SkillEmbeddings))
+	for _, skill := range agent.SkillEmbeddings {
+		skills = append(skills, skill.Vector)
+	}
+	if len(skills) > 0 {
+		vectors[vectorSkills] = qdrant.NewVectorMulti(skills)
+	}
+
+	if len(agent.TagsEmbedding) > 0 {
+		vectors[vectorTags] = qdrant.NewVectorDense(agent.TagsEmbedding)
+	}
+
+	return qdrant.NewVectorsMap(vectors)
+}
+
+// applyVectors copies named vectors from a Qdrant point into agent.
+func applyVectors(agent *RegisteredAgent, vectors *qdrant.VectorsOutput, payload map[string]*qdrant.Value) {
+	named := vectors.GetVectors().GetVectors()
+	if named == nil {
+		return
+	}
+
+	if profile := named[vectorProfile]; profile != nil {
+		agent.Embedding = profile.GetDense().GetData()
+	}
+	if tags := named[vectorTags]; tags != nil {
+		agent.TagsEmbedding = tags.GetDense().GetData()
+	}
+
+	skills := named[vectorSkills].GetMultiDense().GetVectors()
+	skillIDs := payload["skill_vector_ids"].GetListValue().GetValues()
+	for i, vec := range skills {
+		if i >= len(skillIDs) {
+			break
+		}
+		agent.SkillEmbeddings = append(agent.SkillEmbeddings, SkillEmbedding{
+			SkillID: skillIDs[i].GetStringValue(),
+			Vector:  vec.GetData(),
+		})
+	}
+}
+
+// bestSkill returns the ID of the skill whose vector is closest to query.
+func bestSkill(query []float32, skills []SkillEmbedding) string {
+	var best string
+	var bestScore float32
+	for _, skill := range skills {
+		score := cosineSimilarity(query, skill.Vector)
+		if best == "" || score > bestScore {
+			best = skill.SkillID
+			bestScore = score
+		}
+	}
+	return best
+}
+
 // agentToPayload converts a RegisteredAgent to Qdrant payload.
 func agentToPayload(agent *RegisteredAgent) (map[string]*qdrant.Value, error) {
 	cardJSON, err := json.Marshal(agent.Card)
@@ -495,15 +990,25 @@ func agentToPayload(agent *RegisteredAgent) (map[string]*qdrant.Value, error) {
 		tags[i] = tag
 	}
 
+	skillVectorIDs := make([]any, len(agent.SkillEmbeddings))
+	for i, skill := range agent.SkillEmbeddings {
+		skillVectorIDs[i] = skill.SkillID
+	}
+
 	payload := map[string]any{
-		"id":               agent.ID,
-		"card":             string(cardJSON),
-		"card_name":        agent.Card.Name,
-		"card_description": agent.Card.Description,
-		"tags":             tags,
-		"skill_ids":        skillIDs,
-		"created_at":       agent.CreatedAt.Unix(),
-		"updated_at":       agent.UpdatedAt.Unix(),
+		"id":                agent.ID,
+		"card":              string(cardJSON),
+		"card_name":         agent.Card.Name,
+		"card_description":  agent.Card.Description,
+		"card_version":      agent.Card.Version,
+		"tags":              tags,
+		"skill_ids":         skillIDs,
+		"skill_vector_ids":  skillVectorIDs,
+		"embedding_version": agent.EmbeddingVersion,
+		"embedding_model":   agent.EmbeddingModel,
+		"has_vectors":       len(agent.Embedding) > 0,
+		"created_at":        agent.CreatedAt.Unix(),
+		"updated_at":        agent.UpdatedAt.Unix(),
 	}
 
 	return qdrant.NewValueMap(payload), nil
@@ -531,11 +1036,14 @@ func payloadToAgent(id string, payload map[string]*qdrant.Value) (*RegisteredAge
 	updatedAt := time.Unix(payload["updated_at"].GetIntegerValue(), 0)
 
 	return &RegisteredAgent{
-		ID:        id,
-		Card:      card,
-		Tags:      tags,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		ID:               id,
+		Card:             card,
+		Tags:             tags,
+		EmbeddingVersion: payload["embedding_version"].GetStringValue(),
+		EmbeddingModel:   payload["embedding_model"].GetStringValue(),
+		Embedded:         payload["has_vectors"].GetBoolValue(),
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
 	}, nil
 }
 
@@ -583,9 +1091,85 @@ func buildFilter(filter AgentFilter) *qdrant.Filter {
 		})
 	}
 
+	if filter.Expr != nil {
+		conditions = append(conditions, compileExpr(filter.Expr))
+	}
+
 	if len(conditions) == 0 {
 		return nil
 	}
 
 	return &qdrant.Filter{Must: conditions}
 }
+
+// payloadFields maps filter fields to Qdrant payload keys.
+var payloadFields = map[FilterField]string{
+	FieldID:          "id",
+	FieldName:        "card_name",
+	FieldDescription: "card_description",
+	FieldVersion:     "card_version",
+	FieldTags:        "tags",
+	FieldSkills:      "skill_ids",
+	FieldCreatedAt:   "created_at",
+	FieldUpdatedAt:   "updated_at",
+}
+
+// compileExpr converts a FilterExpr to a Qdrant condition.
+func compileExpr(expr *FilterExpr) *qdrant.Condition {
+	switch {
+	case len(expr.And) > 0:
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{Must: compileExprs(expr.And)})
+	case len(expr.Or) > 0:
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: compileExprs(expr.Or)})
+	case expr.Not != nil:
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{
+			MustNot: []*qdrant.Condition{compileExpr(expr.Not)},
+		})
+	default:
+		return compileCond(expr.Cond)
+	}
+}
+
+func compileExprs(exprs []*FilterExpr) []*qdrant.Condition {
+	conditions := make([]*qdrant.Condition, len(exprs))
+	for i, sub := range exprs {
+		conditions[i] = compileExpr(sub)
+	}
+	return conditions
+}
+
+// compileCond converts a single FilterCond to a Qdrant condition.
+func compileCond(cond *FilterCond) *qdrant.Condition {
+	field := payloadFields[cond.Field]
+
+	switch cond.Op {
+	case OpAll:
+		must := make([]*qdrant.Condition, len(cond.Values))
+		for i, v := range cond.Values {
+			must[i] = qdrant.NewMatchKeyword(field, v)
+		}
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{Must: must})
+	case OpContains:
+		should := make([]*qdrant.Condition, len(cond.Values))
+		for i, v := range cond.Values {
+			should[i] = qdrant.NewMatchText(field, v)
+		}
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: should})
+	case OpGt, OpGte, OpLt, OpLte:
+		bound := qdrant.PtrOf(float64(cond.Time.Unix()))
+		r := &qdrant.Range{}
+		switch cond.Op {
+		case OpGt:
+			r.Gt = bound
+		case OpGte:
+			r.Gte = bound
+		case OpLt:
+			r.Lt = bound
+		case OpLte:
+			r.Lte = bound
+		}
+		return qdrant.NewRange(field, r)
+	default:
+		return qdrant.NewMatchKeywords(field, cond.Values...)
+	}
+}
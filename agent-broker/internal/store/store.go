@@ -11,6 +11,10 @@ var ErrNotFound = errors.New("agent not found")
 // ErrAlreadyExists is returned when creating a duplicate agent.
 var ErrAlreadyExists = errors.New("agent already exists")
 
+// ErrDimensionMismatch is returned when an existing collection stores
+// vectors of another dimension than the configured embedder produces.
+var ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
 // Store defines the interface for agent storage operations.
 type Store interface {
 	// Ping checks if the storage backend is reachable.
@@ -24,11 +28,18 @@ type Store interface {
 	// ListAgents returns agents matching the filter criteria.
 	ListAgents(ctx context.Context, filter AgentFilter) (*AgentListResult, error)
 	// SearchAgents finds agents by vector similarity with optional filtering.
+	// Agents are scored by their best-matching named vector.
 	SearchAgents(ctx context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error)
 	// UpdateAgent updates an existing agent. Returns ErrNotFound if not exists.
 	UpdateAgent(ctx context.Context, agent *RegisteredAgent) error
 	// DeleteAgent removes an agent. Returns ErrNotFound if not exists.
 	DeleteAgent(ctx context.Context, id string) error
+	// UpsertAgents creates or replaces agents in a single operation.
+	// Existing agents keep their CreatedAt. Results are in input order.
+	UpsertAgents(ctx context.Context, agents []*RegisteredAgent) ([]BatchItemResult, error)
+	// DeleteAgents removes agents in a single operation. Missing IDs are
+	// reported per item with ErrNotFound. Results are in input order.
+	DeleteAgents(ctx context.Context, ids []string) ([]BatchItemResult, error)
 }
 
 // HealthChecker provides health check capability for storage backends.
@@ -48,6 +59,9 @@ type AgentFilter struct {
 	Skills []string
 	// Query is a text search in name/description.
 	Query string
+	// Expr is an optional filter expression, combined with the other
+	// criteria using AND.
+	Expr *FilterExpr
 }
 
 // AgentListResult contains the list result with pagination info.
@@ -62,6 +76,22 @@ type AgentListResult struct {
 type SearchResult struct {
 	// Agents is the list of matching agents with scores.
 	Agents []ScoredAgent
+	// Lexical is true when agents were ranked by keyword overlap instead
+	// of vector similarity.
+	Lexical bool
+	// Reranked is true when agents are ordered by a second-stage reranker
+	// and carry a RerankScore.
+	Reranked bool
+}
+
+// BatchItemResult is the outcome of a single item in a batch write.
+type BatchItemResult struct {
+	// ID is the agent ID the result refers to.
+	ID string
+	// Created is true when an upsert inserted a new agent.
+	Created bool
+	// Err is the per-item error, if any.
+	Err error
 }
 
 // ScoredAgent is an agent with its similarity score.
@@ -70,4 +100,11 @@ type ScoredAgent struct {
 	Agent *RegisteredAgent
 	// Score is the similarity score (0-1, higher is more similar).
 	Score float32
+	// MatchedSkill is the ID of the best-matching skill, if the agent
+	// has skill vectors.
+	MatchedSkill string
+	// RerankScore is the second-stage reranker score (0-1, higher is more
+	// relevant), set when the result is reranked. Score keeps the
+	// first-stage similarity.
+	RerankScore float32
 }
@@ -14,10 +14,35 @@ type RegisteredAgent struct {
 	Card a2a.AgentCard
 	// Tags are classification tags for filtering.
 	Tags []string
-	// Embedding is the vector representation for semantic search.
+	// Embedding is the card-level profile vector for semantic search.
 	Embedding []float32
+	// SkillEmbeddings holds one vector per skill, including its examples.
+	SkillEmbeddings []SkillEmbedding
+	// TagsEmbedding is the optional vector of the agent's tags.
+	TagsEmbedding []float32
+	// EmbeddingVersion identifies the embedding text template the vectors
+	// were built from. Empty means the vectors predate versioning.
+	EmbeddingVersion string
+	// EmbeddingModel is the ID of the model that produced the vectors.
+	EmbeddingModel string
+	// Embedded is true when the store holds vectors for the agent, also
+	// when it returned the agent without loading them.
+	Embedded bool
 	// CreatedAt is when the agent was registered.
 	CreatedAt time.Time
 	// UpdatedAt is when the agent was last updated.
 	UpdatedAt time.Time
 }
+
+// HasVectors reports whether the agent has stored vectors, loaded or not.
+func (a *RegisteredAgent) HasVectors() bool {
+	return a.Embedded || len(a.Embedding) > 0
+}
+
+// SkillEmbedding is the vector for a single agent skill.
+type SkillEmbedding struct {
+	// SkillID is the A2A skill ID.
+	SkillID string
+	// Vector is the embedding of the skill text.
+	Vector []float32
+}
@@ -0,0 +1,214 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchOptions configures the BatchingEmbedder.
+type BatchOptions struct {
+	// MaxBatchSize is the maximum number of texts sent in one request.
+	MaxBatchSize int
+	// Window is how long the first caller waits for others to join.
+	Window time.Duration
+}
+
+// DefaultBatchOptions returns sensible defaults.
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{
+		MaxBatchSize: 32,
+		Window:       5 * time.Millisecond,
+	}
+}
+
+// BatchOption is a functional option for BatchingEmbedder.
+type BatchOption func(*BatchOptions)
+
+// WithMaxBatchSize sets the maximum number of texts per request.
+func WithMaxBatchSize(n int) BatchOption {
+	return func(o *BatchOptions) {
+		o.MaxBatchSize = n
+	}
+}
+
+// WithBatchWindow sets how long to gather concurrent calls.
+func WithBatchWindow(d time.Duration) BatchOption {
+	return func(o *BatchOptions) {
+		o.Window = d
+	}
+}
+
+// BatchingEmbedder is an Embedder decorator that gathers concurrent calls
+// for a short window, or until the batch is full, and sends them to the
+// underlying embedder as a single request. Calls are only batched with
+// others of the same embedding mode.
+type BatchingEmbedder struct {
+	// next is the wrapped embedder.
+	next Embedder
+	// opts holds batching configuration.
+	opts BatchOptions
+
+	// mu protects pending.
+	mu sync.Mutex
+	// pending holds the batch currently accepting callers, per mode.
+	pending map[Mode]*pendingBatch
+}
+
+// pendingBatch is a set of callers that will be embedded together.
+type pendingBatch struct {
+	// mode is the embedding mode shared by all waiters.
+	mode Mode
+	// waiters are the callers in arrival order.
+	waiters []*batchWaiter
+	// size is the total number of texts across waiters.
+	size int
+	// timer flushes the batch when the window elapses.
+	timer *time.Timer
+}
+
+// batchWaiter is a single caller waiting on a batch.
+type batchWaiter struct {
+	// ctx is the caller's context.
+	ctx context.Context
+	// texts are the caller's inputs.
+	texts []string
+	// done is closed when vectors or err is set.
+	done chan struct{}
+	// vectors are the caller's embeddings, in input order.
+	vectors [][]float32
+	// err is the batch error, if any.
+	err error
+}
+
+// NewBatchingEmbedder wraps next with request micro-batching.
+func NewBatchingEmbedder(next Embedder, opts ...BatchOption) *BatchingEmbedder {
+	options := DefaultBatchOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &BatchingEmbedder{
+		next:    next,
+		opts:    options,
+		pending: make(map[Mode]*pendingBatch),
+	}
+}
+
+// Dimensions returns the embedding vector dimension.
+func (b *BatchingEmbedder) Dimensions() int {
+	return b.next.Dimensions()
+}
+
+// Model returns the wrapped embedder's model name, if known.
+func (b *BatchingEmbedder) Model() string {
+	if m, ok := b.next.(ModelReporter); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+// Embed queues texts into the pending batch and waits for its result.
+// Calls that alone fill a batch are sent directly.
+func (b *BatchingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+	if b.opts.Window <= 0 || len(texts) >= b.opts.MaxBatchSize {
+		return b.next.Embed(ctx, texts)
+	}
+
+	w := &batchWaiter{ctx: ctx, texts: texts, done: make(chan struct{})}
+	mode := ModeFromContext(ctx)
+
+	b.mu.Lock()
+	if batch := b.pending[mode]; batch != nil && batch.size+len(texts) > b.opts.MaxBatchSize {
+		go b.flush(b.detachLocked(mode))
+	}
+	batch := b.pending[mode]
+	if batch == nil {
+		batch = &pendingBatch{mode: mode}
+		batch.timer = time.AfterFunc(b.opts.Window, func() {
+			b.mu.Lock()
+			if b.pending[mode] != batch {
+				b.mu.Unlock()
+				return
+			}
+			delete(b.pending, mode)
+			b.mu.Unlock()
+			b.flush(batch)
+		})
+		b.pending[mode] = batch
+	}
+	batch.waiters = append(batch.waiters, w)
+	batch.size += len(texts)
+	if batch.size >= b.opts.MaxBatchSize {
+		go b.flush(b.detachLocked(mode))
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return w.vectors, w.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detachLocked removes the pending batch for mode so that it can be
+// flushed. The caller must hold mu.
+func (b *BatchingEmbedder) detachLocked(mode Mode) *pendingBatch {
+	batch := b.pending[mode]
+	delete(b.pending, mode)
+	batch.timer.Stop()
+	return batch
+}
+
+// flush embeds the texts of every caller that is still waiting and hands
+// each its slice of the result. The request is cancelled only when every
+// caller has given up.
+func (b *BatchingEmbedder) flush(batch *pendingBatch) {
+	var live []*batchWaiter
+	var texts []string
+	for _, w := range batch.waiters {
+		if w.ctx.Err() != nil {
+			continue
+		}
+		live = append(live, w)
+		texts = append(texts, w.texts...)
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ContextWithMode(context.WithoutCancel(live[0].ctx), batch.mode))
+	defer cancel()
+	var remaining atomic.Int32
+	remaining.Store(int32(len(live)))
+	for _, w := range live {
+		stop := context.AfterFunc(w.ctx, func() {
+			if remaining.Add(-1) == 0 {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	vectors, err := b.next.Embed(ctx, texts)
+	if err == nil && len(vectors) != len(texts) {
+		err = fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+
+	offset := 0
+	for _, w := range live {
+		if err != nil {
+			w.err = err
+		} else {
+			w.vectors = vectors[offset : offset+len(w.texts) : offset+len(w.texts)]
+		}
+		offset += len(w.texts)
+		close(w.done)
+	}
+}
@@ -0,0 +1,124 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newLengthServer returns a server that embeds each input as its length
+// and counts requests.
+func newLengthServer(t *testing.T, requests *atomic.Int64, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		resp := embeddingResponse{Data: make([]embeddingData, len(req.Input))}
+		for i, text := range req.Input {
+			resp.Data[i] = embeddingData{Embedding: []float32{float32(len(text))}, Index: i}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBatchingEmbedder_Embed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concurrent calls share one request", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int64
+		server := newLengthServer(t, &requests, 0)
+		batcher := NewBatchingEmbedder(NewClient(server.URL, 1),
+			WithBatchWindow(50*time.Millisecond),
+		)
+
+		texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+		var wg sync.WaitGroup
+		for _, text := range texts {
+			wg.Go(func() {
+				vectors, err := batcher.Embed(context.Background(), []string{text})
+				if err != nil {
+					t.Errorf("Embed(%q) error = %v", text, err)
+					return
+				}
+				if len(vectors) != 1 || vectors[0][0] != float32(len(text)) {
+					t.Errorf("Embed(%q) = %v, want [[%d]]", text, vectors, len(text))
+				}
+			})
+		}
+		wg.Wait()
+
+		if got := requests.Load(); got != 1 {
+			t.Errorf("server got %d requests, want 1", got)
+		}
+	})
+
+	t.Run("full batch is sent without waiting for the window", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int64
+		server := newLengthServer(t, &requests, 0)
+		batcher := NewBatchingEmbedder(NewClient(server.URL, 1),
+			WithBatchWindow(time.Hour),
+			WithMaxBatchSize(2),
+		)
+
+		var wg sync.WaitGroup
+		for _, text := range []string{"a", "bb"} {
+			wg.Go(func() {
+				if _, err := batcher.Embed(context.Background(), []string{text}); err != nil {
+					t.Errorf("Embed() error = %v", err)
+				}
+			})
+		}
+		wg.Wait()
+
+		if got := requests.Load(); got != 1 {
+			t.Errorf("server got %d requests, want 1", got)
+		}
+	})
+
+	t.Run("cancelled caller returns early and others still succeed", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int64
+		server := newLengthServer(t, &requests, 100*time.Millisecond)
+		batcher := NewBatchingEmbedder(NewClient(server.URL, 1),
+			WithBatchWindow(10*time.Millisecond),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			_, err := batcher.Embed(ctx, []string{"impatient"})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Embed() error = %v, want DeadlineExceeded", err)
+			}
+		})
+		wg.Go(func() {
+			vectors, err := batcher.Embed(context.Background(), []string{"patient"})
+			if err != nil {
+				t.Errorf("Embed() error = %v", err)
+				return
+			}
+			if vectors[0][0] != float32(len("patient")) {
+				t.Errorf("Embed() = %v, want [[7]]", vectors)
+			}
+		})
+		wg.Wait()
+	})
+}
@@ -0,0 +1,183 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState string
+
+// Circuit breaker states.
+const (
+	// CircuitClosed passes calls through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects calls with ErrCircuitOpen.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe call through.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// BreakerOptions configures the CircuitBreaker.
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive provider failures
+	// that opens the circuit.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before a probe.
+	Cooldown time.Duration
+}
+
+// DefaultBreakerOptions returns sensible defaults.
+func DefaultBreakerOptions() BreakerOptions {
+	return BreakerOptions{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// BreakerOption is a functional option for CircuitBreaker.
+type BreakerOption func(*BreakerOptions)
+
+// WithFailureThreshold sets the consecutive failures that open the circuit.
+func WithFailureThreshold(n int) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.FailureThreshold = n
+	}
+}
+
+// WithCooldown sets how long the circuit stays open.
+func WithCooldown(d time.Duration) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.Cooldown = d
+	}
+}
+
+// CircuitBreaker is an Embedder decorator that fails fast with
+// ErrCircuitOpen after repeated provider failures. Only transport errors
+// and 429/5xx responses count as failures; client errors and caller
+// cancellation do not.
+type CircuitBreaker struct {
+	// next is the wrapped embedder.
+	next Embedder
+	// opts holds breaker configuration.
+	opts BreakerOptions
+
+	// mu protects the fields below.
+	mu sync.Mutex
+	// state is the current circuit state.
+	state CircuitState
+	// failures counts consecutive provider failures.
+	failures int
+	// openedAt is when the circuit last opened.
+	openedAt time.Time
+	// probing is true while a half-open probe is in flight.
+	probing bool
+}
+
+// NewCircuitBreaker wraps next with a circuit breaker.
+func NewCircuitBreaker(next Embedder, opts ...BreakerOption) *CircuitBreaker {
+	options := DefaultBreakerOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &CircuitBreaker{
+		next:  next,
+		opts:  options,
+		state: CircuitClosed,
+	}
+}
+
+// Dimensions returns the embedding vector dimension.
+func (b *CircuitBreaker) Dimensions() int {
+	return b.next.Dimensions()
+}
+
+// Model returns the wrapped embedder's model name, if known.
+func (b *CircuitBreaker) Model() string {
+	if m, ok := b.next.(ModelReporter); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+// State returns the current circuit state.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Now().Sub(b.openedAt) >= b.opts.Cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Embed calls the wrapped embedder unless the circuit is open.
+func (b *CircuitBreaker) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	probe, err := b.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := b.next.Embed(ctx, texts)
+	b.record(probe, err)
+	return vectors, err
+}
+
+// acquire checks whether a call may proceed, moving an expired open
+// circuit to half-open and admitting a single probe. It reports whether
+// the admitted call is that probe.
+func (b *CircuitBreaker) acquire() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Now().Sub(b.openedAt) < b.opts.Cooldown {
+			return false, ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true, nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return false, ErrCircuitOpen
+		}
+		b.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record updates the circuit with the outcome of a call. Only the probe
+// decides a half-open circuit: a call admitted while the circuit was
+// closed that finishes after it opened is ignored.
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	} else if b.state != CircuitClosed {
+		return
+	}
+
+	if !isTemporary(err) {
+		// A success or a client error shows the provider is reachable. A
+		// cancelled probe proves nothing, so the next call probes again.
+		var apiErr *APIError
+		if err == nil || errors.As(err, &apiErr) {
+			b.state = CircuitClosed
+			b.failures = 0
+		}
+		return
+	}
+
+	b.failures++
+	if probe || b.failures >= b.opts.FailureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,402 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// CacheStats contains embedding cache counters.
+type CacheStats struct {
+	// MemoryHits is the number of texts served from the in-memory LRU.
+	MemoryHits uint64
+	// DiskHits is the number of texts served from the on-disk tier.
+	DiskHits uint64
+	// Misses is the number of texts sent to the underlying embedder.
+	Misses uint64
+	// Coalesced is the number of texts that waited on an identical
+	// in-flight request instead of being embedded again.
+	Coalesced uint64
+}
+
+// HitRate returns the fraction of texts not sent to the underlying embedder.
+func (s CacheStats) HitRate() float64 {
+	total := s.MemoryHits + s.DiskHits + s.Misses + s.Coalesced
+	if total == 0 {
+		return 0
+	}
+	return float64(total-s.Misses) / float64(total)
+}
+
+// CacheStatsReporter is implemented by embedders that expose cache counters.
+type CacheStatsReporter interface {
+	// CacheStats returns a snapshot of the cache counters.
+	CacheStats() CacheStats
+}
+
+// CacheOptions configures the CachedEmbedder.
+type CacheOptions struct {
+	// MaxEntries bounds the in-memory LRU.
+	MaxEntries int
+	// Dir enables the on-disk tier when non-empty.
+	Dir string
+	// Model is the model name used in cache keys. When empty, it is taken
+	// from the wrapped embedder if it implements ModelReporter.
+	Model string
+	// Prefixes are the instruction templates applied below the cache, used
+	// in cache keys. When zero, they are taken from the wrapped embedder if
+	// it implements PrefixReporter.
+	Prefixes Prefixes
+}
+
+// DefaultCacheOptions returns sensible defaults.
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		MaxEntries: 10000,
+	}
+}
+
+// CacheOption is a functional option for CachedEmbedder.
+type CacheOption func(*CacheOptions)
+
+// WithMaxEntries sets the in-memory LRU capacity.
+func WithMaxEntries(n int) CacheOption {
+	return func(o *CacheOptions) {
+		o.MaxEntries = n
+	}
+}
+
+// WithCacheDir enables the on-disk tier rooted at dir.
+func WithCacheDir(dir string) CacheOption {
+	return func(o *CacheOptions) {
+		o.Dir = dir
+	}
+}
+
+// WithCacheModel sets the model name used in cache keys.
+func WithCacheModel(model string) CacheOption {
+	return func(o *CacheOptions) {
+		o.Model = model
+	}
+}
+
+// WithCachePrefixes sets the instruction templates used in cache keys.
+func WithCachePrefixes(prefixes Prefixes) CacheOption {
+	return func(o *CacheOptions) {
+		o.Prefixes = prefixes
+	}
+}
+
+// CachedEmbedder is an Embedder decorator that caches vectors by model,
+// instruction prefix, dimension and normalized text. Concurrent requests for the same text
+// share a single call to the underlying embedder.
+type CachedEmbedder struct {
+	// next is the wrapped embedder.
+	next Embedder
+	// opts holds cache configuration.
+	opts CacheOptions
+
+	// mu protects lru, entries and inflight.
+	mu sync.Mutex
+	// lru orders entries from most to least recently used.
+	lru *list.List
+	// entries maps cache keys to lru elements.
+	entries map[string]*list.Element
+	// inflight maps cache keys to pending embedder calls.
+	inflight map[string]*embedCall
+
+	// memoryHits counts in-memory hits.
+	memoryHits atomic.Uint64
+	// diskHits counts on-disk hits.
+	diskHits atomic.Uint64
+	// misses counts texts sent to the underlying embedder.
+	misses atomic.Uint64
+	// coalesced counts texts that joined an in-flight call.
+	coalesced atomic.Uint64
+}
+
+// lruEntry is a cached vector.
+type lruEntry struct {
+	// key is the cache key.
+	key string
+	// vector is the cached embedding.
+	vector []float32
+}
+
+// embedCall is a pending embedding shared by concurrent requests.
+type embedCall struct {
+	// done is closed when vector or err is set.
+	done chan struct{}
+	// vector is the resulting embedding.
+	vector []float32
+	// err is the embedding error, if any.
+	err error
+}
+
+// NewCachedEmbedder wraps next with an embedding cache.
+func NewCachedEmbedder(next Embedder, opts ...CacheOption) *CachedEmbedder {
+	options := DefaultCacheOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Model == "" {
+		if m, ok := next.(ModelReporter); ok {
+			options.Model = m.Model()
+		}
+	}
+	if p, ok := next.(PrefixReporter); ok && options.Prefixes.IsZero() {
+		options.Prefixes = p.Prefixes()
+	}
+
+	return &CachedEmbedder{
+		next:     next,
+		opts:     options,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*embedCall),
+	}
+}
+
+// Dimensions returns the embedding vector dimension.
+func (c *CachedEmbedder) Dimensions() int {
+	return c.next.Dimensions()
+}
+
+// Model returns the model name used in cache keys.
+func (c *CachedEmbedder) Model() string {
+	return c.opts.Model
+}
+
+// Prefixes returns the instruction templates used in cache keys.
+func (c *CachedEmbedder) Prefixes() Prefixes {
+	return c.opts.Prefixes
+}
+
+// CacheStats returns a snapshot of the cache counters.
+func (c *CachedEmbedder) CacheStats() CacheStats {
+	return CacheStats{
+		MemoryHits: c.memoryHits.Load(),
+		DiskHits:   c.diskHits.Load(),
+		Misses:     c.misses.Load(),
+		Coalesced:  c.coalesced.Load(),
+	}
+}
+
+// Embed returns cached embeddings where available and embeds the rest in a
+// single call to the underlying embedder.
+func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	results := make([][]float32, len(texts))
+	waits := make(map[int]*embedCall)
+	owned := make(map[string]*embedCall)
+	var ownedKeys, ownedTexts []string
+
+	mode := ModeFromContext(ctx)
+	c.mu.Lock()
+	for i, text := range texts {
+		key := c.key(mode, text)
+		if el, ok := c.entries[key]; ok {
+			c.lru.MoveToFront(el)
+			results[i] = slices.Clone(el.Value.(*lruEntry).vector)
+			c.memoryHits.Add(1)
+			continue
+		}
+		if call, ok := c.inflight[key]; ok {
+			if _, mine := owned[key]; !mine {
+				c.coalesced.Add(1)
+			}
+			waits[i] = call
+			continue
+		}
+		call := &embedCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		owned[key] = call
+		waits[i] = call
+		ownedKeys = append(ownedKeys, key)
+		ownedTexts = append(ownedTexts, text)
+	}
+	c.mu.Unlock()
+
+	// Resolve our own calls before waiting on anyone else's so that two
+	// requests sharing texts cannot block each other.
+	if len(ownedKeys) > 0 {
+		c.resolve(ctx, ownedKeys, ownedTexts, owned)
+	}
+
+	for i, call := range waits {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		results[i] = slices.Clone(call.vector)
+	}
+
+	return results, nil
+}
+
+// resolve fills the owned calls from disk or the underlying embedder and
+// publishes the results.
+func (c *CachedEmbedder) resolve(ctx context.Context, keys, texts []string, calls map[string]*embedCall) {
+	var missKeys, missTexts []string
+	vectors := make(map[string][]float32, len(keys))
+	for i, key := range keys {
+		if v, ok := c.readDisk(key); ok {
+			vectors[key] = v
+			c.diskHits.Add(1)
+			continue
+		}
+		missKeys = append(missKeys, key)
+		missTexts = append(missTexts, texts[i])
+	}
+
+	var err error
+	if len(missTexts) > 0 {
+		c.misses.Add(uint64(len(missTexts)))
+		var embeddings [][]float32
+		embeddings, err = c.next.Embed(ctx, missTexts)
+		if err == nil && len(embeddings) != len(missTexts) {
+			err = fmt.Errorf("embedder returned %d vectors for %d texts", len(embeddings), len(missTexts))
+		}
+		if err == nil {
+			for i, key := range missKeys {
+				vectors[key] = embeddings[i]
+				c.writeDisk(key, embeddings[i])
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		call := calls[key]
+		delete(c.inflight, key)
+		if v, ok := vectors[key]; ok {
+			call.vector = v
+			c.addLocked(key, v)
+		} else {
+			call.err = err
+		}
+		close(call.done)
+	}
+}
+
+// addLocked inserts a vector into the LRU, evicting the oldest entry when
+// full. The caller must hold mu.
+func (c *CachedEmbedder) addLocked(key string, vector []float32) {
+	if c.opts.MaxEntries <= 0 {
+		return
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEntry).vector = vector
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&lruEntry{key: key, vector: vector})
+	for c.lru.Len() > c.opts.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// key returns the cache key for text under the configured model, the
+// mode's instruction prefix, dimension and embedding mode.
+func (c *CachedEmbedder) key(mode Mode, text string) string {
+	h := sha256.New()
+	h.Write([]byte(c.opts.Model))
+	h.Write([]byte{0})
+	// Keys without a prefix stay as they were before prefixes existed, so
+	// the disk tier keeps its entries.
+	if prefix := c.opts.Prefixes.template(mode); prefix != "" {
+		h.Write([]byte(prefix))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(strconv.Itoa(c.next.Dimensions())))
+	h.Write([]byte{0})
+	h.Write([]byte(mode))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeText(text)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeText trims and collapses whitespace so that formatting
+// differences do not cause cache misses.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// diskPath returns the on-disk location of a cache key.
+func (c *CachedEmbedder) diskPath(key string) string {
+	return filepath.Join(c.opts.Dir, key[:2], key)
+}
+
+// readDisk loads a vector from the on-disk tier. Unreadable or malformed
+// files are treated as misses.
+func (c *CachedEmbedder) readDisk(key string) ([]float32, bool) {
+	if c.opts.Dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.diskPath(key))
+	if err != nil || len(data) == 0 || len(data)%4 != 0 {
+		return nil, false
+	}
+	if dim := c.next.Dimensions(); dim > 0 && len(data) != dim*4 {
+		return nil, false
+	}
+	v := make([]float32, len(data)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return v, true
+}
+
+// writeDisk stores a vector in the on-disk tier. Write failures are
+// ignored; the entry is simply re-embedded on the next miss.
+func (c *CachedEmbedder) writeDisk(key string, vector []float32) {
+	if c.opts.Dir == "" {
+		return
+	}
+	path := c.diskPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	data := make([]byte, len(vector)*4)
+	for i, f := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(f))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".tmp*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
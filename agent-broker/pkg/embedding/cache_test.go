@@ -0,0 +1,176 @@
+package embedding
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingEmbedder returns the text length as a one-dimensional vector
+// and records how many texts it embedded.
+type countingEmbedder struct {
+	// texts is the number of texts embedded.
+	texts atomic.Int64
+	// delay slows each call to make request overlap deterministic.
+	delay time.Duration
+}
+
+func (e *countingEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	time.Sleep(e.delay)
+	e.texts.Add(int64(len(texts)))
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func (e *countingEmbedder) Dimensions() int { return 1 }
+
+func (e *countingEmbedder) Model() string { return "counting" }
+
+func TestCachedEmbedder_Embed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("repeated and normalized texts hit memory", func(t *testing.T) {
+		t.Parallel()
+		inner := &countingEmbedder{}
+		cache := NewCachedEmbedder(inner)
+
+		first, err := cache.Embed(ctx, []string{"who owns billing?", "hello"})
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		second, _ := cache.Embed(ctx, []string{"  who owns\tbilling? ", "hello", "hello"})
+
+		if got := inner.texts.Load(); got != 2 {
+			t.Errorf("inner embedded %d texts, want 2", got)
+		}
+		if !slices.Equal(first[0], second[0]) || !slices.Equal(first[1], second[2]) {
+			t.Errorf("cached vectors differ: %v vs %v", first, second)
+		}
+		stats := cache.CacheStats()
+		if stats.MemoryHits != 3 || stats.Misses != 2 {
+			t.Errorf("CacheStats() = %+v, want 3 memory hits and 2 misses", stats)
+		}
+		if rate := stats.HitRate(); rate != 0.6 {
+			t.Errorf("HitRate() = %v, want 0.6", rate)
+		}
+	})
+
+	t.Run("duplicates within a batch are embedded once", func(t *testing.T) {
+		t.Parallel()
+		inner := &countingEmbedder{}
+		cache := NewCachedEmbedder(inner)
+
+		vectors, err := cache.Embed(ctx, []string{"a", "a", "bb"})
+
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		if got := inner.texts.Load(); got != 2 {
+			t.Errorf("inner embedded %d texts, want 2", got)
+		}
+		if vectors[1][0] != 1 || vectors[2][0] != 2 {
+			t.Errorf("Embed() = %v, want [[1] [1] [2]]", vectors)
+		}
+	})
+
+	t.Run("concurrent requests are coalesced", func(t *testing.T) {
+		t.Parallel()
+		inner := &countingEmbedder{delay: 50 * time.Millisecond}
+		cache := NewCachedEmbedder(inner)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				if _, err := cache.Embed(ctx, []string{"same query"}); err != nil {
+					t.Errorf("Embed() error = %v", err)
+				}
+			})
+		}
+		wg.Wait()
+
+		if got := inner.texts.Load(); got != 1 {
+			t.Errorf("inner embedded %d texts, want 1", got)
+		}
+		if stats := cache.CacheStats(); stats.Coalesced+stats.MemoryHits != 9 {
+			t.Errorf("CacheStats() = %+v, want 9 coalesced or memory hits", stats)
+		}
+	})
+
+	t.Run("LRU evicts oldest entries", func(t *testing.T) {
+		t.Parallel()
+		inner := &countingEmbedder{}
+		cache := NewCachedEmbedder(inner, WithMaxEntries(2))
+
+		_, _ = cache.Embed(ctx, []string{"a", "b", "c"})
+		_, _ = cache.Embed(ctx, []string{"a"})
+
+		if got := inner.texts.Load(); got != 4 {
+			t.Errorf("inner embedded %d texts, want 4", got)
+		}
+	})
+
+	t.Run("keys include the instruction prefixes", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		inner := &countingEmbedder{}
+		plain := NewCachedEmbedder(inner, WithCacheDir(dir))
+		prefixed := NewCachedEmbedder(NewPrefixEmbedder(inner, Prefixes{Document: "passage: "}), WithCacheDir(dir))
+
+		_, _ = plain.Embed(ctx, []string{"billing"})
+		vectors, _ := prefixed.Embed(ctx, []string{"billing"})
+
+		if got := inner.texts.Load(); got != 2 {
+			t.Errorf("inner embedded %d texts, want 2", got)
+		}
+		if vectors[0][0] != float32(len("passage: billing")) {
+			t.Errorf("Embed() = %v, want the prefixed text's vector", vectors)
+		}
+		if prefixed.Prefixes().Document != "passage: " {
+			t.Errorf("Prefixes() = %+v, want the wrapped embedder's prefixes", prefixed.Prefixes())
+		}
+	})
+
+	t.Run("disk tier survives restarts", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		inner := &countingEmbedder{}
+
+		_, _ = NewCachedEmbedder(inner, WithCacheDir(dir)).Embed(ctx, []string{"persisted"})
+		restarted := NewCachedEmbedder(inner, WithCacheDir(dir))
+		vectors, err := restarted.Embed(ctx, []string{"persisted"})
+
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		if got := inner.texts.Load(); got != 1 {
+			t.Errorf("inner embedded %d texts, want 1", got)
+		}
+		if vectors[0][0] != float32(len("persisted")) {
+			t.Errorf("Embed() = %v, want [[9]]", vectors)
+		}
+		if stats := restarted.CacheStats(); stats.DiskHits != 1 {
+			t.Errorf("CacheStats() = %+v, want 1 disk hit", stats)
+		}
+	})
+
+	t.Run("model is part of the key", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		inner := &countingEmbedder{}
+
+		_, _ = NewCachedEmbedder(inner, WithCacheDir(dir)).Embed(ctx, []string{"text"})
+		other := NewCachedEmbedder(inner, WithCacheDir(dir), WithCacheModel("other"))
+		_, _ = other.Embed(ctx, []string{"text"})
+
+		if got := inner.texts.Load(); got != 2 {
+			t.Errorf("inner embedded %d texts, want 2", got)
+		}
+	})
+}
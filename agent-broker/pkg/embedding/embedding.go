@@ -2,10 +2,47 @@ package embedding
 
 import "context"
 
-// Embedder generates vector embeddings from text.
+// Embedder generates vector embeddings from text. Callers mark query
+// embeddings with ContextWithMode; implementations that care read the mode
+// with ModeFromContext.
 type Embedder interface {
 	// Embed generates embeddings for the given texts.
 	Embed(ctx context.Context, texts []string) ([][]float32, error)
 	// Dimensions returns the embedding vector dimension.
 	Dimensions() int
 }
+
+// ModelReporter is implemented by embedders that know their model name.
+type ModelReporter interface {
+	// Model returns the embedding model name.
+	Model() string
+}
+
+// Mode tells an embedder whether texts are stored documents or search
+// queries. Asymmetric models such as e5 and bge embed the two differently.
+type Mode string
+
+// Embedding modes.
+const (
+	// ModeDocument embeds texts that are stored and searched over.
+	ModeDocument Mode = "document"
+	// ModeQuery embeds search queries.
+	ModeQuery Mode = "query"
+)
+
+// modeKey is the context key for the embedding mode.
+type modeKey struct{}
+
+// ContextWithMode returns a context that asks embedders to embed in mode.
+func ContextWithMode(ctx context.Context, mode Mode) context.Context {
+	return context.WithValue(ctx, modeKey{}, mode)
+}
+
+// ModeFromContext returns the embedding mode carried by ctx. Texts are
+// treated as documents unless ctx says otherwise.
+func ModeFromContext(ctx context.Context) Mode {
+	if mode, ok := ctx.Value(modeKey{}).(Mode); ok && mode != "" {
+		return mode
+	}
+	return ModeDocument
+}
@@ -0,0 +1,62 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/internal/httperr"
+)
+
+// ErrCircuitOpen is returned without calling the provider while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("embedding circuit open")
+
+// APIError is a non-success response from an embedding provider.
+type APIError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Message is the provider's error message, or the raw body.
+	Message string
+	// RetryAfter is the delay requested by the provider, if any.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("embedding API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("embedding API error: status %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether the request may succeed if retried.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newAPIError builds an APIError from a non-success response.
+func newAPIError(resp *http.Response) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    httperr.ReadMessage(resp),
+		RetryAfter: httperr.RetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// isTemporary reports whether err is a provider failure worth retrying:
+// a 429/5xx response or a transport error other than caller cancellation.
+func isTemporary(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,148 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genai"
+)
+
+// DefaultGeminiModel is the embedding model used when none is configured.
+const DefaultGeminiModel = "gemini-embedding-001"
+
+// maxGeminiBatch is the most texts the Gemini API embeds per request.
+const maxGeminiBatch = 100
+
+// GeminiEmbedder generates embeddings with the Gemini API. It accepts the
+// same Options as Client; Path is ignored.
+type GeminiEmbedder struct {
+	// client is the GenAI client.
+	client *genai.Client
+	// model is the model name to use for embeddings.
+	model string
+	// dim is the embedding vector dimension, requested as the output
+	// dimensionality.
+	dim int
+	// retry controls retries of failed requests.
+	retry RetryPolicy
+}
+
+// NewGeminiEmbedder creates a Gemini embedder. An empty url uses the
+// public Gemini API endpoint.
+func NewGeminiEmbedder(ctx context.Context, url string, dim int, opts ...Option) (*GeminiEmbedder, error) {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	model := options.Model
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+
+	httpOptions := genai.HTTPOptions{BaseURL: url}
+	if len(options.Headers) > 0 {
+		httpOptions.Headers = http.Header{}
+		for k, v := range options.Headers {
+			httpOptions.Headers.Set(k, v)
+		}
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:      options.APIKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  options.HTTPClient,
+		HTTPOptions: httpOptions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create genai client: %w", err)
+	}
+
+	return &GeminiEmbedder{
+		client: client,
+		model:  model,
+		dim:    dim,
+		retry:  options.Retry,
+	}, nil
+}
+
+// Model returns the configured model name.
+func (e *GeminiEmbedder) Model() string {
+	return e.model
+}
+
+// Embed generates embeddings for the given texts.
+func (e *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxGeminiBatch {
+		end := min(start+maxGeminiBatch, len(texts))
+		batch, err := e.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// embedBatch embeds up to maxGeminiBatch texts in one request.
+func (e *GeminiEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+	config := &genai.EmbedContentConfig{TaskType: geminiTaskType(ModeFromContext(ctx))}
+	if e.dim > 0 {
+		dim := int32(e.dim)
+		config.OutputDimensionality = &dim
+	}
+
+	var resp *genai.EmbedContentResponse
+	err := retry(ctx, e.retry, func() error {
+		var err error
+		resp, err = e.client.Models.EmbedContent(ctx, e.model, contents, config)
+		return geminiError(err)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("embed content: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embed content: got %d embeddings for %d texts", len(resp.Embeddings), len(texts))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for i, emb := range resp.Embeddings {
+		if emb != nil {
+			embeddings[i] = emb.Values
+		}
+	}
+	return embeddings, nil
+}
+
+// geminiTaskType maps an embedding mode to the Gemini retrieval task type.
+func geminiTaskType(mode Mode) string {
+	if mode == ModeQuery {
+		return "RETRIEVAL_QUERY"
+	}
+	return "RETRIEVAL_DOCUMENT"
+}
+
+// geminiError converts a GenAI API error to *APIError so retries and the
+// circuit breaker classify it like other providers.
+func geminiError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return &APIError{
+			StatusCode: apiErr.Code,
+			Message:    apiErr.Message,
+		}
+	}
+	return err
+}
+
+// Dimensions returns the embedding vector dimension.
+func (e *GeminiEmbedder) Dimensions() int {
+	return e.dim
+}
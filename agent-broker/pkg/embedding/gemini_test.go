@@ -0,0 +1,84 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeminiEmbedder_Embed(t *testing.T) {
+	t.Parallel()
+	var gotPath, gotKey string
+	var gotReq struct {
+		Requests []struct {
+			Model                string `json:"model"`
+			OutputDimensionality int    `json:"outputDimensionality"`
+		} `json:"requests"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("X-Goog-Api-Key")
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		embeddings := make([]map[string]any, len(gotReq.Requests))
+		for i := range embeddings {
+			embeddings[i] = map[string]any{"values": []float32{float32(i), 1, 0}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	t.Cleanup(server.Close)
+
+	embedder, err := NewGeminiEmbedder(context.Background(), server.URL, 3,
+		WithAPIKey("gemini-key"),
+	)
+	if err != nil {
+		t.Fatalf("NewGeminiEmbedder() error = %v", err)
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if !strings.HasSuffix(gotPath, "/models/"+DefaultGeminiModel+":batchEmbedContents") {
+		t.Errorf("path = %q, want batchEmbedContents for %s", gotPath, DefaultGeminiModel)
+	}
+	if gotKey != "gemini-key" {
+		t.Errorf("api key header = %q, want gemini-key", gotKey)
+	}
+	if len(gotReq.Requests) != 2 || gotReq.Requests[0].OutputDimensionality != 3 {
+		t.Errorf("requests = %+v, want 2 requests with outputDimensionality 3", gotReq.Requests)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 1 {
+		t.Errorf("Embed() = %v, want vectors in input order", vectors)
+	}
+}
+
+func TestGeminiEmbedder_Embed_Error(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "API key not valid", "status": "INVALID_ARGUMENT"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	embedder, err := NewGeminiEmbedder(context.Background(), server.URL, 3,
+		WithAPIKey("bad-key"),
+	)
+	if err != nil {
+		t.Fatalf("NewGeminiEmbedder() error = %v", err)
+	}
+	_, err = embedder.Embed(context.Background(), []string{"hello"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Embed() error = %v, want 400 *APIError", err)
+	}
+	if apiErr.Message != "API key not valid" {
+		t.Errorf("Message = %q, want %q", apiErr.Message, "API key not valid")
+	}
+}
@@ -0,0 +1,181 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// LocalModel is the model name reported by LocalEmbedder.
+const LocalModel = "local-hashed-ngrams-v1"
+
+// LocalOptions configures the LocalEmbedder.
+type LocalOptions struct {
+	// MinNGram is the shortest character n-gram extracted from each word.
+	MinNGram int
+	// MaxNGram is the longest character n-gram extracted from each word.
+	MaxNGram int
+	// WordWeight is the weight of whole-word features relative to n-grams.
+	WordWeight float64
+}
+
+// DefaultLocalOptions returns sensible defaults.
+func DefaultLocalOptions() LocalOptions {
+	return LocalOptions{
+		MinNGram:   3,
+		MaxNGram:   4,
+		WordWeight: 2,
+	}
+}
+
+// LocalOption is a functional option for LocalEmbedder.
+type LocalOption func(*LocalOptions)
+
+// WithNGramRange sets the character n-gram lengths.
+func WithNGramRange(minN, maxN int) LocalOption {
+	return func(o *LocalOptions) {
+		o.MinNGram = minN
+		o.MaxNGram = maxN
+	}
+}
+
+// WithWordWeight sets the weight of whole-word features.
+func WithWordWeight(w float64) LocalOption {
+	return func(o *LocalOptions) {
+		o.WordWeight = w
+	}
+}
+
+// LocalEmbedder is a pure-Go Embedder that needs no model server. It hashes
+// words and their character n-grams into a fixed number of signed buckets
+// and L2-normalizes the result, so texts sharing words or word stems
+// ("translate", "translation") get similar vectors. Output is
+// deterministic for a given dimension and options.
+type LocalEmbedder struct {
+	// dim is the embedding vector dimension.
+	dim int
+	// opts holds feature extraction configuration.
+	opts LocalOptions
+}
+
+// NewLocalEmbedder creates a local embedder producing dim-dimensional
+// vectors. dim must be positive.
+func NewLocalEmbedder(dim int, opts ...LocalOption) (*LocalEmbedder, error) {
+	if dim <= 0 {
+		return nil, fmt.Errorf("local embedder dimension must be positive, got %d", dim)
+	}
+
+	options := DefaultLocalOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &LocalEmbedder{
+		dim:  dim,
+		opts: options,
+	}, nil
+}
+
+// Embed generates embeddings for the given texts.
+func (e *LocalEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embeddings[i] = e.embed(text)
+	}
+	return embeddings, nil
+}
+
+// Dimensions returns the embedding vector dimension.
+func (e *LocalEmbedder) Dimensions() int {
+	return e.dim
+}
+
+// Model returns the local model name.
+func (e *LocalEmbedder) Model() string {
+	return LocalModel
+}
+
+// embed builds the hashed feature vector for a single text.
+func (e *LocalEmbedder) embed(text string) []float32 {
+	acc := make([]float64, e.dim)
+	words := localWords(text)
+	if len(words) == 0 {
+		// Keep the vector non-zero so cosine similarity stays defined.
+		words = []string{"\x00empty"}
+	}
+
+	for _, word := range words {
+		e.add(acc, "w:"+word, e.opts.WordWeight)
+
+		padded := []rune("<" + word + ">")
+		var grams []string
+		for n := e.opts.MinNGram; n <= e.opts.MaxNGram; n++ {
+			for start := 0; start+n <= len(padded); start++ {
+				grams = append(grams, string(padded[start:start+n]))
+			}
+		}
+		// Split one unit of weight across the word's n-grams so long words
+		// do not dominate short ones.
+		for _, g := range grams {
+			e.add(acc, "g:"+g, 1/float64(len(grams)))
+		}
+	}
+
+	var norm float64
+	for _, v := range acc {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	vec := make([]float32, e.dim)
+	for i, v := range acc {
+		if norm > 0 {
+			vec[i] = float32(v / norm)
+		}
+	}
+	return vec
+}
+
+// add hashes a feature into a signed bucket.
+func (e *LocalEmbedder) add(acc []float64, feature string, weight float64) {
+	if e.dim <= 0 {
+		return
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+	bucket := sum % uint64(e.dim)
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	acc[bucket] += weight
+}
+
+// localStopWords are dropped before hashing.
+var localStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "do": true, "for": true, "from": true,
+	"how": true, "i": true, "in": true, "is": true, "it": true, "me": true,
+	"my": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "what": true, "who": true, "with": true,
+}
+
+// localWords lowercases text and splits it into words, dropping stop words.
+func localWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if !localStopWords[f] {
+			words = append(words, f)
+		}
+	}
+	return words
+}
@@ -0,0 +1,91 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func TestLocalEmbedder_Embed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("vectors are deterministic and normalized", func(t *testing.T) {
+		t.Parallel()
+		e, _ := NewLocalEmbedder(256)
+		other, _ := NewLocalEmbedder(256)
+
+		first, err := e.Embed(ctx, []string{"Translate documents", ""})
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		second, _ := other.Embed(ctx, []string{"Translate documents"})
+
+		if len(first[0]) != 256 {
+			t.Errorf("Embed() dimension = %d, want 256", len(first[0]))
+		}
+		if !slices.Equal(first[0], second[0]) {
+			t.Error("Embed() is not deterministic")
+		}
+		for i, v := range first {
+			var norm float64
+			for _, f := range v {
+				norm += float64(f) * float64(f)
+			}
+			if math.Abs(norm-1) > 1e-5 {
+				t.Errorf("Embed()[%d] squared norm = %v, want 1", i, norm)
+			}
+		}
+	})
+
+	t.Run("related texts score higher than unrelated ones", func(t *testing.T) {
+		t.Parallel()
+		e, _ := NewLocalEmbedder(384)
+
+		tests := []struct {
+			query     string
+			related   string
+			unrelated string
+		}{
+			{
+				query:     "who can translate this letter?",
+				related:   "Translation agent: translates documents between languages",
+				unrelated: "Billing assistant: answers invoice and refund questions",
+			},
+			{
+				query:     "refund my invoice",
+				related:   "Billing assistant: answers invoice and refund questions",
+				unrelated: "Weather agent: forecasts and current conditions",
+			},
+		}
+		for _, tt := range tests {
+			vectors, _ := e.Embed(ctx, []string{tt.query, tt.related, tt.unrelated})
+			related := cosine(vectors[0], vectors[1])
+			unrelated := cosine(vectors[0], vectors[2])
+			if related <= unrelated {
+				t.Errorf("%q: related score %.3f <= unrelated score %.3f", tt.query, related, unrelated)
+			}
+		}
+	})
+}
+
+func TestNewLocalEmbedder_InvalidDimension(t *testing.T) {
+	t.Parallel()
+
+	for _, dim := range []int{0, -1} {
+		if _, err := NewLocalEmbedder(dim); err == nil {
+			t.Errorf("NewLocalEmbedder(%d) error = nil, want error", dim)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// defaultOllamaPath is the native Ollama embeddings endpoint.
+const defaultOllamaPath = "/api/embed"
+
+// OllamaClient is an embeddings client for the native Ollama /api/embed
+// endpoint. It accepts the same Options as Client.
+type OllamaClient struct {
+	// url is the base URL of the Ollama server.
+	url string
+	// model is the model name to use for embeddings.
+	model string
+	// dim is the embedding vector dimension.
+	dim int
+	// httpClient is the HTTP client for making requests.
+	httpClient *http.Client
+	// retry controls retries of failed requests.
+	retry RetryPolicy
+	// path is the embeddings endpoint path appended to url.
+	path string
+	// apiKey is sent as a bearer token when set.
+	apiKey string
+	// headers are added to every request.
+	headers map[string]string
+}
+
+// ollamaEmbedRequest is the request body for POST /api/embed.
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse is the response from POST /api/embed.
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// NewOllamaClient creates a new native Ollama embeddings client.
+func NewOllamaClient(url string, dim int, opts ...Option) *OllamaClient {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	path := options.Path
+	if path == "" {
+		path = defaultOllamaPath
+	}
+
+	return &OllamaClient{
+		url:        url,
+		model:      options.Model,
+		dim:        dim,
+		httpClient: options.HTTPClient,
+		retry:      options.Retry,
+		path:       path,
+		apiKey:     options.APIKey,
+		headers:    options.Headers,
+	}
+}
+
+// Model returns the configured model name.
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
+// Embed generates embeddings for the given texts.
+func (c *OllamaClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	body, err := json.Marshal(ollamaEmbedRequest{
+		Model: c.model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := doWithRetry(ctx, c.httpClient, c.retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+c.path, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		setHeaders(req, c.apiKey, c.headers)
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var embResp ollamaEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(embResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("decode response: got %d embeddings for %d texts", len(embResp.Embeddings), len(texts))
+	}
+
+	return embResp.Embeddings, nil
+}
+
+// Dimensions returns the embedding vector dimension.
+func (c *OllamaClient) Dimensions() int {
+	return c.dim
+}
@@ -0,0 +1,62 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaClient_Embed(t *testing.T) {
+	t.Parallel()
+	var gotPath string
+	var gotReq struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		embeddings := make([][]float32, len(gotReq.Input))
+		for i, text := range gotReq.Input {
+			embeddings[i] = []float32{float32(len(text)), 1}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"model": gotReq.Model, "embeddings": embeddings})
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewOllamaClient(server.URL, 2, WithModel("nomic-embed-text"))
+	vectors, err := client.Embed(context.Background(), []string{"a", "abc"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if gotPath != "/api/embed" {
+		t.Errorf("path = %q, want /api/embed", gotPath)
+	}
+	if gotReq.Model != "nomic-embed-text" {
+		t.Errorf("model = %q, want nomic-embed-text", gotReq.Model)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][0] != 3 {
+		t.Errorf("Embed() = %v, want vectors in input order", vectors)
+	}
+}
+
+func TestOllamaClient_Embed_Error(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "model \"missing\" not found, try pulling it first"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewOllamaClient(server.URL, 2, WithModel("missing"))
+	_, err := client.Embed(context.Background(), []string{"hello"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Embed() error = %v, want 404 *APIError", err)
+	}
+}
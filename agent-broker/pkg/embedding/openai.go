@@ -9,8 +9,12 @@ import (
 	"time"
 )
 
+// defaultOpenAIPath is the embeddings endpoint of OpenAI-compatible APIs.
+const defaultOpenAIPath = "/v1/embeddings"
+
 // Client is an OpenAI-compatible embeddings client.
-// Works with OpenAI, TEI, Ollama, vLLM, and other compatible providers.
+// Works with OpenAI, Azure OpenAI, TEI, Ollama, vLLM, and other compatible
+// providers.
 type Client struct {
 	// url is the base URL of the embeddings API.
 	url string
@@ -20,6 +24,14 @@ type Client struct {
 	dim int
 	// httpClient is the HTTP client for making requests.
 	httpClient *http.Client
+	// retry controls retries of failed requests.
+	retry RetryPolicy
+	// path is the embeddings endpoint path appended to url.
+	path string
+	// apiKey is sent as a bearer token when set.
+	apiKey string
+	// headers are added to every request.
+	headers map[string]string
 }
 
 // Options configures the Client.
@@ -28,6 +40,17 @@ type Options struct {
 	Model string
 	// HTTPClient is the HTTP client to use.
 	HTTPClient *http.Client
+	// Retry controls retries of failed requests.
+	Retry RetryPolicy
+	// APIKey is the provider API key. Empty sends no credentials.
+	APIKey string
+	// Headers are extra HTTP headers added to every request, e.g. an Azure
+	// "api-key" header or an organization ID.
+	Headers map[string]string
+	// Path overrides the endpoint path appended to the base URL, e.g. an
+	// Azure deployment path with its api-version query. Empty uses the
+	// provider's default.
+	Path string
 }
 
 // DefaultOptions returns sensible defaults.
@@ -37,6 +60,7 @@ func DefaultOptions() Options {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		Retry: DefaultRetryPolicy(),
 	}
 }
 
@@ -57,6 +81,34 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithRetryPolicy sets the retry policy for failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *Options) {
+		o.Retry = policy
+	}
+}
+
+// WithAPIKey sets the provider API key.
+func WithAPIKey(key string) Option {
+	return func(o *Options) {
+		o.APIKey = key
+	}
+}
+
+// WithHeaders sets extra HTTP headers added to every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *Options) {
+		o.Headers = headers
+	}
+}
+
+// WithPath overrides the endpoint path appended to the base URL.
+func WithPath(path string) Option {
+	return func(o *Options) {
+		o.Path = path
+	}
+}
+
 // embeddingRequest is the request body for POST /v1/embeddings.
 type embeddingRequest struct {
 	Input []string `json:"input"`
@@ -81,14 +133,28 @@ func NewClient(url string, dim int, opts ...Option) *Client {
 		opt(&options)
 	}
 
+	path := options.Path
+	if path == "" {
+		path = defaultOpenAIPath
+	}
+
 	return &Client{
 		url:        url,
 		model:      options.Model,
 		dim:        dim,
 		httpClient: options.HTTPClient,
+		retry:      options.Retry,
+		path:       path,
+		apiKey:     options.APIKey,
+		headers:    options.Headers,
 	}
 }
 
+// Model returns the configured model name.
+func (c *Client) Model() string {
+	return c.model
+}
+
 // Embed generates embeddings for the given texts.
 func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
@@ -105,22 +171,19 @@ func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error)
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/v1/embeddings", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+c.path, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		setHeaders(req, c.apiKey, c.headers)
+		return req, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
-	}
-
 	var embResp embeddingResponse
 	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
@@ -141,3 +204,15 @@ func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error)
 func (c *Client) Dimensions() int {
 	return c.dim
 }
+
+// setHeaders sets the JSON content type, bearer auth and extra headers.
+// Extra headers are applied last so they can replace the defaults.
+func setHeaders(req *http.Request, apiKey string, headers map[string]string) {
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+}
@@ -0,0 +1,78 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Embed_AuthAndHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantPath   string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "no credentials",
+			wantPath:   "/v1/embeddings",
+			wantHeader: map[string]string{"Authorization": ""},
+		},
+		{
+			name:       "bearer api key",
+			opts:       []Option{WithAPIKey("sk-test")},
+			wantPath:   "/v1/embeddings",
+			wantHeader: map[string]string{"Authorization": "Bearer sk-test"},
+		},
+		{
+			name: "azure deployment path and api-key header",
+			opts: []Option{
+				WithHeaders(map[string]string{"api-key": "azure-key"}),
+				WithPath("/openai/deployments/embed/embeddings?api-version=2024-02-01"),
+			},
+			wantPath:   "/openai/deployments/embed/embeddings",
+			wantHeader: map[string]string{"Api-Key": "azure-key", "Authorization": ""},
+		},
+		{
+			name: "custom header overrides auth",
+			opts: []Option{
+				WithAPIKey("sk-test"),
+				WithHeaders(map[string]string{"Authorization": "Token other"}),
+			},
+			wantPath:   "/v1/embeddings",
+			wantHeader: map[string]string{"Authorization": "Token other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				_ = json.NewEncoder(w).Encode(embeddingResponse{
+					Data: []embeddingData{{Embedding: []float32{1}, Index: 0}},
+				})
+			}))
+			t.Cleanup(server.Close)
+
+			client := NewClient(server.URL, 1, tt.opts...)
+			if _, err := client.Embed(context.Background(), []string{"hello"}); err != nil {
+				t.Fatalf("Embed() error = %v", err)
+			}
+
+			if got.URL.Path != tt.wantPath {
+				t.Errorf("path = %q, want %q", got.URL.Path, tt.wantPath)
+			}
+			for k, want := range tt.wantHeader {
+				if v := got.Header.Get(k); v != want {
+					t.Errorf("header %s = %q, want %q", k, v, want)
+				}
+			}
+		})
+	}
+}
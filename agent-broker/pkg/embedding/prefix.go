@@ -0,0 +1,137 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// textPlaceholder marks where the text goes in a prefix template.
+const textPlaceholder = "{text}"
+
+// Prefixes holds the instruction templates applied per embedding mode. A
+// template containing "{text}" has it replaced by the text; any other
+// template is prepended. An empty template leaves texts unchanged.
+type Prefixes struct {
+	// Query is the template for ModeQuery texts.
+	Query string
+	// Document is the template for ModeDocument texts.
+	Document string
+}
+
+// IsZero reports whether no template is set.
+func (p Prefixes) IsZero() bool {
+	return p.Query == "" && p.Document == ""
+}
+
+// Version returns a short hash identifying the templates, or "" when no
+// template is set. Vectors embedded with different prefixes differ.
+func (p Prefixes) Version() string {
+	if p.IsZero() {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(p.Query + "\x00" + p.Document))
+	return hex.EncodeToString(sum[:8])
+}
+
+// template returns the template for mode.
+func (p Prefixes) template(mode Mode) string {
+	if mode == ModeQuery {
+		return p.Query
+	}
+	return p.Document
+}
+
+// Apply renders text with the template for mode.
+func (p Prefixes) Apply(mode Mode, text string) string {
+	tmpl := p.template(mode)
+	if strings.Contains(tmpl, textPlaceholder) {
+		return strings.ReplaceAll(tmpl, textPlaceholder, text)
+	}
+	return tmpl + text
+}
+
+// PrefixReporter is implemented by embedders that apply instruction
+// prefixes, directly or below them.
+type PrefixReporter interface {
+	// Prefixes returns the per-mode templates.
+	Prefixes() Prefixes
+}
+
+// bgeQueryInstruction is the retrieval instruction of the bge and mxbai
+// English models.
+const bgeQueryInstruction = "Represent this sentence for searching relevant passages: "
+
+// modelPrefixes maps model name fragments to their recommended prefixes,
+// checked in order.
+var modelPrefixes = []struct {
+	fragment string
+	prefixes Prefixes
+}{
+	{fragment: "e5", prefixes: Prefixes{Query: "query: ", Document: "passage: "}},
+	{fragment: "nomic-embed", prefixes: Prefixes{Query: "search_query: ", Document: "search_document: "}},
+	{fragment: "bge-m3", prefixes: Prefixes{}},
+	{fragment: "bge", prefixes: Prefixes{Query: bgeQueryInstruction}},
+	{fragment: "mxbai-embed", prefixes: Prefixes{Query: bgeQueryInstruction}},
+}
+
+// DefaultPrefixes returns the recommended prefixes for a model, matched by
+// name fragment (e.g. "intfloat/multilingual-e5-large", "BAAI/bge-small-en-v1.5",
+// "nomic-embed-text"). Unknown models get no prefixes.
+func DefaultPrefixes(model string) Prefixes {
+	model = strings.ToLower(model)
+	for _, mp := range modelPrefixes {
+		if strings.Contains(model, mp.fragment) {
+			return mp.prefixes
+		}
+	}
+	return Prefixes{}
+}
+
+// PrefixEmbedder is an Embedder decorator that renders each text with the
+// instruction template for the context's embedding mode before embedding.
+type PrefixEmbedder struct {
+	// next is the wrapped embedder.
+	next Embedder
+	// prefixes holds the per-mode templates.
+	prefixes Prefixes
+}
+
+// NewPrefixEmbedder wraps next with per-mode instruction prefixes. Wrap it
+// in a CachedEmbedder, not the other way round, so cache keys include the
+// prefixes and the cache's stats stay reachable.
+func NewPrefixEmbedder(next Embedder, prefixes Prefixes) *PrefixEmbedder {
+	return &PrefixEmbedder{
+		next:     next,
+		prefixes: prefixes,
+	}
+}
+
+// Dimensions returns the embedding vector dimension.
+func (p *PrefixEmbedder) Dimensions() int {
+	return p.next.Dimensions()
+}
+
+// Model returns the wrapped embedder's model name, if known.
+func (p *PrefixEmbedder) Model() string {
+	if m, ok := p.next.(ModelReporter); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+// Prefixes returns the per-mode templates.
+func (p *PrefixEmbedder) Prefixes() Prefixes {
+	return p.prefixes
+}
+
+// Embed renders texts for the context's mode and embeds them.
+func (p *PrefixEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	mode := ModeFromContext(ctx)
+	rendered := make([]string, len(texts))
+	for i, text := range texts {
+		rendered[i] = p.prefixes.Apply(mode, text)
+	}
+	return p.next.Embed(ctx, rendered)
+}
@@ -0,0 +1,150 @@
+package embedding
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// modeRecorder records the texts and mode of every Embed call.
+type modeRecorder struct {
+	// mu protects calls.
+	mu sync.Mutex
+	// calls holds one entry per Embed call.
+	calls []modeCall
+}
+
+// modeCall is a single recorded Embed call.
+type modeCall struct {
+	// mode is the embedding mode from the context.
+	mode Mode
+	// texts are the embedded texts.
+	texts []string
+}
+
+func (r *modeRecorder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, modeCall{mode: ModeFromContext(ctx), texts: texts})
+	r.mu.Unlock()
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func (r *modeRecorder) Dimensions() int { return 1 }
+
+func TestPrefixes_Apply(t *testing.T) {
+	t.Parallel()
+	prefixes := Prefixes{Query: "query: ", Document: "<doc>{text}</doc>"}
+
+	tests := []struct {
+		name string
+		mode Mode
+		want string
+	}{
+		{name: "query prefix is prepended", mode: ModeQuery, want: "query: translate text"},
+		{name: "document template replaces placeholder", mode: ModeDocument, want: "<doc>translate text</doc>"},
+		{name: "unknown mode is a document", mode: "", want: "<doc>translate text</doc>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := prefixes.Apply(tt.mode, "translate text"); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPrefixes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		model string
+		want  Prefixes
+	}{
+		{model: "intfloat/multilingual-e5-large", want: Prefixes{Query: "query: ", Document: "passage: "}},
+		{model: "BAAI/bge-small-en-v1.5", want: Prefixes{Query: "Represent this sentence for searching relevant passages: "}},
+		{model: "BAAI/bge-m3", want: Prefixes{}},
+		{model: "nomic-embed-text", want: Prefixes{Query: "search_query: ", Document: "search_document: "}},
+		{model: "text-embedding-3-small", want: Prefixes{}},
+		{model: "", want: Prefixes{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			t.Parallel()
+			if got := DefaultPrefixes(tt.model); got != tt.want {
+				t.Errorf("DefaultPrefixes(%q) = %+v, want %+v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixEmbedder_Embed(t *testing.T) {
+	t.Parallel()
+	inner := &modeRecorder{}
+	embedder := NewPrefixEmbedder(inner, Prefixes{Query: "query: ", Document: "passage: "})
+	ctx := context.Background()
+
+	if _, err := embedder.Embed(ctx, []string{"billing agent"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if _, err := embedder.Embed(ContextWithMode(ctx, ModeQuery), []string{"who owns billing?"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	want := []string{"passage: billing agent", "query: who owns billing?"}
+	for i, call := range inner.calls {
+		if call.texts[0] != want[i] {
+			t.Errorf("call %d text = %q, want %q", i, call.texts[0], want[i])
+		}
+	}
+}
+
+func TestCachedEmbedder_Embed_SeparatesModes(t *testing.T) {
+	t.Parallel()
+	inner := &modeRecorder{}
+	cache := NewCachedEmbedder(inner)
+	ctx := context.Background()
+
+	for range 2 {
+		_, _ = cache.Embed(ctx, []string{"billing"})
+		_, _ = cache.Embed(ContextWithMode(ctx, ModeQuery), []string{"billing"})
+	}
+
+	if len(inner.calls) != 2 {
+		t.Fatalf("inner got %d calls, want 2 (one per mode)", len(inner.calls))
+	}
+	if inner.calls[0].mode != ModeDocument || inner.calls[1].mode != ModeQuery {
+		t.Errorf("inner modes = %s, %s, want document, query", inner.calls[0].mode, inner.calls[1].mode)
+	}
+}
+
+func TestBatchingEmbedder_Embed_SeparatesModes(t *testing.T) {
+	t.Parallel()
+	inner := &modeRecorder{}
+	batcher := NewBatchingEmbedder(inner, WithMaxBatchSize(8))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for _, mode := range []Mode{ModeDocument, ModeQuery, ModeDocument, ModeQuery} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = batcher.Embed(ContextWithMode(ctx, mode), []string{string(mode)})
+		}()
+	}
+	wg.Wait()
+
+	for _, call := range inner.calls {
+		for _, text := range call.texts {
+			if Mode(text) != call.mode {
+				t.Errorf("%s text sent in a %s batch", text, call.mode)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrProbeMismatch is returned when a probed provider does not match the
+// configured dimension or model.
+var ErrProbeMismatch = errors.New("embedding provider mismatch")
+
+// probeText is embedded to measure the provider's vector dimension.
+const probeText = "dimension probe"
+
+// ProviderInfo describes the model served by an embedding provider.
+type ProviderInfo struct {
+	// ModelID is the served model identifier.
+	ModelID string
+	// MaxInputLength is the model's input limit in tokens, if reported.
+	MaxInputLength int
+}
+
+// InfoReporter is implemented by embedders that can describe the model
+// their provider serves.
+type InfoReporter interface {
+	// Info returns the provider's model description, or nil when the
+	// provider does not offer one.
+	Info(ctx context.Context) (*ProviderInfo, error)
+}
+
+// ProbeResult is what a provider reported about itself.
+type ProbeResult struct {
+	// Dimensions is the length of a returned embedding.
+	Dimensions int
+	// Model is the served model ID, or the configured model name when the
+	// provider does not report one.
+	Model string
+	// MaxInputLength is the model's input limit in tokens, zero if unknown.
+	MaxInputLength int
+}
+
+// Probe embeds a test text to measure the real vector dimension and asks
+// the provider for its model ID where supported.
+func Probe(ctx context.Context, e Embedder) (*ProbeResult, error) {
+	vectors, err := e.Embed(ctx, []string{probeText})
+	if err != nil {
+		return nil, fmt.Errorf("probe embedding: %w", err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) == 0 {
+		return nil, fmt.Errorf("probe embedding: provider returned no vector")
+	}
+
+	result := &ProbeResult{Dimensions: len(vectors[0])}
+	if r, ok := e.(InfoReporter); ok {
+		// Model info is best effort; the dimension is what must be right.
+		if info, err := r.Info(ctx); err == nil && info != nil {
+			result.Model = info.ModelID
+			result.MaxInputLength = info.MaxInputLength
+		}
+	}
+	if result.Model == "" {
+		if m, ok := e.(ModelReporter); ok {
+			result.Model = m.Model()
+		}
+	}
+	return result, nil
+}
+
+// Validate checks the probe against a configured dimension and model. Zero
+// or empty values are not checked.
+func (r *ProbeResult) Validate(dim int, model string) error {
+	if dim > 0 && dim != r.Dimensions {
+		return fmt.Errorf("%w: configured dimension %d, provider returned %d", ErrProbeMismatch, dim, r.Dimensions)
+	}
+	if model != "" && r.Model != "" && model != r.Model {
+		return fmt.Errorf("%w: configured model %q, provider serves %q", ErrProbeMismatch, model, r.Model)
+	}
+	return nil
+}
+
+// teiInfo is the response from the TEI GET /info endpoint.
+type teiInfo struct {
+	ModelID        string `json:"model_id"`
+	MaxInputLength int    `json:"max_input_length"`
+}
+
+// Info returns the model served by a Text Embeddings Inference server from
+// its /info endpoint. Providers without the endpoint return nil.
+func (c *Client) Info(ctx context.Context) (*ProviderInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/info", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	setHeaders(req, c.apiKey, c.headers)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var info teiInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if info.ModelID == "" {
+		return nil, nil
+	}
+	return &ProviderInfo{
+		ModelID:        info.ModelID,
+		MaxInputLength: info.MaxInputLength,
+	}, nil
+}
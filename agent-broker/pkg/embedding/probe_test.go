@@ -0,0 +1,118 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTEIServer serves three-dimensional embeddings and, when modelID is
+// set, a TEI-style /info endpoint.
+func newTEIServer(t *testing.T, modelID string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/embeddings", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(embeddingResponse{
+			Data: []embeddingData{{Embedding: []float32{0.1, 0.2, 0.3}, Index: 0}},
+		})
+	})
+	if modelID != "" {
+		mux.HandleFunc("GET /info", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"model_id":         modelID,
+				"max_input_length": 512,
+			})
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		serverModel   string
+		clientModel   string
+		wantModel     string
+		wantMaxLength int
+	}{
+		{
+			name:          "TEI reports model and input limit",
+			serverModel:   "BAAI/bge-small-en-v1.5",
+			wantModel:     "BAAI/bge-small-en-v1.5",
+			wantMaxLength: 512,
+		},
+		{
+			name:        "no info endpoint falls back to configured model",
+			clientModel: "text-embedding-3-small",
+			wantModel:   "text-embedding-3-small",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := newTEIServer(t, tt.serverModel)
+			client := NewClient(server.URL, 0, WithModel(tt.clientModel))
+
+			got, err := Probe(context.Background(), client)
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+
+			if got.Dimensions != 3 {
+				t.Errorf("Dimensions = %d, want 3", got.Dimensions)
+			}
+			if got.Model != tt.wantModel {
+				t.Errorf("Model = %q, want %q", got.Model, tt.wantModel)
+			}
+			if got.MaxInputLength != tt.wantMaxLength {
+				t.Errorf("MaxInputLength = %d, want %d", got.MaxInputLength, tt.wantMaxLength)
+			}
+		})
+	}
+}
+
+func TestProbeResult_Validate(t *testing.T) {
+	t.Parallel()
+	probe := &ProbeResult{Dimensions: 384, Model: "BAAI/bge-small-en-v1.5"}
+
+	tests := []struct {
+		name     string
+		dim      int
+		model    string
+		mismatch bool
+	}{
+		{name: "auto-detect", dim: 0},
+		{name: "matching dimension and model", dim: 384, model: "BAAI/bge-small-en-v1.5"},
+		{name: "wrong dimension", dim: 768, mismatch: true},
+		{name: "wrong model", dim: 384, model: "intfloat/e5-small-v2", mismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := probe.Validate(tt.dim, tt.model)
+			if got := errors.Is(err, ErrProbeMismatch); got != tt.mismatch {
+				t.Errorf("Validate() error = %v, want mismatch %v", err, tt.mismatch)
+			}
+		})
+	}
+}
+
+func TestProbe_Unreachable(t *testing.T) {
+	t.Parallel()
+	server := newTEIServer(t, "")
+	server.Close()
+	client := NewClient(server.URL, 384, WithRetryPolicy(RetryPolicy{}))
+
+	if _, err := Probe(context.Background(), client); err == nil {
+		t.Error("Probe() error = nil, want connection error")
+	}
+}
@@ -0,0 +1,105 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls retries of failed provider requests.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// InitialBackoff is the base delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including a Retry-After
+	// requested by the provider.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns sensible defaults.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// backoff returns the jittered delay before retry attempt n (starting at 0):
+// half of the exponential delay plus a random share of the other half.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.InitialBackoff << n
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// doWithRetry sends the request built by newReq, retrying transport errors
+// and 429/5xx responses. A successful response is returned with its body
+// open; failures are returned as *APIError or the transport error.
+func doWithRetry(ctx context.Context, client *http.Client, policy RetryPolicy, newReq func() (*http.Request, error)) (*http.Response, error) {
+	var resp *http.Response
+	err := retry(ctx, policy, func() error {
+		req, err := newReq()
+		if err != nil {
+			return err
+		}
+
+		r, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		if r.StatusCode != http.StatusOK {
+			defer func() { _ = r.Body.Close() }()
+			return newAPIError(r)
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// retry calls fn until it succeeds, fails with a non-temporary error, the
+// policy's retries are exhausted, or ctx is done. It gives up without
+// waiting when ctx's deadline comes before the next attempt. The last
+// error is returned.
+func retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= policy.MaxRetries || !isTemporary(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			delay = min(apiErr.RetryAfter, policy.MaxBackoff)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
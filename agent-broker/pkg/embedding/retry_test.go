@@ -0,0 +1,251 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries keeps retry tests quick.
+var fastRetries = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+// newFlakyServer fails the first failures requests with status and body,
+// then returns a one-dimensional
+func newFlakyServer(t *testing.T, requests *atomic.Int64, failures int64, status int, header http.Header, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(embeddingResponse{
+			Data: []embeddingData{{Embedding: []float32{1}, Index: 0}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Embed_Retries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		failures     int64
+		status       int
+		body         string
+		wantRequests int64
+		wantErr      string
+	}{
+		{
+			name:         "retries 503 then succeeds",
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "retries 429 then succeeds",
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			wantRequests: 2,
+		},
+		{
+			name:         "gives up after max retries",
+			failures:     10,
+			status:       http.StatusBadGateway,
+			body:         `{"error": "model is loading"}`,
+			wantRequests: 4,
+			wantErr:      "embedding API error: status 502: model is loading",
+		},
+		{
+			name:         "does not retry client errors",
+			failures:     10,
+			status:       http.StatusBadRequest,
+			body:         `{"error": {"message": "input too long", "type": "invalid_request_error"}}`,
+			wantRequests: 1,
+			wantErr:      "embedding API error: status 400: input too long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var requests atomic.Int64
+			server := newFlakyServer(t, &requests, tt.failures, tt.status, nil, tt.body)
+			client := NewClient(server.URL, 1, WithRetryPolicy(fastRetries))
+
+			_, err := client.Embed(context.Background(), []string{"hello"})
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Embed() error = %v, want nil", err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Embed() error = %v, want *APIError", err)
+			}
+			if apiErr.Error() != tt.wantErr {
+				t.Errorf("Embed() error = %q, want %q", apiErr.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_Embed_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		retryAfter   string
+		maxBackoff   time.Duration
+		timeout      time.Duration
+		minElapsed   time.Duration
+		maxElapsed   time.Duration
+		wantRequests int64
+		wantErr      bool
+	}{
+		{
+			name:         "honors Retry-After within MaxBackoff",
+			retryAfter:   "1",
+			maxBackoff:   2 * time.Second,
+			minElapsed:   time.Second,
+			maxElapsed:   2 * time.Second,
+			wantRequests: 2,
+		},
+		{
+			name:         "caps Retry-After at MaxBackoff",
+			retryAfter:   "60",
+			maxBackoff:   5 * time.Millisecond,
+			maxElapsed:   time.Second,
+			wantRequests: 2,
+		},
+		{
+			name:         "gives up when the deadline is before the retry",
+			retryAfter:   "60",
+			maxBackoff:   time.Minute,
+			timeout:      time.Second,
+			maxElapsed:   500 * time.Millisecond,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var requests atomic.Int64
+			header := http.Header{"Retry-After": []string{tt.retryAfter}}
+			server := newFlakyServer(t, &requests, 1, http.StatusTooManyRequests, header, "slow down")
+			policy := fastRetries
+			policy.MaxBackoff = tt.maxBackoff
+			client := NewClient(server.URL, 1, WithRetryPolicy(policy))
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			_, err := client.Embed(ctx, []string{"hello"})
+			elapsed := time.Since(start)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Embed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+			if elapsed < tt.minElapsed || elapsed > tt.maxElapsed {
+				t.Errorf("Embed() took %v, want between %v and %v", elapsed, tt.minElapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_Embed(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int64
+	server := newFlakyServer(t, &requests, 2, http.StatusInternalServerError, nil, "down")
+	noRetries := RetryPolicy{}
+	breaker := NewCircuitBreaker(
+		NewClient(server.URL, 1, WithRetryPolicy(noRetries)),
+		WithFailureThreshold(2),
+		WithCooldown(50*time.Millisecond),
+	)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := breaker.Embed(ctx, []string{"hello"}); err == nil {
+			t.Fatal("Embed() error = nil, want server error")
+		}
+	}
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("State() = %s, want open", state)
+	}
+
+	_, err := breaker.Embed(ctx, []string{"hello"})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Embed() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server got %d requests while open, want 2", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := breaker.Embed(ctx, []string{"hello"}); err != nil {
+		t.Errorf("probe Embed() error = %v, want nil", err)
+	}
+	if state := breaker.State(); state != CircuitClosed {
+		t.Errorf("State() after probe = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreaker_StaleCall(t *testing.T) {
+	t.Parallel()
+	breaker := NewCircuitBreaker(&countingEmbedder{}, WithFailureThreshold(1), WithCooldown(time.Millisecond))
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	// A slow call is admitted while closed, then another call fails and
+	// opens the circuit.
+	slow, _ := breaker.acquire()
+	failing, _ := breaker.acquire()
+	breaker.record(failing, unavailable)
+	time.Sleep(5 * time.Millisecond)
+	probe, err := breaker.acquire()
+	if err != nil || !probe {
+		t.Fatalf("acquire() after cooldown = %v, %v, want the probe", probe, err)
+	}
+
+	breaker.record(slow, nil)
+	if state := breaker.State(); state != CircuitHalfOpen {
+		t.Errorf("State() after the slow call = %s, want half_open", state)
+	}
+	if _, err := breaker.acquire(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("acquire() during the probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	breaker.record(probe, nil)
+	if state := breaker.State(); state != CircuitClosed {
+		t.Errorf("State() after the probe = %s, want closed", state)
+	}
+}
@@ -0,0 +1,22 @@
+// Package genaitext extracts plain text from genai content.
+package genaitext
+
+import (
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Join joins the text parts of content with newlines.
+func Join(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
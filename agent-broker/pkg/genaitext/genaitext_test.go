@@ -0,0 +1,38 @@
+package genaitext
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content *genai.Content
+		want    string
+	}{
+		{name: "nil content", content: nil, want: ""},
+		{
+			name: "text parts are joined",
+			content: &genai.Content{Parts: []*genai.Part{
+				{Text: "first"},
+				nil,
+				{FunctionCall: &genai.FunctionCall{Name: "route"}},
+				{Text: "second"},
+			}},
+			want: "first\nsecond",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Join(tt.content); got != tt.want {
+				t.Errorf("Join() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package httperr reads the error responses of HTTP model providers.
+package httperr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBody bounds how much of an error response is read.
+const maxBody = 4096
+
+// ReadMessage reads the start of resp's body and returns the provider's
+// error message, or the trimmed body when it has no known shape.
+func ReadMessage(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	return Message(body)
+}
+
+// Message extracts the message from common provider error shapes:
+// {"error": "..."} (TEI, Ollama), {"error": {"message": "..."}} (OpenAI)
+// and {"message": "..."}. Other bodies are returned trimmed.
+func Message(body []byte) string {
+	var payload struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var flat string
+		switch {
+		case json.Unmarshal(payload.Error, &flat) == nil && flat != "":
+			return flat
+		case json.Unmarshal(payload.Error, &nested) == nil && nested.Message != "":
+			return nested.Message
+		case payload.Message != "":
+			return payload.Message
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// RetryAfter parses a Retry-After header in seconds or HTTP-date form. It
+// returns zero when the header is absent, invalid or in the past.
+func RetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
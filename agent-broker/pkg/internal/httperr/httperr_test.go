@@ -0,0 +1,58 @@
+package httperr
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "flat error", body: `{"error":"Input validation error","error_type":"Validation"}`, want: "Input validation error"},
+		{name: "nested error", body: `{"error":{"message":"model not found","type":"invalid_request_error"}}`, want: "model not found"},
+		{name: "top-level message", body: `{"message":"quota exceeded"}`, want: "quota exceeded"},
+		{name: "plain text", body: "  bad gateway\n", want: "bad gateway"},
+		{name: "unknown shape", body: `{"detail":"nope"}`, want: `{"detail":"nope"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Message([]byte(tt.body)); got != tt.want {
+				t.Errorf("Message() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "absent", value: ""},
+		{name: "seconds", value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{name: "http date", value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute},
+		{name: "past date", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+		{name: "invalid", value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := RetryAfter(tt.value); got < tt.min || got > tt.max {
+				t.Errorf("RetryAfter(%q) = %s, want between %s and %s", tt.value, got, tt.min, tt.max)
+			}
+		})
+	}
+}
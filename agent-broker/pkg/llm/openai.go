@@ -0,0 +1,515 @@
+// Package llm provides chat model providers for the broker agent.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/genaitext"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/internal/httperr"
+)
+
+// defaultOpenAIPath is the chat completions endpoint of OpenAI-compatible
+// APIs.
+const defaultOpenAIPath = "/v1/chat/completions"
+
+// OpenAIModel is a model.LLM backed by an OpenAI-compatible chat
+// completions API with tool calling. Works with OpenAI, vLLM, Ollama and
+// other compatible servers.
+type OpenAIModel struct {
+	// url is the base URL of the API.
+	url string
+	// model is the model name sent with each request.
+	model string
+	// httpClient is the HTTP client for making requests.
+	httpClient *http.Client
+	// path is the chat completions endpoint path appended to url.
+	path string
+	// apiKey is sent as a bearer token when set.
+	apiKey string
+	// headers are added to every request.
+	headers map[string]string
+}
+
+var _ model.LLM = (*OpenAIModel)(nil)
+
+// Options configures the OpenAIModel.
+type Options struct {
+	// Model is the model name to use.
+	Model string
+	// HTTPClient is the HTTP client to use.
+	HTTPClient *http.Client
+	// APIKey is the provider API key. Empty sends no credentials.
+	APIKey string
+	// Headers are extra HTTP headers added to every request.
+	Headers map[string]string
+	// Path overrides the endpoint path appended to the base URL. Empty uses
+	// /v1/chat/completions.
+	Path string
+}
+
+// DefaultOptions returns sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		HTTPClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// Option is a functional option for OpenAIModel.
+type Option func(*Options)
+
+// WithModel sets the model name.
+func WithModel(model string) Option {
+	return func(o *Options) {
+		o.Model = model
+	}
+}
+
+// WithHTTPClient sets the HTTP client. A nil client keeps the default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		if client != nil {
+			o.HTTPClient = client
+		}
+	}
+}
+
+// WithAPIKey sets the provider API key.
+func WithAPIKey(key string) Option {
+	return func(o *Options) {
+		o.APIKey = key
+	}
+}
+
+// WithHeaders sets extra HTTP headers added to every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *Options) {
+		o.Headers = headers
+	}
+}
+
+// WithPath overrides the endpoint path appended to the base URL.
+func WithPath(path string) Option {
+	return func(o *Options) {
+		o.Path = path
+	}
+}
+
+// NewOpenAIModel creates a chat model for the OpenAI-compatible API at url.
+func NewOpenAIModel(url string, opts ...Option) *OpenAIModel {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	path := options.Path
+	if path == "" {
+		path = defaultOpenAIPath
+	}
+
+	return &OpenAIModel{
+		url:        strings.TrimRight(url, "/"),
+		model:      options.Model,
+		httpClient: options.HTTPClient,
+		path:       path,
+		apiKey:     options.APIKey,
+		headers:    options.Headers,
+	}
+}
+
+// Name returns the model name.
+func (m *OpenAIModel) Name() string {
+	return m.model
+}
+
+// GenerateContent sends the conversation and its tools to the chat
+// completions endpoint. Streaming is not used: with stream set the complete
+// response is yielded once.
+func (m *OpenAIModel) GenerateContent(ctx context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		resp, err := m.generate(ctx, req)
+		yield(resp, err)
+	}
+}
+
+// chatRequest is the request body for POST /v1/chat/completions.
+type chatRequest struct {
+	Model       string        `json:"model,omitempty"`
+	Messages    []chatMessage `json:"messages"`
+	Tools       []chatTool    `json:"tools,omitempty"`
+	Temperature *float32      `json:"temperature,omitempty"`
+	TopP        *float32      `json:"top_p,omitempty"`
+	MaxTokens   int32         `json:"max_tokens,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
+}
+
+// chatMessage is one message of a chat conversation.
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    *string        `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// chatTool declares a function the model may call.
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+// chatFunction describes a callable function.
+type chatFunction struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+}
+
+// chatToolCall is a function call made by the model.
+type chatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function chatFunctionCall `json:"function"`
+}
+
+// chatFunctionCall holds the called function and its JSON-encoded
+// arguments.
+type chatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// chatResponse is the response from POST /v1/chat/completions.
+type chatResponse struct {
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage"`
+}
+
+// chatChoice is one completion candidate.
+type chatChoice struct {
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// chatUsage reports token counts.
+type chatUsage struct {
+	PromptTokens     int32 `json:"prompt_tokens"`
+	CompletionTokens int32 `json:"completion_tokens"`
+	TotalTokens      int32 `json:"total_tokens"`
+}
+
+// generate performs one chat completion.
+func (m *OpenAIModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	chatReq, err := m.toChatRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url+m.path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+	for k, v := range m.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := m.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(resp)
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("chat completion returned no choices")
+	}
+	return toLLMResponse(chatResp)
+}
+
+// toChatRequest converts an ADK request into a chat completions request.
+func (m *OpenAIModel) toChatRequest(req *model.LLMRequest) (*chatRequest, error) {
+	chatReq := &chatRequest{Model: m.model}
+	if req.Model != "" && m.model == "" {
+		chatReq.Model = req.Model
+	}
+
+	if cfg := req.Config; cfg != nil {
+		if text := genaitext.Join(cfg.SystemInstruction); text != "" {
+			chatReq.Messages = append(chatReq.Messages, textMessage("system", text))
+		}
+		chatReq.Temperature = cfg.Temperature
+		chatReq.TopP = cfg.TopP
+		chatReq.MaxTokens = cfg.MaxOutputTokens
+		chatReq.Stop = cfg.StopSequences
+		for _, t := range cfg.Tools {
+			if t == nil {
+				continue
+			}
+			for _, decl := range t.FunctionDeclarations {
+				tool, err := toChatTool(decl)
+				if err != nil {
+					return nil, err
+				}
+				chatReq.Tools = append(chatReq.Tools, tool)
+			}
+		}
+	}
+
+	messages, err := toChatMessages(req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	chatReq.Messages = append(chatReq.Messages, messages...)
+	return chatReq, nil
+}
+
+// toChatTool converts a function declaration into a chat tool.
+func toChatTool(decl *genai.FunctionDeclaration) (chatTool, error) {
+	var params any = map[string]any{"type": "object", "properties": map[string]any{}}
+	switch {
+	case decl.ParametersJsonSchema != nil:
+		params = decl.ParametersJsonSchema
+	case decl.Parameters != nil:
+		schema, err := jsonSchema(decl.Parameters)
+		if err != nil {
+			return chatTool{}, fmt.Errorf("convert %s parameters: %w", decl.Name, err)
+		}
+		params = schema
+	}
+	return chatTool{
+		Type: "function",
+		Function: chatFunction{
+			Name:        decl.Name,
+			Description: decl.Description,
+			Parameters:  params,
+		},
+	}, nil
+}
+
+// jsonSchema converts a Gemini schema into JSON Schema. Gemini spells types
+// in upper case ("OBJECT") and marks optional values with nullable.
+func jsonSchema(schema *genai.Schema) (map[string]any, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	lowerTypes(out)
+	return out, nil
+}
+
+// lowerTypes rewrites the Gemini type names of a decoded schema and its
+// subschemas in place and drops the Gemini-only keywords.
+func lowerTypes(schema map[string]any) {
+	if t, ok := schema["type"].(string); ok {
+		t = strings.ToLower(t)
+		switch {
+		case t == strings.ToLower(string(genai.TypeUnspecified)):
+			delete(schema, "type")
+		case schema["nullable"] == true:
+			schema["type"] = []any{t, "null"}
+		default:
+			schema["type"] = t
+		}
+	}
+	delete(schema, "nullable")
+	delete(schema, "propertyOrdering")
+
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		for _, property := range properties {
+			if sub, ok := property.(map[string]any); ok {
+				lowerTypes(sub)
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		lowerTypes(items)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, alternative := range anyOf {
+			if sub, ok := alternative.(map[string]any); ok {
+				lowerTypes(sub)
+			}
+		}
+	}
+}
+
+// toChatMessages converts ADK contents into chat messages. Model turns
+// become assistant messages with their tool calls, and function responses
+// become tool messages. ADK strips the IDs it generated itself, so calls
+// and responses without IDs are paired by name in order.
+func toChatMessages(contents []*genai.Content) ([]chatMessage, error) {
+	var messages []chatMessage
+	pending := make(map[string][]string)
+	generated := 0
+
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+		var texts []string
+		var calls []chatToolCall
+		var results []chatMessage
+		for _, part := range content.Parts {
+			switch {
+			case part == nil:
+			case part.FunctionCall != nil:
+				call := part.FunctionCall
+				id := call.ID
+				if id == "" {
+					generated++
+					id = fmt.Sprintf("call_local_%d", generated)
+					pending[call.Name] = append(pending[call.Name], id)
+				}
+				args, err := json.Marshal(call.Args)
+				if err != nil {
+					return nil, fmt.Errorf("encode %s arguments: %w", call.Name, err)
+				}
+				calls = append(calls, chatToolCall{
+					ID:       id,
+					Type:     "function",
+					Function: chatFunctionCall{Name: call.Name, Arguments: string(args)},
+				})
+			case part.FunctionResponse != nil:
+				response := part.FunctionResponse
+				id := response.ID
+				if id == "" && len(pending[response.Name]) > 0 {
+					id = pending[response.Name][0]
+					pending[response.Name] = pending[response.Name][1:]
+				}
+				result, err := json.Marshal(response.Response)
+				if err != nil {
+					return nil, fmt.Errorf("encode %s result: %w", response.Name, err)
+				}
+				msg := textMessage("tool", string(result))
+				msg.ToolCallID = id
+				results = append(results, msg)
+			case part.Text != "" && !part.Thought:
+				texts = append(texts, part.Text)
+			}
+		}
+
+		if content.Role == genai.RoleModel {
+			if len(texts) > 0 || len(calls) > 0 {
+				msg := chatMessage{Role: "assistant", ToolCalls: calls}
+				if len(texts) > 0 {
+					text := strings.Join(texts, "\n")
+					msg.Content = &text
+				}
+				messages = append(messages, msg)
+			}
+			continue
+		}
+		messages = append(messages, results...)
+		if len(texts) > 0 {
+			messages = append(messages, textMessage("user", strings.Join(texts, "\n")))
+		}
+	}
+	return messages, nil
+}
+
+// toLLMResponse converts the first choice of a chat completion into an ADK
+// response.
+func toLLMResponse(resp chatResponse) (*model.LLMResponse, error) {
+	choice := resp.Choices[0]
+	content := &genai.Content{Role: genai.RoleModel}
+	if choice.Message.Content != nil && *choice.Message.Content != "" {
+		content.Parts = append(content.Parts, genai.NewPartFromText(*choice.Message.Content))
+	}
+	for _, call := range choice.Message.ToolCalls {
+		args := map[string]any{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				return nil, fmt.Errorf("decode %s arguments: %w", call.Function.Name, err)
+			}
+		}
+		content.Parts = append(content.Parts, &genai.Part{
+			FunctionCall: &genai.FunctionCall{ID: call.ID, Name: call.Function.Name, Args: args},
+		})
+	}
+
+	out := &model.LLMResponse{
+		Content:      content,
+		TurnComplete: true,
+		FinishReason: finishReason(choice.FinishReason),
+	}
+	if resp.Usage != nil {
+		out.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     resp.Usage.PromptTokens,
+			CandidatesTokenCount: resp.Usage.CompletionTokens,
+			TotalTokenCount:      resp.Usage.TotalTokens,
+		}
+	}
+	return out, nil
+}
+
+// finishReason maps an OpenAI finish reason to its genai equivalent.
+func finishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "stop", "tool_calls", "function_call":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	case "":
+		return genai.FinishReasonUnspecified
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+// textMessage builds a chat message holding text.
+func textMessage(role, text string) chatMessage {
+	return chatMessage{Role: role, Content: &text}
+}
+
+// APIError is a non-success response from a chat completions API.
+type APIError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Message is the provider's error message, or the raw body.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("chat API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("chat API error: status %d: %s", e.StatusCode, e.Message)
+}
+
+// newAPIError builds an APIError from a non-success response.
+func newAPIError(resp *http.Response) *APIError {
+	return &APIError{StatusCode: resp.StatusCode, Message: httperr.ReadMessage(resp)}
+}
@@ -0,0 +1,271 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// chatReply writes a chat completion with one choice.
+func chatReply(w http.ResponseWriter, message chatMessage, finishReason string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"choices": []any{map[string]any{"message": message, "finish_reason": finishReason}},
+		"usage":   map[string]any{"prompt_tokens": 7, "completion_tokens": 3, "total_tokens": 10},
+	})
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestOpenAIModel_GenerateContent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("converts conversation and tools", func(t *testing.T) {
+		t.Parallel()
+		var got chatRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/chat/completions" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+				t.Errorf("Authorization = %q, want bearer token", auth)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+			chatReply(w, chatMessage{Role: "assistant", Content: strPtr("done")}, "stop")
+		}))
+		defer server.Close()
+
+		m := NewOpenAIModel(server.URL, WithModel("qwen"), WithAPIKey("secret"))
+		req := &model.LLMRequest{
+			Contents: []*genai.Content{
+				genai.NewContentFromText("find a translator", genai.RoleUser),
+				{Role: genai.RoleModel, Parts: []*genai.Part{
+					{FunctionCall: &genai.FunctionCall{Name: "route", Args: map[string]any{"query": "translate"}}},
+				}},
+				{Role: genai.RoleUser, Parts: []*genai.Part{
+					{FunctionResponse: &genai.FunctionResponse{Name: "route", Response: map[string]any{"found": true}}},
+				}},
+			},
+			Config: &genai.GenerateContentConfig{
+				SystemInstruction: genai.NewContentFromText("You are a broker.", genai.RoleUser),
+				Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{
+					{Name: "route", Description: "Route a request", ParametersJsonSchema: map[string]any{"type": "object"}},
+				}}},
+			},
+		}
+
+		var resp *model.LLMResponse
+		for r, err := range m.GenerateContent(context.Background(), req, false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			resp = r
+		}
+
+		if got.Model != "qwen" {
+			t.Errorf("model = %q, want qwen", got.Model)
+		}
+		if len(got.Tools) != 1 || got.Tools[0].Function.Name != "route" || got.Tools[0].Function.Parameters.(map[string]any)["type"] != "object" {
+			t.Errorf("tools = %+v, want the route declaration", got.Tools)
+		}
+		wantRoles := []string{"system", "user", "assistant", "tool"}
+		if len(got.Messages) != len(wantRoles) {
+			t.Fatalf("messages = %+v, want roles %v", got.Messages, wantRoles)
+		}
+		for i, role := range wantRoles {
+			if got.Messages[i].Role != role {
+				t.Errorf("message %d role = %q, want %q", i, got.Messages[i].Role, role)
+			}
+		}
+		call := got.Messages[2].ToolCalls
+		if len(call) != 1 || call[0].Function.Name != "route" || call[0].Function.Arguments != `{"query":"translate"}` {
+			t.Errorf("tool calls = %+v, want route with its arguments", call)
+		}
+		if call[0].ID == "" || got.Messages[3].ToolCallID != call[0].ID {
+			t.Errorf("tool result id = %q, want call id %q", got.Messages[3].ToolCallID, call[0].ID)
+		}
+
+		if resp.Content.Parts[0].Text != "done" || resp.FinishReason != genai.FinishReasonStop {
+			t.Errorf("response = %+v, want text done with stop", resp.Content.Parts[0])
+		}
+		if resp.UsageMetadata == nil || resp.UsageMetadata.TotalTokenCount != 10 {
+			t.Errorf("usage = %+v, want 10 total tokens", resp.UsageMetadata)
+		}
+	})
+
+	t.Run("returns tool calls", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			call := chatToolCall{ID: "call_1", Type: "function"}
+			call.Function.Name = "discover"
+			call.Function.Arguments = `{"query":"weather","limit":2}`
+			chatReply(w, chatMessage{Role: "assistant", ToolCalls: []chatToolCall{call}}, "tool_calls")
+		}))
+		defer server.Close()
+
+		// A nil client keeps the default one.
+		m := NewOpenAIModel(server.URL, WithHTTPClient(nil))
+		for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			if len(resp.Content.Parts) != 1 || resp.Content.Parts[0].FunctionCall == nil {
+				t.Fatalf("parts = %+v, want one function call", resp.Content.Parts)
+			}
+			fc := resp.Content.Parts[0].FunctionCall
+			if fc.ID != "call_1" || fc.Name != "discover" || fc.Args["query"] != "weather" || fc.Args["limit"] != float64(2) {
+				t.Errorf("function call = %+v, want discover(weather, 2) with id call_1", fc)
+			}
+		}
+	})
+
+	t.Run("error response returns APIError", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"model not found"}}`))
+		}))
+		defer server.Close()
+
+		m := NewOpenAIModel(server.URL, WithPath("/custom/chat"))
+		for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GenerateContent() error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "model not found" {
+				t.Errorf("APIError = %+v, want 400 model not found", apiErr)
+			}
+		}
+	})
+}
+
+func TestOpenAIModel_LLMAgentToolCalling(t *testing.T) {
+	t.Parallel()
+
+	// The stub calls the lookup tool first, then answers with its result.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == "tool" {
+			chatReply(w, chatMessage{Role: "assistant", Content: strPtr("agent: " + *last.Content)}, "stop")
+			return
+		}
+		call := chatToolCall{ID: "call_lookup", Type: "function"}
+		call.Function.Name = "lookup"
+		call.Function.Arguments = `{"query":"translate"}`
+		chatReply(w, chatMessage{Role: "assistant", ToolCalls: []chatToolCall{call}}, "tool_calls")
+	}))
+	defer server.Close()
+
+	type lookupArgs struct {
+		Query string `json:"query"`
+	}
+	type lookupResult struct {
+		Agent string `json:"agent"`
+	}
+	lookup, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "Look up an agent"},
+		func(_ tool.Context, args lookupArgs) (lookupResult, error) {
+			return lookupResult{Agent: args.Query + "-agent"}, nil
+		})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	broker, err := llmagent.New(llmagent.Config{
+		Name:  "broker",
+		Model: NewOpenAIModel(server.URL),
+		Tools: []tool.Tool{lookup},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	sessions := session.InMemoryService()
+	created, err := sessions.Create(ctx, &session.CreateRequest{AppName: "test", UserID: "user"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	r, err := runner.New(runner.Config{AppName: "test", Agent: broker, SessionService: sessions})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+
+	var final string
+	for event, err := range r.Run(ctx, "user", created.Session.ID(), genai.NewContentFromText("find a translator", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if event.Content != nil && len(event.Content.Parts) > 0 && event.Content.Parts[0].Text != "" {
+			final = event.Content.Parts[0].Text
+		}
+	}
+	if final != `agent: {"agent":"translate-agent"}` {
+		t.Errorf("final reply = %q, want the tool result relayed", final)
+	}
+}
+
+func TestToChatTool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		decl *genai.FunctionDeclaration
+		want string
+	}{
+		{
+			name: "JSON schema is passed through",
+			decl: &genai.FunctionDeclaration{Name: "f", ParametersJsonSchema: map[string]any{"type": "object"}},
+			want: `{"type":"object"}`,
+		},
+		{
+			name: "Gemini schema types are lowered",
+			decl: &genai.FunctionDeclaration{Name: "f", Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"tags":  {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+					"limit": {Type: genai.TypeInteger, Nullable: genai.Ptr(true)},
+				},
+				PropertyOrdering: []string{"tags", "limit"},
+				Required:         []string{"tags"},
+			}},
+			want: `{"properties":{"limit":{"type":["integer","null"]},"tags":{"items":{"type":"string"},"type":"array"}},"required":["tags"],"type":"object"}`,
+		},
+		{
+			name: "no parameters",
+			decl: &genai.FunctionDeclaration{Name: "f"},
+			want: `{"properties":{},"type":"object"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tool, err := toChatTool(tt.decl)
+			if err != nil {
+				t.Fatalf("toChatTool() error = %v", err)
+			}
+			got, _ := json.Marshal(tool.Function.Parameters)
+			if string(got) != tt.want {
+				t.Errorf("parameters = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// maxLLMScore is the top of the scale the model scores documents on.
+const maxLLMScore = 10
+
+// llmInstruction asks the model to score every candidate on a fixed scale.
+const llmInstruction = `You rank candidates for a search query. Rate how well each numbered candidate can handle the query on a scale from 0 (unrelated) to 10 (exact fit).
+Reply with only a JSON object of the form {"scores": [n0, n1, ...]} holding one number per candidate, in candidate order.`
+
+// LLMReranker scores documents by asking a chat model to rate them. It
+// suits deployments without a cross-encoder, at the cost of a model call
+// per search.
+type LLMReranker struct {
+	// llm is the chat model that scores candidates.
+	llm model.LLM
+}
+
+var _ Reranker = (*LLMReranker)(nil)
+
+// NewLLMReranker creates a reranker that scores with m.
+func NewLLMReranker(m model.LLM) *LLMReranker {
+	return &LLMReranker{llm: m}
+}
+
+// Rerank asks the model to score each document from 0 to 10 and scales
+// the scores to 0-1.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, documents []string) ([]float32, error) {
+	if len(documents) == 0 {
+		return []float32{}, nil
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Query: %s\n", query)
+	for i, doc := range documents {
+		fmt.Fprintf(&prompt, "\nCandidate %d:\n%s\n", i, doc)
+	}
+
+	temperature := float32(0)
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText(prompt.String(), genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(llmInstruction, genai.RoleUser),
+			Temperature:       &temperature,
+			ResponseMIMEType:  "application/json",
+		},
+	}
+
+	var text strings.Builder
+	for resp, err := range r.llm.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return nil, fmt.Errorf("generate scores: %w", err)
+		}
+		if resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+
+	return parseLLMScores(text.String(), len(documents))
+}
+
+// parseLLMScores extracts the scores object from the model reply, which
+// may be wrapped in a code fence or surrounding prose.
+func parseLLMScores(text string, n int) ([]float32, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("parse scores: no JSON object in reply %q", text)
+	}
+
+	var reply struct {
+		Scores []float32 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &reply); err != nil {
+		return nil, fmt.Errorf("parse scores: %w", err)
+	}
+	if len(reply.Scores) != n {
+		return nil, fmt.Errorf("parse scores: got %d scores for %d candidates", len(reply.Scores), n)
+	}
+
+	scores := make([]float32, n)
+	for i, s := range reply.Scores {
+		scores[i] = min(max(s, 0), maxLLMScore) / maxLLMScore
+	}
+	return scores, nil
+}
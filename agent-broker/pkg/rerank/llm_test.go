@@ -0,0 +1,59 @@
+package rerank
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// replyModel is a model.LLM that answers every request with a fixed text.
+type replyModel struct {
+	// reply is the text of every response.
+	reply string
+}
+
+func (m replyModel) Name() string { return "reply" }
+
+func (m replyModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.reply, genai.RoleModel)}, nil)
+	}
+}
+
+func TestLLMReranker_Rerank(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		reply   string
+		want    []float32
+		wantErr bool
+	}{
+		{name: "plain JSON", reply: `{"scores": [2, 10]}`, want: []float32{0.2, 1}},
+		{name: "fenced JSON", reply: "```json\n{\"scores\": [0, 15]}\n```", want: []float32{0, 1}},
+		{name: "wrong score count", reply: `{"scores": [5]}`, wantErr: true},
+		{name: "no JSON", reply: "The translator fits best.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := NewLLMReranker(replyModel{reply: tt.reply})
+
+			scores, err := r.Rerank(context.Background(), "translate", []string{"weather", "translator"})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rerank() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(scores) != len(tt.want) || scores[0] != tt.want[0] || scores[1] != tt.want[1] {
+				t.Errorf("Rerank() = %v, want %v", scores, tt.want)
+			}
+		})
+	}
+}
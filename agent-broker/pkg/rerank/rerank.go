@@ -0,0 +1,39 @@
+// Package rerank provides second-stage rerankers that score search
+// candidates against a query more precisely than vector similarity.
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/internal/httperr"
+)
+
+// Reranker scores documents by relevance to a query.
+type Reranker interface {
+	// Rerank returns one score per document, in document order, in the
+	// range 0-1 with higher meaning more relevant.
+	Rerank(ctx context.Context, query string, documents []string) ([]float32, error)
+}
+
+// APIError is a non-success response from a rerank provider.
+type APIError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Message is the provider's error message, or the raw body.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("rerank API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("rerank API error: status %d: %s", e.StatusCode, e.Message)
+}
+
+// newAPIError builds an APIError from a non-success response.
+func newAPIError(resp *http.Response) *APIError {
+	return &APIError{StatusCode: resp.StatusCode, Message: httperr.ReadMessage(resp)}
+}
@@ -0,0 +1,174 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultTEIPath is the rerank endpoint of Text Embeddings Inference.
+const defaultTEIPath = "/rerank"
+
+// TEIReranker scores documents with a cross-encoder served by a Text
+// Embeddings Inference compatible /rerank endpoint.
+type TEIReranker struct {
+	// url is the base URL of the rerank API.
+	url string
+	// httpClient is the HTTP client for making requests.
+	httpClient *http.Client
+	// path is the rerank endpoint path appended to url.
+	path string
+	// apiKey is sent as a bearer token when set.
+	apiKey string
+	// headers are added to every request.
+	headers map[string]string
+}
+
+var _ Reranker = (*TEIReranker)(nil)
+
+// TEIOptions configures the TEIReranker.
+type TEIOptions struct {
+	// HTTPClient is the HTTP client to use.
+	HTTPClient *http.Client
+	// APIKey is the provider API key. Empty sends no credentials.
+	APIKey string
+	// Headers are extra HTTP headers added to every request.
+	Headers map[string]string
+	// Path overrides the endpoint path appended to the base URL. Empty uses
+	// /rerank.
+	Path string
+}
+
+// DefaultTEIOptions returns sensible defaults.
+func DefaultTEIOptions() TEIOptions {
+	return TEIOptions{
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// TEIOption is a functional option for TEIReranker.
+type TEIOption func(*TEIOptions)
+
+// WithHTTPClient sets the HTTP client. A nil client keeps the default.
+func WithHTTPClient(client *http.Client) TEIOption {
+	return func(o *TEIOptions) {
+		if client != nil {
+			o.HTTPClient = client
+		}
+	}
+}
+
+// WithAPIKey sets the provider API key.
+func WithAPIKey(key string) TEIOption {
+	return func(o *TEIOptions) {
+		o.APIKey = key
+	}
+}
+
+// WithHeaders sets extra HTTP headers added to every request.
+func WithHeaders(headers map[string]string) TEIOption {
+	return func(o *TEIOptions) {
+		o.Headers = headers
+	}
+}
+
+// WithPath overrides the endpoint path appended to the base URL.
+func WithPath(path string) TEIOption {
+	return func(o *TEIOptions) {
+		o.Path = path
+	}
+}
+
+// NewTEIReranker creates a reranker for the TEI server at url.
+func NewTEIReranker(url string, opts ...TEIOption) *TEIReranker {
+	options := DefaultTEIOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	path := options.Path
+	if path == "" {
+		path = defaultTEIPath
+	}
+
+	return &TEIReranker{
+		url:        url,
+		httpClient: options.HTTPClient,
+		path:       path,
+		apiKey:     options.APIKey,
+		headers:    options.Headers,
+	}
+}
+
+// teiRequest is the request body for POST /rerank.
+type teiRequest struct {
+	Query    string   `json:"query"`
+	Texts    []string `json:"texts"`
+	Truncate bool     `json:"truncate"`
+}
+
+// teiScore is one entry of the POST /rerank response.
+type teiScore struct {
+	Index int     `json:"index"`
+	Score float32 `json:"score"`
+}
+
+// Rerank scores documents with the cross-encoder. TEI returns sigmoid
+// scores in the range 0-1 unless raw scores are requested.
+func (r *TEIReranker) Rerank(ctx context.Context, query string, documents []string) ([]float32, error) {
+	if len(documents) == 0 {
+		return []float32{}, nil
+	}
+
+	body, err := json.Marshal(teiRequest{Query: query, Texts: documents, Truncate: true})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url+r.path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var results []teiScore
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	scores := make([]float32, len(documents))
+	seen := make([]bool, len(documents))
+	for _, s := range results {
+		if s.Index < 0 || s.Index >= len(documents) {
+			return nil, fmt.Errorf("rerank returned index %d for %d documents", s.Index, len(documents))
+		}
+		scores[s.Index] = s.Score
+		seen[s.Index] = true
+	}
+	for i, ok := range seen {
+		if !ok {
+			return nil, fmt.Errorf("rerank returned no score for document %d", i)
+		}
+	}
+	return scores, nil
+}
@@ -0,0 +1,63 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTEIReranker_Rerank(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns scores in document order", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/rerank" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			var req struct {
+				Query string   `json:"query"`
+				Texts []string `json:"texts"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+			if req.Query != "translate" || len(req.Texts) != 2 {
+				t.Errorf("request = %+v, want query and two texts", req)
+			}
+			// TEI sorts results by score, not by input order.
+			_, _ = w.Write([]byte(`[{"index":1,"score":0.9},{"index":0,"score":0.2}]`))
+		}))
+		defer server.Close()
+
+		// A nil client keeps the default one.
+		scores, err := NewTEIReranker(server.URL, WithHTTPClient(nil)).Rerank(context.Background(), "translate", []string{"weather", "translator"})
+		if err != nil {
+			t.Fatalf("Rerank() error = %v", err)
+		}
+		if len(scores) != 2 || scores[0] != 0.2 || scores[1] != 0.9 {
+			t.Errorf("Rerank() = %v, want [0.2 0.9]", scores)
+		}
+	})
+
+	t.Run("error response returns APIError", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write([]byte(`{"error":"batch size 64 > maximum allowed batch size 32","error_type":"Validation"}`))
+		}))
+		defer server.Close()
+
+		_, err := NewTEIReranker(server.URL).Rerank(context.Background(), "q", []string{"a"})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Rerank() error = %v, want *APIError", err)
+		}
+		if apiErr.StatusCode != http.StatusRequestEntityTooLarge || apiErr.Message != "batch size 64 > maximum allowed batch size 32" {
+			t.Errorf("APIError = %+v, want 413 with the TEI message", apiErr)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package integration_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -10,7 +12,10 @@ import (
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+	"github.com/qdrant/go-client/qdrant"
 )
 
 var testHost string
@@ -231,6 +236,41 @@ func TestQdrantStore_ListAgents(t *testing.T) {
 			t.Errorf("ListAgents() got ID = %v, want agent-1", result.Agents[0].ID)
 		}
 	})
+
+	t.Run("filter by expression", func(t *testing.T) {
+		t.Parallel()
+		s := setupStore(t)
+		ctx := context.Background()
+
+		agent1 := validAgent("agent-1")
+		agent1.Tags = []string{"prod", "ml"}
+		agent1.Card.Skills = []a2a.AgentSkill{{ID: "translate", Name: "Translate"}, {ID: "summarize", Name: "Summarize"}}
+		agent2 := validAgent("agent-2")
+		agent2.Tags = []string{"prod", "beta"}
+		agent2.Card.Skills = []a2a.AgentSkill{{ID: "translate", Name: "Translate"}}
+		agent3 := validAgent("agent-3")
+		agent3.Tags = []string{"dev"}
+		_ = s.CreateAgent(ctx, agent1)
+		_ = s.CreateAgent(ctx, agent2)
+		_ = s.CreateAgent(ctx, agent3)
+
+		expr, err := store.ParseFilter("tags = prod AND NOT tags = beta OR (skills all (translate, summarize) AND created_at >= 2000-01-01)")
+		if err != nil {
+			t.Fatalf("ParseFilter() error = %v", err)
+		}
+
+		result, err := s.ListAgents(ctx, store.AgentFilter{Expr: expr, Limit: 10})
+
+		if err != nil {
+			t.Fatalf("ListAgents() error = %v", err)
+		}
+		if len(result.Agents) != 1 {
+			t.Fatalf("ListAgents() got %d agents, want 1", len(result.Agents))
+		}
+		if result.Agents[0].ID != "agent-1" {
+			t.Errorf("ListAgents() got ID = %v, want agent-1", result.Agents[0].ID)
+		}
+	})
 }
 
 func TestQdrantStore_UpdateAgent(t *testing.T) {
@@ -306,6 +346,74 @@ func TestQdrantStore_DeleteAgent(t *testing.T) {
 	})
 }
 
+func TestQdrantStore_UpsertAgents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates new and replaces existing agents", func(t *testing.T) {
+		t.Parallel()
+		s := setupStore(t)
+		ctx := context.Background()
+
+		existing := validAgent("agent-1")
+		existing.CreatedAt = time.Now().Add(-time.Hour)
+		_ = s.CreateAgent(ctx, existing)
+
+		updated := validAgent("agent-1")
+		updated.Card.Name = "Updated Name"
+		results, err := s.UpsertAgents(ctx, []*store.RegisteredAgent{updated, validAgent("agent-2")})
+
+		if err != nil {
+			t.Fatalf("UpsertAgents() error = %v", err)
+		}
+		if results[0].Created || !results[1].Created {
+			t.Errorf("UpsertAgents() results = %+v, want [updated created]", results)
+		}
+
+		agent, _ := s.GetAgent(ctx, "agent-1")
+		if agent.Card.Name != "Updated Name" {
+			t.Errorf("GetAgent() Name = %v, want Updated Name", agent.Card.Name)
+		}
+		if agent.CreatedAt.Unix() != existing.CreatedAt.Unix() {
+			t.Error("UpsertAgents() should preserve CreatedAt")
+		}
+
+		list, _ := s.ListAgents(ctx, store.AgentFilter{Limit: 10})
+		if list.Total != 2 {
+			t.Errorf("ListAgents() total = %d, want 2", list.Total)
+		}
+	})
+}
+
+func TestQdrantStore_DeleteAgents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deletes existing and reports missing", func(t *testing.T) {
+		t.Parallel()
+		s := setupStore(t)
+		ctx := context.Background()
+
+		_ = s.CreateAgent(ctx, validAgent("agent-1"))
+		_ = s.CreateAgent(ctx, validAgent("agent-2"))
+
+		results, err := s.DeleteAgents(ctx, []string{"agent-1", "agent-2", "not-exists"})
+
+		if err != nil {
+			t.Fatalf("DeleteAgents() error = %v", err)
+		}
+		if results[0].Err != nil || results[1].Err != nil {
+			t.Errorf("DeleteAgents() results = %+v, want no errors for existing agents", results)
+		}
+		if results[2].Err != store.ErrNotFound {
+			t.Errorf("DeleteAgents() not-exists error = %v, want ErrNotFound", results[2].Err)
+		}
+
+		list, _ := s.ListAgents(ctx, store.AgentFilter{Limit: 10})
+		if list.Total != 0 {
+			t.Errorf("ListAgents() total = %d, want 0", list.Total)
+		}
+	})
+}
+
 func TestQdrantStore_SearchAgents(t *testing.T) {
 	t.Parallel()
 
@@ -438,3 +546,255 @@ func TestQdrantStore_SearchAgents(t *testing.T) {
 		}
 	})
 }
+
+func TestQdrantStore_MigrateLegacyCollection(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	client, err := qdrant.NewClient(&qdrant.Config{Host: testHost})
+	if err != nil {
+		t.Fatalf("failed to create qdrant client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	collectionName := "test_" + uuid.New().String()[:8]
+	err = client.CreateCollection(ctx, &qdrant.CreateCollection{
+		CollectionName: collectionName,
+		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
+			Size:     4,
+			Distance: qdrant.Distance_Cosine,
+		}),
+	})
+	if err != nil {
+		t.Fatalf("failed to create legacy collection: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.DeleteAlias(context.Background(), collectionName)
+		_ = client.DeleteCollection(context.Background(), collectionName+"_named")
+	})
+
+	cardJSON, _ := json.Marshal(validAgentCard())
+	_, err = client.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points: []*qdrant.PointStruct{{
+			Id:      qdrant.NewID(uuid.New().String()),
+			Vectors: qdrant.NewVectors(0.1, 0.2, 0.3, 0.4),
+			Payload: qdrant.NewValueMap(map[string]any{
+				"id":         "legacy-agent",
+				"card":       string(cardJSON),
+				"tags":       []any{"test"},
+				"created_at": time.Now().Unix(),
+				"updated_at": time.Now().Unix(),
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to insert legacy agent: %v", err)
+	}
+
+	s, err := store.NewQdrantStore(ctx,
+		store.WithHost(testHost),
+		store.WithCollectionName(collectionName),
+		store.WithVectorDimension(4),
+	)
+	if err != nil {
+		t.Fatalf("NewQdrantStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	if !s.Migrated() {
+		t.Error("Migrated() = false, want true")
+	}
+	agent, err := s.GetAgent(ctx, "legacy-agent")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if agent.Card.Name != "Test Agent" || len(agent.Embedding) != 0 {
+		t.Errorf("GetAgent() = %+v, want card kept and no vectors", agent)
+	}
+
+	agent.Embedding = []float32{1, 0, 0, 0}
+	if err := s.UpdateAgent(ctx, agent); err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+	result, err := s.SearchAgents(ctx, []float32{1, 0, 0, 0}, 1, store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("SearchAgents() error = %v", err)
+	}
+	if len(result.Agents) != 1 || result.Agents[0].Agent.ID != "legacy-agent" {
+		t.Errorf("SearchAgents() = %+v, want legacy-agent", result.Agents)
+	}
+
+	reopened, err := store.NewQdrantStore(ctx,
+		store.WithHost(testHost),
+		store.WithCollectionName(collectionName),
+		store.WithVectorDimension(4),
+	)
+	if err != nil {
+		t.Fatalf("reopen NewQdrantStore() error = %v", err)
+	}
+	_ = reopened.Close()
+	if reopened.Migrated() {
+		t.Error("reopened Migrated() = true, want false")
+	}
+}
+
+func TestQdrantStore_BackfillCardVersion(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	collectionName := "test_" + uuid.New().String()[:8]
+	s, err := store.NewQdrantStore(ctx,
+		store.WithHost(testHost),
+		store.WithCollectionName(collectionName),
+		store.WithVectorDimension(4),
+	)
+	if err != nil {
+		t.Fatalf("NewQdrantStore() error = %v", err)
+	}
+	_ = s.Close()
+
+	client, err := qdrant.NewClient(&qdrant.Config{Host: testHost})
+	if err != nil {
+		t.Fatalf("failed to create qdrant client: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.DeleteCollection(context.Background(), collectionName)
+		_ = client.Close()
+	})
+
+	cardJSON, _ := json.Marshal(validAgentCard())
+	_, err = client.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points: []*qdrant.PointStruct{{
+			Id:      qdrant.NewID(uuid.New().String()),
+			Vectors: qdrant.NewVectorsMap(map[string]*qdrant.Vector{}),
+			Payload: qdrant.NewValueMap(map[string]any{
+				"id":         "old-agent",
+				"card":       string(cardJSON),
+				"created_at": time.Now().Unix(),
+				"updated_at": time.Now().Unix(),
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to insert agent without card_version: %v", err)
+	}
+
+	s, err = store.NewQdrantStore(ctx,
+		store.WithHost(testHost),
+		store.WithCollectionName(collectionName),
+		store.WithVectorDimension(4),
+	)
+	if err != nil {
+		t.Fatalf("reopen NewQdrantStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	expr, _ := store.ParseFilter("version = 1.0.0")
+	result, err := s.ListAgents(ctx, store.AgentFilter{Limit: 10, Expr: expr})
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if len(result.Agents) != 1 || result.Agents[0].ID != "old-agent" {
+		t.Errorf("ListAgents() = %+v, want old-agent", result.Agents)
+	}
+}
+
+func TestQdrantStore_VectorDimension(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	collectionName := "test_" + uuid.New().String()[:8]
+	opts := []store.Option{store.WithHost(testHost), store.WithCollectionName(collectionName)}
+
+	dim, err := store.CollectionDimension(ctx, opts...)
+	if err != nil || dim != 0 {
+		t.Fatalf("CollectionDimension() before creation = %d, %v, want 0", dim, err)
+	}
+
+	s, err := store.NewQdrantStore(ctx, append(opts, store.WithVectorDimension(4))...)
+	if err != nil {
+		t.Fatalf("NewQdrantStore() error = %v", err)
+	}
+	_ = s.Close()
+	t.Cleanup(func() {
+		client, err := qdrant.NewClient(&qdrant.Config{Host: testHost})
+		if err == nil {
+			_ = client.DeleteCollection(context.Background(), collectionName)
+			_ = client.Close()
+		}
+	})
+
+	dim, err = store.CollectionDimension(ctx, opts...)
+	if err != nil || dim != 4 {
+		t.Errorf("CollectionDimension() = %d, %v, want 4", dim, err)
+	}
+
+	_, err = store.NewQdrantStore(ctx, append(opts, store.WithVectorDimension(8))...)
+	if !errors.Is(err, store.ErrDimensionMismatch) {
+		t.Errorf("NewQdrantStore() with another dimension error = %v, want ErrDimensionMismatch", err)
+	}
+}
+
+func TestQdrantRegistry_Reindex(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := setupStore(t)
+
+	embedder, err := embedding.NewLocalEmbedder(4)
+	if err != nil {
+		t.Fatalf("NewLocalEmbedder() error = %v", err)
+	}
+	svc := registry.NewRegistryService(s, registry.WithEmbedder(embedder))
+	if _, err := svc.Create(ctx, registry.CreateInput{ID: "embedded", Card: validAgentCard(), Tags: []string{"test"}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	bare := validAgent("bare")
+	bare.Embedding = nil
+	if err := s.CreateAgent(ctx, bare); err != nil {
+		t.Fatalf("CreateAgent() error = %v", err)
+	}
+
+	first, err := svc.Reindex(ctx)
+	if err != nil || first != 1 {
+		t.Fatalf("Reindex() = %d, %v, want 1", first, err)
+	}
+	second, err := svc.Reindex(ctx)
+	if err != nil || second != 0 {
+		t.Errorf("second Reindex() = %d, %v, want 0", second, err)
+	}
+}
+
+func TestQdrantRegistry_NeedsReembed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := setupStore(t)
+
+	embedder, err := embedding.NewLocalEmbedder(4)
+	if err != nil {
+		t.Fatalf("NewLocalEmbedder() error = %v", err)
+	}
+	original := registry.NewRegistryService(s, registry.WithEmbedder(embedder))
+	if _, err := original.Create(ctx, registry.CreateInput{ID: "agent", Card: validAgentCard(), Tags: []string{"test"}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	changed := registry.NewRegistryService(s,
+		registry.WithEmbedder(embedder),
+		registry.WithEmbeddingTemplate(registry.MustParseEmbeddingTemplate("{{.Card.Name}}")),
+	)
+
+	listed, err := s.ListAgents(ctx, store.AgentFilter{Limit: 10})
+	if err != nil || len(listed.Agents) != 1 {
+		t.Fatalf("ListAgents() = %v, %v, want one agent", listed, err)
+	}
+	agent := listed.Agents[0]
+	if original.NeedsReembed(agent) {
+		t.Error("NeedsReembed() with the original template = true, want false")
+	}
+	if !changed.NeedsReembed(agent) {
+		t.Error("NeedsReembed() with a changed template = false, want true")
+	}
+}